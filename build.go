@@ -12,7 +12,6 @@ import (
 	"github.com/paketo-buildpacks/packit/v2/fs"
 
 	"github.com/BurntSushi/toml"
-	"github.com/Masterminds/semver/v3"
 	"github.com/paketo-buildpacks/packit/v2/internal"
 )
 
@@ -51,6 +50,10 @@ type BuildContext struct {
 	// $CNB_STACK_ID environment variable.
 	Stack string
 
+	// Target is the platform that the application image is being built for.
+	// This value is populated from the $CNB_TARGET_* environment variables.
+	Target Target
+
 	// WorkingDir is the location of the application source code as provided by
 	// the lifecycle.
 	WorkingDir string
@@ -85,18 +88,29 @@ type BuildResult struct {
 // Buildpacks specification. Calling this function with a BuildFunc will
 // perform the build phase process.
 func Build(f BuildFunc, options ...Option) {
+	// The metadata of every layer, along with the launch.toml, build.toml and
+	// SBOM files, is staged as it is written and only renamed into place once
+	// all of it has been written, so that a build that fails part way through
+	// does not leave a mix of old and new metadata for the lifecycle to restore.
+	transaction := internal.NewTransaction()
+
 	config := OptionConfig{
 		exitHandler: internal.NewExitHandler(),
 		args:        os.Args,
-		tomlWriter:  internal.NewTOMLWriter(),
-		envWriter:   internal.NewEnvironmentWriter(),
-		fileWriter:  internal.NewFileWriter(),
+		tomlWriter:  internal.NewTOMLWriter().WithTransaction(transaction),
+		envWriter:   internal.NewEnvironmentWriter().WithTransaction(transaction),
+		fileWriter:  internal.NewFileWriter().WithTransaction(transaction),
 	}
 
 	for _, option := range options {
 		config = option(config)
 	}
 
+	config.exitHandler = rollbackExitHandler{
+		exitHandler: config.exitHandler,
+		transaction: transaction,
+	}
+
 	pwd, err := os.Getwd()
 	if err != nil {
 		config.exitHandler.Error(err)
@@ -141,11 +155,7 @@ func Build(f BuildFunc, options ...Option) {
 		return
 	}
 
-	apiV05, _ := semver.NewVersion("0.5")
-	apiV06, _ := semver.NewVersion("0.6")
-	apiV08, _ := semver.NewVersion("0.8")
-	apiV09, _ := semver.NewVersion("0.9")
-	apiVersion, err := semver.NewVersion(buildpackInfo.APIVersion)
+	api, err := newBuildpackAPI(buildpackInfo.APIVersion)
 	if err != nil {
 		config.exitHandler.Error(err)
 		return
@@ -157,6 +167,7 @@ func Build(f BuildFunc, options ...Option) {
 			Path: platformPath,
 		},
 		Stack:      os.Getenv("CNB_STACK_ID"),
+		Target:     newTarget(api),
 		WorkingDir: pwd,
 		Plan:       plan,
 		Layers: Layers{
@@ -170,8 +181,9 @@ func Build(f BuildFunc, options ...Option) {
 	}
 
 	if len(result.Plan.Entries) > 0 {
-		if apiVersion.GreaterThan(apiV05) || apiVersion.Equal(apiV05) {
-			config.exitHandler.Error(errors.New("buildpack plan is read only since Buildpack API v0.5"))
+		err = api.require(apiFeatureWritablePlan)
+		if err != nil {
+			config.exitHandler.Error(err)
 			return
 		}
 
@@ -188,20 +200,16 @@ func Build(f BuildFunc, options ...Option) {
 		return
 	}
 
-	if apiVersion.LessThan(apiV06) {
+	if !api.supports(apiFeatureLayerTypes) {
 		for _, file := range layerTomls {
 			if filepath.Base(file) != "launch.toml" && filepath.Base(file) != "store.toml" && filepath.Base(file) != "build.toml" {
-				err = os.Remove(file)
-				if err != nil {
-					config.exitHandler.Error(fmt.Errorf("failed to remove layer toml: %w", err))
-					return
-				}
+				transaction.Remove(file)
 			}
 		}
 	}
 
 	for _, layer := range result.Layers {
-		err = config.tomlWriter.Write(filepath.Join(layersPath, fmt.Sprintf("%s.toml", layer.Name)), formattedLayer{layer, apiVersion})
+		err = config.tomlWriter.Write(filepath.Join(layersPath, fmt.Sprintf("%s.toml", layer.Name)), formattedLayer{layer, api})
 		if err != nil {
 			config.exitHandler.Error(err)
 			return
@@ -234,21 +242,22 @@ func Build(f BuildFunc, options ...Option) {
 		}
 
 		if layer.SBOM != nil {
-			if apiVersion.GreaterThan(apiV06) {
-				for _, format := range layer.SBOM.Formats() {
-					err = config.fileWriter.Write(filepath.Join(layersPath, fmt.Sprintf("%s.sbom.%s", layer.Name, format.Extension)), format.Content)
-					if err != nil {
-						config.exitHandler.Error(err)
-						return
-					}
-				}
-			} else {
-				config.exitHandler.Error(fmt.Errorf("%s.sbom.* output is only supported with Buildpack API v0.7 or higher", layer.Name))
+			err = api.require(apiFeatureSBOM, layer.Name)
+			if err != nil {
+				config.exitHandler.Error(err)
 				return
 			}
+
+			for _, format := range layer.SBOM.Formats() {
+				err = config.fileWriter.Write(filepath.Join(layersPath, fmt.Sprintf("%s.sbom.%s", layer.Name, format.Extension)), format.Content)
+				if err != nil {
+					config.exitHandler.Error(err)
+					return
+				}
+			}
 		}
 
-		if (apiVersion.GreaterThan(apiV05) || apiVersion.Equal(apiV05)) && len(layer.ExecD) > 0 {
+		if api.supports(apiFeatureExecD) && len(layer.ExecD) > 0 {
 			execdDir := filepath.Join(layer.Path, "exec.d")
 			err = os.MkdirAll(execdDir, os.ModePerm)
 			if err != nil {
@@ -274,9 +283,12 @@ func Build(f BuildFunc, options ...Option) {
 	}
 
 	if !result.Launch.isEmpty() {
-		if apiVersion.LessThan(apiV05) && len(result.Launch.BOM) > 0 {
-			config.exitHandler.Error(errors.New("BOM entries in launch.toml is only supported with Buildpack API v0.5 or higher"))
-			return
+		if len(result.Launch.BOM) > 0 {
+			err = api.require(apiFeatureLaunchBOM)
+			if err != nil {
+				config.exitHandler.Error(err)
+				return
+			}
 		}
 
 		type label struct {
@@ -292,33 +304,37 @@ func Build(f BuildFunc, options ...Option) {
 			BOM             []BOMEntry      `toml:"bom"`
 		}
 
-		if apiVersion.LessThan(apiV09) {
-			if result.Launch.DirectProcesses != nil {
-				config.exitHandler.Error(errors.New("direct processes can only be used with Buildpack API v0.9 or higher"))
+		if result.Launch.DirectProcesses != nil {
+			err = api.require(apiFeatureDirectProcesses)
+			if err != nil {
+				config.exitHandler.Error(err)
 				return
 			}
-			launch.Processes = result.Launch.Processes
-		} else {
-			if result.Launch.Processes != nil {
-				config.exitHandler.Error(errors.New("non direct processes can only be used with Buildpack API v0.8 or lower"))
+			launch.DirectProcesses = result.Launch.DirectProcesses
+		}
+
+		if result.Launch.Processes != nil {
+			err = api.require(apiFeatureNonDirectProcesses)
+			if err != nil {
+				config.exitHandler.Error(err)
 				return
 			}
-			launch.DirectProcesses = result.Launch.DirectProcesses
+			launch.Processes = result.Launch.Processes
 		}
 
-		if apiVersion.LessThan(apiV06) {
-			for _, process := range launch.Processes {
-				if process.Default {
-					config.exitHandler.Error(errors.New("processes can only be marked as default with Buildpack API v0.6 or higher"))
+		for _, process := range launch.Processes {
+			if process.Default {
+				err = api.require(apiFeatureDefaultProcess)
+				if err != nil {
+					config.exitHandler.Error(err)
 					return
 				}
 			}
-		}
 
-		if apiVersion.LessThan(apiV08) {
-			for _, process := range launch.Processes {
-				if process.WorkingDirectory != "" {
-					config.exitHandler.Error(errors.New("processes can only have a specific working directory with Buildpack API v0.8 or higher"))
+			if process.WorkingDirectory != "" {
+				err = api.require(apiFeatureProcessWorkingDirectory)
+				if err != nil {
+					config.exitHandler.Error(err)
 					return
 				}
 			}
@@ -344,40 +360,43 @@ func Build(f BuildFunc, options ...Option) {
 		}
 
 		if result.Launch.SBOM != nil {
-			if apiVersion.GreaterThan(apiV06) {
-				for _, format := range result.Launch.SBOM.Formats() {
-					err = config.fileWriter.Write(filepath.Join(layersPath, fmt.Sprintf("launch.sbom.%s", format.Extension)), format.Content)
-					if err != nil {
-						config.exitHandler.Error(err)
-						return
-					}
-				}
-			} else {
-				config.exitHandler.Error(fmt.Errorf("launch.sbom.* output is only supported with Buildpack API v0.7 or higher"))
+			err = api.require(apiFeatureSBOM, "launch")
+			if err != nil {
+				config.exitHandler.Error(err)
 				return
 			}
+
+			for _, format := range result.Launch.SBOM.Formats() {
+				err = config.fileWriter.Write(filepath.Join(layersPath, fmt.Sprintf("launch.sbom.%s", format.Extension)), format.Content)
+				if err != nil {
+					config.exitHandler.Error(err)
+					return
+				}
+			}
 		}
 	}
 
 	if !result.Build.isEmpty() {
-		if apiVersion.LessThan(apiV05) {
-			config.exitHandler.Error(fmt.Errorf("build.toml is only supported with Buildpack API v0.5 or higher"))
+		err = api.require(apiFeatureBuildTOML)
+		if err != nil {
+			config.exitHandler.Error(err)
 			return
 		}
 
 		if result.Build.SBOM != nil {
-			if apiVersion.GreaterThan(apiV06) {
-				for _, format := range result.Build.SBOM.Formats() {
-					err = config.fileWriter.Write(filepath.Join(layersPath, fmt.Sprintf("build.sbom.%s", format.Extension)), format.Content)
-					if err != nil {
-						config.exitHandler.Error(err)
-						return
-					}
-				}
-			} else {
-				config.exitHandler.Error(fmt.Errorf("build.sbom.* output is only supported with Buildpack API v0.7 or higher"))
+			err = api.require(apiFeatureSBOM, "build")
+			if err != nil {
+				config.exitHandler.Error(err)
 				return
 			}
+
+			for _, format := range result.Build.SBOM.Formats() {
+				err = config.fileWriter.Write(filepath.Join(layersPath, fmt.Sprintf("build.sbom.%s", format.Extension)), format.Content)
+				if err != nil {
+					config.exitHandler.Error(err)
+					return
+				}
+			}
 		}
 		err = config.tomlWriter.Write(filepath.Join(layersPath, "build.toml"), result.Build)
 		if err != nil {
@@ -385,4 +404,22 @@ func Build(f BuildFunc, options ...Option) {
 			return
 		}
 	}
+
+	err = transaction.Commit()
+	if err != nil {
+		config.exitHandler.Error(err)
+		return
+	}
+}
+
+// A rollbackExitHandler discards the files staged by a build before handing
+// its error to the exit handler, which may exit the process.
+type rollbackExitHandler struct {
+	exitHandler ExitHandler
+	transaction *internal.Transaction
+}
+
+func (h rollbackExitHandler) Error(err error) {
+	h.transaction.Rollback()
+	h.exitHandler.Error(err)
 }
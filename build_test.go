@@ -151,6 +151,68 @@ api = "0.8"
 		}))
 	})
 
+	context("when the target environment variables are set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("CNB_TARGET_OS", "linux")).To(Succeed())
+			Expect(os.Setenv("CNB_TARGET_ARCH", "arm64")).To(Succeed())
+			Expect(os.Setenv("CNB_TARGET_ARCH_VARIANT", "v8")).To(Succeed())
+			Expect(os.Setenv("CNB_TARGET_DISTRO_NAME", "ubuntu")).To(Succeed())
+			Expect(os.Setenv("CNB_TARGET_DISTRO_VERSION", "22.04")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("CNB_TARGET_OS")).To(Succeed())
+			Expect(os.Unsetenv("CNB_TARGET_ARCH")).To(Succeed())
+			Expect(os.Unsetenv("CNB_TARGET_ARCH_VARIANT")).To(Succeed())
+			Expect(os.Unsetenv("CNB_TARGET_DISTRO_NAME")).To(Succeed())
+			Expect(os.Unsetenv("CNB_TARGET_DISTRO_VERSION")).To(Succeed())
+		})
+
+		context("when the api version is 0.10 or higher", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+api = "0.10"
+[buildpack]
+  id = "some-id"
+`), 0600)).To(Succeed())
+			})
+
+			it("provides the target in the build context", func() {
+				var context packit.BuildContext
+
+				packit.Build(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+					context = ctx
+
+					return packit.BuildResult{}, nil
+				}, packit.WithArgs([]string{binaryPath, layersDir, platformDir, planPath}), packit.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.ErrorCall.CallCount).To(Equal(0))
+				Expect(context.Target).To(Equal(packit.Target{
+					OS:            "linux",
+					Arch:          "arm64",
+					ArchVariant:   "v8",
+					DistroName:    "ubuntu",
+					DistroVersion: "22.04",
+				}))
+			})
+		})
+
+		context("when the api version is less than 0.10", func() {
+			it("does not provide the target in the build context", func() {
+				var context packit.BuildContext
+
+				packit.Build(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+					context = ctx
+
+					return packit.BuildResult{}, nil
+				}, packit.WithArgs([]string{binaryPath, layersDir, platformDir, planPath}), packit.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.ErrorCall.CallCount).To(Equal(0))
+				Expect(context.Target).To(Equal(packit.Target{}))
+			})
+		})
+	})
+
 	context("when there are updates to the build plan", func() {
 		context("when the api version is less than 0.5", func() {
 			it.Before(func() {
@@ -416,7 +478,7 @@ api = "0.5"
 									Layers: []packit.Layer{},
 								}, nil
 							}, packit.WithArgs([]string{binaryPath, layersDir, platformDir, planPath}), packit.WithExitHandler(exitHandler))
-							Expect(exitHandler.ErrorCall.Receives.Error).To(MatchError(ContainSubstring("failed to remove layer toml:")))
+							Expect(exitHandler.ErrorCall.Receives.Error).To(MatchError(ContainSubstring("failed to remove")))
 						})
 					})
 				})
@@ -1636,7 +1698,16 @@ api = "0.4"
   clear-env = false
 				`)
 				Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), bpTOML, 0600)).To(Succeed())
-				Expect(os.Chmod(planPath, 0444)).To(Succeed())
+
+				planDir := filepath.Join(tmpDir, "plan")
+				Expect(os.Mkdir(planDir, os.ModePerm)).To(Succeed())
+				Expect(os.Rename(planPath, filepath.Join(planDir, "plan.toml"))).To(Succeed())
+				planPath = filepath.Join(planDir, "plan.toml")
+				Expect(os.Chmod(planDir, 0555)).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Chmod(filepath.Dir(planPath), os.ModePerm)).To(Succeed())
 			})
 
 			it("calls the exit handler", func() {
@@ -1694,16 +1765,53 @@ api = "0.4"
 
 				Expect(exitHandler.ErrorCall.Receives.Error).To(MatchError(ContainSubstring("failed to format layer sbom")))
 			})
+
+			it("does not write the metadata of any of the layers", func() {
+				Expect(os.WriteFile(filepath.Join(layersDir, "other-layer.toml"), []byte("launch = false\n"), 0644)).To(Succeed())
+
+				packit.Build(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+					return packit.BuildResult{
+						Layers: []packit.Layer{
+							{
+								Path:   filepath.Join(layersDir, "other-layer"),
+								Name:   "other-layer",
+								Launch: true,
+							},
+							{
+								Path: filepath.Join(layersDir, "some-layer"),
+								Name: "some-layer",
+								SBOM: packit.SBOMFormats{
+									{
+										Extension: "some.json",
+										Content:   iotest.ErrReader(errors.New("failed to format layer sbom")),
+									},
+								},
+							},
+						},
+					}, nil
+				}, packit.WithArgs([]string{binaryPath, layersDir, platformDir, planPath}), packit.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.ErrorCall.Receives.Error).To(MatchError(ContainSubstring("failed to format layer sbom")))
+
+				content, err := os.ReadFile(filepath.Join(layersDir, "other-layer.toml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("launch = false\n"))
+
+				Expect(filepath.Join(layersDir, "some-layer.toml")).NotTo(BeAnExistingFile())
+
+				files, err := filepath.Glob(filepath.Join(layersDir, ".*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(BeEmpty())
+			})
 		})
 
 		context("when the launch.toml file cannot be written", func() {
 			it.Before(func() {
-				_, err := os.OpenFile(filepath.Join(layersDir, "launch.toml"), os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0000)
-				Expect(err).NotTo(HaveOccurred())
+				Expect(os.Chmod(layersDir, 0555)).To(Succeed())
 			})
 
 			it.After(func() {
-				Expect(os.Chmod(filepath.Join(layersDir, "launch.toml"), os.ModePerm)).To(Succeed())
+				Expect(os.Chmod(layersDir, os.ModePerm)).To(Succeed())
 			})
 
 			it("calls the exit handler", func() {
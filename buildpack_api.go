@@ -0,0 +1,141 @@
+package packit
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// apiFeature identifies a capability of the buildpack interface whose
+// availability depends upon the Buildpack API version declared in the
+// buildpack.toml or extension.toml.
+type apiFeature int
+
+const (
+	apiFeatureWritablePlan apiFeature = iota
+	apiFeatureLayerTypes
+	apiFeatureExecD
+	apiFeatureLaunchBOM
+	apiFeatureBuildTOML
+	apiFeatureDefaultProcess
+	apiFeatureProcessWorkingDirectory
+	apiFeatureSBOM
+	apiFeatureDirectProcesses
+	apiFeatureNonDirectProcesses
+	apiFeatureRunImageExtension
+	apiFeatureTargets
+)
+
+// apiSupport describes the range of Buildpack API versions that support a
+// feature. The introduced field is the first version that supports the
+// feature and the removed field is the first version that no longer supports
+// it. Leaving either field empty leaves that end of the range open. The
+// message field is the error reported when a feature that is used outside of
+// that range is required; features that are silently skipped omit it.
+type apiSupport struct {
+	introduced string
+	removed    string
+	message    string
+}
+
+// apiCompatibility is the matrix of features that are supported by each
+// Buildpack API version as described in the specification:
+// https://github.com/buildpacks/spec/blob/main/buildpack.md.
+var apiCompatibility = map[apiFeature]apiSupport{
+	apiFeatureWritablePlan: {
+		removed: "0.5",
+		message: "buildpack plan is read only since Buildpack API v0.5",
+	},
+	apiFeatureLayerTypes: {
+		introduced: "0.6",
+	},
+	apiFeatureExecD: {
+		introduced: "0.5",
+	},
+	apiFeatureLaunchBOM: {
+		introduced: "0.5",
+		message:    "BOM entries in launch.toml is only supported with Buildpack API v0.5 or higher",
+	},
+	apiFeatureBuildTOML: {
+		introduced: "0.5",
+		message:    "build.toml is only supported with Buildpack API v0.5 or higher",
+	},
+	apiFeatureDefaultProcess: {
+		introduced: "0.6",
+		message:    "processes can only be marked as default with Buildpack API v0.6 or higher",
+	},
+	apiFeatureProcessWorkingDirectory: {
+		introduced: "0.8",
+		message:    "processes can only have a specific working directory with Buildpack API v0.8 or higher",
+	},
+	apiFeatureSBOM: {
+		introduced: "0.7",
+		message:    "%s.sbom.* output is only supported with Buildpack API v0.7 or higher",
+	},
+	apiFeatureDirectProcesses: {
+		introduced: "0.9",
+		message:    "direct processes can only be used with Buildpack API v0.9 or higher",
+	},
+	apiFeatureNonDirectProcesses: {
+		removed: "0.9",
+		message: "non direct processes can only be used with Buildpack API v0.8 or lower",
+	},
+	apiFeatureRunImageExtension: {
+		introduced: "0.10",
+		message:    "run.Dockerfile instructions other than ARG and FROM are only supported with Buildpack API v0.10 or higher",
+	},
+	apiFeatureTargets: {
+		introduced: "0.10",
+		message:    "targets are only supported with Buildpack API v0.10 or higher",
+	},
+}
+
+// buildpackAPI is the Buildpack API version declared by a buildpack or
+// extension. It is used to determine which features of the buildpack
+// interface are available. The zero value represents a buildpack or extension
+// that did not declare a version, which must be checked for using declared
+// before calling supports or require.
+type buildpackAPI struct {
+	version *semver.Version
+}
+
+// declared returns true if a Buildpack API version was declared.
+func (a buildpackAPI) declared() bool {
+	return a.version != nil
+}
+
+func newBuildpackAPI(version string) (buildpackAPI, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return buildpackAPI{}, fmt.Errorf("failed to parse buildpack API version %q: %w", version, err)
+	}
+
+	return buildpackAPI{version: v}, nil
+}
+
+// supports returns true if the given feature is available in this Buildpack
+// API version.
+func (a buildpackAPI) supports(feature apiFeature) bool {
+	support := apiCompatibility[feature]
+
+	if support.introduced != "" && a.version.LessThan(semver.MustParse(support.introduced)) {
+		return false
+	}
+
+	if support.removed != "" && !a.version.LessThan(semver.MustParse(support.removed)) {
+		return false
+	}
+
+	return true
+}
+
+// require returns an error if the given feature is not available in this
+// Buildpack API version. Any arguments are used to format the error message
+// declared for the feature.
+func (a buildpackAPI) require(feature apiFeature, args ...interface{}) error {
+	if a.supports(feature) {
+		return nil
+	}
+
+	return fmt.Errorf(apiCompatibility[feature].message, args...)
+}
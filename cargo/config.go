@@ -55,21 +55,31 @@ type ConfigMetadata struct {
 }
 
 type ConfigMetadataDependency struct {
-	Checksum        string        `toml:"checksum"         json:"checksum,omitempty"`
-	CPE             string        `toml:"cpe"              json:"cpe,omitempty"`
-	PURL            string        `toml:"purl"             json:"purl,omitempty"`
-	DeprecationDate *time.Time    `toml:"deprecation_date" json:"deprecation_date,omitempty"`
-	ID              string        `toml:"id"               json:"id,omitempty"`
-	Licenses        []interface{} `toml:"licenses"         json:"licenses,omitempty"`
-	Name            string        `toml:"name"             json:"name,omitempty"`
-	SHA256          string        `toml:"sha256"           json:"sha256,omitempty"`
-	Source          string        `toml:"source"           json:"source,omitempty"`
-	SourceChecksum  string        `toml:"source-checksum"  json:"source-checksum,omitempty"`
-	SourceSHA256    string        `toml:"source_sha256"    json:"source_sha256,omitempty"`
-	Stacks          []string      `toml:"stacks"           json:"stacks,omitempty"`
-	StripComponents int           `toml:"strip-components" json:"strip-components,omitempty"`
-	URI             string        `toml:"uri"              json:"uri,omitempty"`
-	Version         string        `toml:"version"          json:"version,omitempty"`
+	Checksum        string                           `toml:"checksum"         json:"checksum,omitempty"`
+	CPE             string                           `toml:"cpe"              json:"cpe,omitempty"`
+	PURL            string                           `toml:"purl"             json:"purl,omitempty"`
+	DeprecationDate *time.Time                       `toml:"deprecation_date" json:"deprecation_date,omitempty"`
+	ID              string                           `toml:"id"               json:"id,omitempty"`
+	Licenses        []interface{}                    `toml:"licenses"         json:"licenses,omitempty"`
+	Name            string                           `toml:"name"             json:"name,omitempty"`
+	SHA256          string                           `toml:"sha256"           json:"sha256,omitempty"`
+	Source          string                           `toml:"source"           json:"source,omitempty"`
+	SourceChecksum  string                           `toml:"source-checksum"  json:"source-checksum,omitempty"`
+	SourceSHA256    string                           `toml:"source_sha256"    json:"source_sha256,omitempty"`
+	Stacks          []string                         `toml:"stacks"           json:"stacks,omitempty"`
+	OS              string                           `toml:"os"               json:"os,omitempty"`
+	Arch            string                           `toml:"arch"             json:"arch,omitempty"`
+	Distros         []ConfigMetadataDependencyDistro `toml:"distros"          json:"distros,omitempty"`
+	SignatureURI    string                           `toml:"signature-uri"    json:"signature-uri,omitempty"`
+	SignatureKeyID  string                           `toml:"signature-key-id" json:"signature-key-id,omitempty"`
+	StripComponents int                              `toml:"strip-components" json:"strip-components,omitempty"`
+	URI             string                           `toml:"uri"              json:"uri,omitempty"`
+	Version         string                           `toml:"version"          json:"version,omitempty"`
+}
+
+type ConfigMetadataDependencyDistro struct {
+	Name    string `toml:"name"    json:"name,omitempty"`
+	Version string `toml:"version" json:"version,omitempty"`
 }
 
 type ConfigMetadataDependencyConstraint struct {
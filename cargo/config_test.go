@@ -59,8 +59,8 @@ func testConfig(t *testing.T, context spec.G, it spec.S) {
 				},
 				Targets: []cargo.ConfigTarget{
 					{
-						OS:     "linux",
-						Arch:   "arm64",
+						OS:   "linux",
+						Arch: "arm64",
 					},
 				},
 				Metadata: cargo.ConfigMetadata{
@@ -84,6 +84,16 @@ func testConfig(t *testing.T, context spec.G, it spec.S) {
 							SourceChecksum:  "sha256:source-shasum",
 							SourceSHA256:    "source-shasum",
 							Stacks:          []string{"io.buildpacks.stacks.bionic", "org.cloudfoundry.stacks.tiny"},
+							OS:              "linux",
+							Arch:            "amd64",
+							Distros: []cargo.ConfigMetadataDependencyDistro{
+								{
+									Name:    "ubuntu",
+									Version: "22.04",
+								},
+							},
+							SignatureURI:    "http://some-url.sig",
+							SignatureKeyID:  "some-key-id",
 							StripComponents: 1,
 							URI:             "http://some-url",
 							Version:         "1.2.3",
@@ -153,10 +163,18 @@ api = "0.6"
 	source-checksum = "sha256:source-shasum"
   source_sha256 = "source-shasum"
   stacks = ["io.buildpacks.stacks.bionic", "org.cloudfoundry.stacks.tiny"]
+  os = "linux"
+  arch = "amd64"
+  signature-uri = "http://some-url.sig"
+  signature-key-id = "some-key-id"
   strip-components = 1
   uri = "http://some-url"
   version = "1.2.3"
 
+  [[metadata.dependencies.distros]]
+    name = "ubuntu"
+    version = "22.04"
+
 [[metadata.dependency-constraints]]
   id = "some-dependency"
   constraint = "1.*"
@@ -461,10 +479,18 @@ api = "0.6"
   source-checksum = "sha256:source-shasum"
   source_sha256 = "source-shasum"
   stacks = ["io.buildpacks.stacks.bionic", "org.cloudfoundry.stacks.tiny"]
+  os = "linux"
+  arch = "amd64"
+  signature-uri = "http://some-url.sig"
+  signature-key-id = "some-key-id"
 	strip-components = 1
   uri = "http://some-url"
   version = "1.2.3"
 
+  [[metadata.dependencies.distros]]
+    name = "ubuntu"
+    version = "22.04"
+
 [[metadata.dependency-constraints]]
   id = "some-dependency"
   constraint = "1.*"
@@ -526,17 +552,27 @@ api = "0.6"
 					PrePackage: "some-pre-package-script.sh",
 					Dependencies: []cargo.ConfigMetadataDependency{
 						{
-							Checksum:        "sha256:some-sum",
-							CPE:             "some-cpe",
-							PURL:            "some-purl",
-							ID:              "some-dependency",
-							Licenses:        []interface{}{"fancy-license", "fancy-license-2"},
-							Name:            "Some Dependency",
-							SHA256:          "shasum",
-							Source:          "source",
-							SourceChecksum:  "sha256:source-shasum",
-							SourceSHA256:    "source-shasum",
-							Stacks:          []string{"io.buildpacks.stacks.bionic", "org.cloudfoundry.stacks.tiny"},
+							Checksum:       "sha256:some-sum",
+							CPE:            "some-cpe",
+							PURL:           "some-purl",
+							ID:             "some-dependency",
+							Licenses:       []interface{}{"fancy-license", "fancy-license-2"},
+							Name:           "Some Dependency",
+							SHA256:         "shasum",
+							Source:         "source",
+							SourceChecksum: "sha256:source-shasum",
+							SourceSHA256:   "source-shasum",
+							Stacks:         []string{"io.buildpacks.stacks.bionic", "org.cloudfoundry.stacks.tiny"},
+							OS:             "linux",
+							Arch:           "amd64",
+							Distros: []cargo.ConfigMetadataDependencyDistro{
+								{
+									Name:    "ubuntu",
+									Version: "22.04",
+								},
+							},
+							SignatureURI:    "http://some-url.sig",
+							SignatureKeyID:  "some-key-id",
 							StripComponents: 1,
 							URI:             "http://some-url",
 							Version:         "1.2.3",
@@ -0,0 +1,67 @@
+package cargo
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Credentials represents the authentication details that are attached to
+// requests made by the Transport. A bearer token takes precedence over a
+// username and password. Custom headers are added to every request in
+// addition to any Authorization header.
+//
+// The String, GoString and Format methods never reveal the credential values
+// so that Credentials can not be accidentally written to build logs.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+	Headers  map[string]string
+}
+
+// IsZero returns true if no credentials have been provided.
+func (c Credentials) IsZero() bool {
+	return c.Username == "" && c.Password == "" && c.Token == "" && len(c.Headers) == 0
+}
+
+// String implements the fmt.Stringer interface and always returns a redacted
+// value.
+func (c Credentials) String() string {
+	return "cargo.Credentials{REDACTED}"
+}
+
+// GoString implements the fmt.GoStringer interface and always returns a
+// redacted value.
+func (c Credentials) GoString() string {
+	return c.String()
+}
+
+// Format implements the fmt.Formatter interface and always writes a redacted
+// value, regardless of the verb.
+func (c Credentials) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, c.String())
+}
+
+func (c Credentials) apply(request *http.Request) {
+	for name, value := range c.Headers {
+		request.Header.Set(name, value)
+	}
+
+	switch {
+	case c.Token != "":
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	case c.Username != "" || c.Password != "":
+		request.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// strip removes the credentials from a request. It is used when a request is
+// redirected to a different host so that credentials are only ever sent to
+// the host that they were provided for.
+func (c Credentials) strip(request *http.Request) {
+	for name := range c.Headers {
+		request.Header.Del(name)
+	}
+
+	request.Header.Del("Authorization")
+}
@@ -0,0 +1,50 @@
+package cargo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCredentials(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		credentials cargo.Credentials
+	)
+
+	it.Before(func() {
+		credentials = cargo.Credentials{
+			Username: "some-username",
+			Password: "some-password",
+			Token:    "some-token",
+			Headers: map[string]string{
+				"X-Api-Key": "some-api-key",
+			},
+		}
+	})
+
+	context("IsZero", func() {
+		it("returns true only when no credentials are provided", func() {
+			Expect(cargo.Credentials{}.IsZero()).To(BeTrue())
+			Expect(credentials.IsZero()).To(BeFalse())
+			Expect(cargo.Credentials{Headers: map[string]string{"X-Api-Key": "some-api-key"}}.IsZero()).To(BeFalse())
+		})
+	})
+
+	context("when the credentials are formatted", func() {
+		it("never includes the credential values", func() {
+			for _, verb := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x"} {
+				formatted := fmt.Sprintf(verb, credentials)
+				Expect(formatted).NotTo(ContainSubstring("some-password"), verb)
+				Expect(formatted).NotTo(ContainSubstring("some-token"), verb)
+				Expect(formatted).NotTo(ContainSubstring("some-api-key"), verb)
+				Expect(formatted).To(Equal("cargo.Credentials{REDACTED}"), verb)
+			}
+		})
+	})
+}
@@ -18,6 +18,7 @@ func TestUnitCargo(t *testing.T) {
 	suite("Transport", testTransport)
 	suite("ValidatedReader", testValidatedReader)
 	suite("Checksum", testChecksum)
+	suite("Credentials", testCredentials)
 	suite.Run(t)
 }
 
@@ -1,44 +1,458 @@
 package cargo
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
-type Transport struct{}
+// MaxBackoff is the longest time that Transport will wait between retries,
+// including when a server asks for a longer delay using a Retry-After header.
+const MaxBackoff = time.Minute
+
+// A ProgressReporter receives updates on the progress of a download. Progress
+// is called with the uri being fetched, the number of bytes that have been
+// received, and the total size of the content, or -1 when it is not known. It
+// is called often, so implementations that print progress should throttle
+// their output.
+type ProgressReporter interface {
+	Progress(entry string, read, total int64)
+}
+
+type Transport struct {
+	ctx      context.Context
+	timeout  time.Duration
+	retries  int
+	backoff  time.Duration
+	progress ProgressReporter
+}
 
 func NewTransport() Transport {
 	return Transport{}
 }
 
+// WithTimeout sets the maximum amount of time that the Transport will wait
+// for a server to respond to a request, or for the next part of a response
+// body to arrive, before abandoning the attempt. A timed out attempt is
+// retried if retries are enabled. A timeout of zero disables the timeout.
+func (t Transport) WithTimeout(timeout time.Duration) Transport {
+	t.timeout = timeout
+	return t
+}
+
+// WithContext makes the requests of the Transport use the given context. A
+// download, including any wait between its retries, is abandoned when the
+// context is canceled, and a retry is not attempted when its wait would end
+// after the deadline of the context.
+func (t Transport) WithContext(ctx context.Context) Transport {
+	t.ctx = ctx
+	return t
+}
+
+// WithRetries enables retrying of failed requests. Requests that fail with a
+// connection error, a timeout, or a 429 or 5xx status code are retried up to
+// the given number of times. The wait between attempts starts at the given
+// backoff and doubles after each attempt, up to MaxBackoff, unless the server
+// provides a Retry-After header. Downloads that are interrupted part way
+// through are resumed using a Range request when possible.
+func (t Transport) WithRetries(retries int, backoff time.Duration) Transport {
+	t.retries = retries
+	t.backoff = backoff
+	return t
+}
+
+// WithProgress reports the progress of each download to reporter as the
+// returned content is read.
+func (t Transport) WithProgress(reporter ProgressReporter) Transport {
+	t.progress = reporter
+	return t
+}
+
 func (t Transport) Drop(root, uri string) (io.ReadCloser, error) {
+	return t.DropWithCredentials(root, uri, Credentials{})
+}
+
+// DropWithCredentials fetches the given uri in the same way as Drop, attaching
+// the given credentials to each request. Credentials are not sent if the
+// request is redirected to a different host, and they are ignored for
+// file:// uris.
+func (t Transport) DropWithCredentials(root, uri string, credentials Credentials) (io.ReadCloser, error) {
 	if strings.HasPrefix(uri, "file://") {
 		file, err := os.Open(filepath.Join(root, strings.TrimPrefix(uri, "file://")))
 		if err != nil {
 			return nil, fmt.Errorf("failed to open file: %s", err)
 		}
 
+		if t.progress != nil {
+			total := int64(-1)
+			if info, err := file.Stat(); err == nil {
+				total = info.Size()
+			}
+
+			return &progressFile{File: file, reporter: t.progress, uri: uri, total: total}, nil
+		}
+
 		return file, nil
 	}
 
-	request, err := http.NewRequest("GET", uri, nil)
+	d := &download{
+		transport:   t,
+		uri:         uri,
+		credentials: credentials,
+	}
+
+	err := d.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// download is a response body that transparently re-requests the remainder
+// of the content when it is interrupted.
+type download struct {
+	transport   Transport
+	uri         string
+	credentials Credentials
+
+	body      io.ReadCloser
+	cancel    context.CancelFunc
+	timer     *time.Timer
+	offset    int64
+	total     int64
+	validator string
+	attempts  int
+}
+
+// progressFile reports the progress of reading a file:// uri.
+type progressFile struct {
+	*os.File
+	reporter ProgressReporter
+	uri      string
+	read     int64
+	total    int64
+}
+
+func (f *progressFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	if n > 0 {
+		f.read += int64(n)
+		f.reporter.Progress(f.uri, f.read, f.total)
+	}
+
+	return n, err
+}
+
+// attemptError is returned by a single request attempt and records whether
+// the attempt can be retried, and how long to wait if the server asked for a
+// specific delay.
+type attemptError struct {
+	err        error
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (e attemptError) Error() string {
+	return e.err.Error()
+}
+
+func (d *download) Read(p []byte) (int, error) {
+	for {
+		n, err := d.body.Read(p)
+		d.offset += int64(n)
+
+		if d.transport.progress != nil && n > 0 {
+			d.transport.progress.Progress(d.uri, d.offset, d.total)
+		}
+
+		if d.timer != nil {
+			d.timer.Reset(d.transport.timeout)
+		}
+
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+
+		if !d.retryable(err) || d.attempts >= d.transport.retries {
+			return n, d.describe(err)
+		}
+
+		d.close()
+		waitErr := d.wait(0)
+		if waitErr != nil {
+			return n, fmt.Errorf("%s: %w", d.describe(err), waitErr)
+		}
+		d.attempts++
+
+		openErr := d.open()
+		if openErr != nil {
+			return n, openErr
+		}
+
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (d *download) Close() error {
+	return d.close()
+}
+
+func (d *download) close() error {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+
+	var err error
+	if d.body != nil {
+		err = d.body.Close()
+	}
+
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	return err
+}
+
+// open requests the content from the current offset, retrying failed
+// attempts while retries remain.
+func (d *download) open() error {
+	for {
+		err := d.request()
+		if err == nil {
+			return nil
+		}
+
+		var attemptErr attemptError
+		if !errors.As(err, &attemptErr) || !attemptErr.retryable || d.attempts >= d.transport.retries {
+			return err
+		}
+
+		waitErr := d.wait(attemptErr.retryAfter)
+		if waitErr != nil {
+			return fmt.Errorf("%s: %w", err, waitErr)
+		}
+		d.attempts++
+	}
+}
+
+func (d *download) request() error {
+	ctx, cancel := context.WithCancel(d.context())
+
+	request, err := http.NewRequestWithContext(ctx, "GET", d.uri, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse request uri: %s", err)
+		cancel()
+		return fmt.Errorf("failed to parse request uri: %s", err)
+	}
+
+	client := http.DefaultClient
+	if !d.credentials.IsZero() {
+		d.credentials.apply(request)
+		client = &http.Client{CheckRedirect: d.checkRedirect}
+	}
+
+	if d.offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", d.offset))
+		if d.validator != "" {
+			request.Header.Set("If-Range", d.validator)
+		}
 	}
 
-	response, err := http.DefaultClient.Do(request)
+	var timer *time.Timer
+	if d.transport.timeout > 0 {
+		timer = time.AfterFunc(d.transport.timeout, cancel)
+	}
+
+	fail := func(err error) error {
+		if timer != nil {
+			timer.Stop()
+		}
+		cancel()
+		return err
+	}
+
+	response, err := client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %s", err)
+		return fail(attemptError{
+			err:       fmt.Errorf("failed to make request: %s", d.describe(err)),
+			retryable: d.retryable(err),
+		})
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+		response.Body.Close()
+		return fail(attemptError{
+			err:        fmt.Errorf("unexpected status code %d while fetching %q", response.StatusCode, d.uri),
+			retryable:  true,
+			retryAfter: parseRetryAfter(response.Header.Get("Retry-After")),
+		})
 	}
 
 	if response.StatusCode >= 400 {
 		response.Body.Close()
-		return nil, fmt.Errorf("unexpected status code %d while fetching %q", response.StatusCode, uri)
+		return fail(fmt.Errorf("unexpected status code %d while fetching %q", response.StatusCode, d.uri))
+	}
+
+	switch {
+	case d.offset == 0:
+		d.validator = response.Header.Get("ETag")
+		if d.validator == "" || strings.HasPrefix(d.validator, "W/") {
+			d.validator = response.Header.Get("Last-Modified")
+		}
+
+	case response.StatusCode == http.StatusPartialContent:
+		if !strings.HasPrefix(response.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", d.offset)) {
+			response.Body.Close()
+			return fail(fmt.Errorf("failed to resume download of %q: unexpected content range %q", d.uri, response.Header.Get("Content-Range")))
+		}
+
+	default:
+		// The server does not support range requests, or the content has changed
+		// since the first attempt, so the already received bytes are skipped.
+		// Changed content is caught when the checksum is validated.
+		_, err = io.CopyN(io.Discard, response.Body, d.offset)
+		if err != nil {
+			response.Body.Close()
+			return fail(attemptError{
+				err:       fmt.Errorf("failed to resume download of %q: %s", d.uri, err),
+				retryable: d.retryable(err),
+			})
+		}
+	}
+
+	// A partial response only gives the length of the remaining content.
+	d.total = response.ContentLength
+	if response.StatusCode == http.StatusPartialContent && d.total >= 0 {
+		d.total += d.offset
+	}
+
+	d.body = response.Body
+	d.cancel = cancel
+	d.timer = timer
+
+	return nil
+}
+
+// checkRedirect removes the credentials from a redirected request when it is
+// sent to a different host than the original request.
+func (d *download) checkRedirect(request *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	if !strings.EqualFold(request.URL.Host, via[0].URL.Host) {
+		d.credentials.strip(request)
+	}
+
+	return nil
+}
+
+// context returns the context of the Transport, or the background context
+// when none was given.
+func (d *download) context() context.Context {
+	if d.transport.ctx == nil {
+		return context.Background()
+	}
+
+	return d.transport.ctx
+}
+
+// wait sleeps before the next attempt, for the time the server asked for when
+// it is given and otherwise for the current backoff, but never for longer than
+// MaxBackoff. It returns an error instead when the context is canceled, or
+// when its deadline comes before the wait would end.
+func (d *download) wait(retryAfter time.Duration) error {
+	delay := retryAfter
+	if delay <= 0 {
+		delay = d.transport.backoff
+		for i := 0; i < d.attempts && delay < MaxBackoff; i++ {
+			delay *= 2
+		}
+	}
+
+	if delay > MaxBackoff {
+		delay = MaxBackoff
+	}
+
+	ctx := d.context()
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return fmt.Errorf("next attempt in %s would start after the deadline: %w", delay, context.DeadlineExceeded)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryable returns true if the error is the result of a dropped connection
+// or a timeout.
+func (d *download) retryable(err error) bool {
+	if d.context().Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) {
+		// Unless the context of the Transport was canceled, the request context
+		// is only canceled by the timeout timer while the download is open.
+		return d.transport.timeout > 0
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// describe replaces the error that results from the timeout canceling a
+// request with one that explains why it was canceled.
+func (d *download) describe(err error) error {
+	if d.transport.timeout > 0 && errors.Is(err, context.Canceled) && d.context().Err() == nil {
+		return fmt.Errorf("timed out after %s while fetching %q", d.transport.timeout, d.uri)
+	}
+
+	return err
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which can either
+// be a number of seconds or an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err == nil {
+		return time.Until(date)
 	}
 
-	return response.Body, nil
+	return 0
 }
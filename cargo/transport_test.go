@@ -1,13 +1,19 @@
 package cargo_test
 
 import (
+	gocontext "context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/paketo-buildpacks/packit/v2/cargo"
 	"github.com/sclevine/spec"
@@ -15,6 +21,18 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+type progressReporter struct {
+	reads  []int64
+	totals []int64
+	uris   []string
+}
+
+func (r *progressReporter) Progress(entry string, read, total int64) {
+	r.uris = append(r.uris, entry)
+	r.reads = append(r.reads, read)
+	r.totals = append(r.totals, total)
+}
+
 func testTransport(t *testing.T, context spec.G, it spec.S) {
 	var Expect = NewWithT(t).Expect
 
@@ -54,6 +72,24 @@ func testTransport(t *testing.T, context spec.G, it spec.S) {
 				Expect(bundle.Close()).To(Succeed())
 			})
 
+			context("when progress is reported", func() {
+				it("reports the bytes received and the content length", func() {
+					reporter := &progressReporter{}
+					uri := fmt.Sprintf("%s/some-bundle", server.URL)
+
+					bundle, err := transport.WithProgress(reporter).Drop("", uri)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = io.ReadAll(bundle)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(bundle.Close()).To(Succeed())
+
+					Expect(reporter.uris).To(ContainElement(uri))
+					Expect(reporter.reads[len(reporter.reads)-1]).To(BeEquivalentTo(len("some-bundle-contents")))
+					Expect(reporter.totals[len(reporter.totals)-1]).To(BeEquivalentTo(len("some-bundle-contents")))
+				})
+			})
+
 			context("failure cases", func() {
 				context("when the uri is malformed", func() {
 					it("returns an error", func() {
@@ -84,6 +120,436 @@ func testTransport(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when a timeout is set", func() {
+			var server *httptest.Server
+
+			it.Before(func() {
+				transport = transport.WithTimeout(50 * time.Millisecond)
+
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					switch req.URL.Path {
+					case "/slow-response":
+						select {
+						case <-req.Context().Done():
+						case <-time.After(time.Second):
+						}
+					case "/slow-body":
+						w.Header().Set("Content-Length", "20")
+						fmt.Fprint(w, "some-bundle")
+						w.(http.Flusher).Flush()
+
+						select {
+						case <-req.Context().Done():
+						case <-time.After(time.Second):
+						}
+					default:
+						fmt.Fprint(w, "some-bundle-contents")
+					}
+				}))
+			})
+
+			it.After(func() {
+				server.Close()
+			})
+
+			it("downloads the file when the server responds in time", func() {
+				bundle, err := transport.Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+				Expect(err).NotTo(HaveOccurred())
+
+				contents, err := io.ReadAll(bundle)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("some-bundle-contents"))
+
+				Expect(bundle.Close()).To(Succeed())
+			})
+
+			context("failure cases", func() {
+				context("when the server does not respond in time", func() {
+					it("returns an error", func() {
+						_, err := transport.Drop("", fmt.Sprintf("%s/slow-response", server.URL))
+						Expect(err).To(MatchError(fmt.Sprintf("failed to make request: timed out after 50ms while fetching %q", fmt.Sprintf("%s/slow-response", server.URL))))
+					})
+				})
+
+				context("when the response body stalls", func() {
+					it("returns an error", func() {
+						bundle, err := transport.Drop("", fmt.Sprintf("%s/slow-body", server.URL))
+						Expect(err).NotTo(HaveOccurred())
+						defer bundle.Close()
+
+						_, err = io.ReadAll(bundle)
+						Expect(err).To(MatchError(ContainSubstring("timed out after 50ms")))
+					})
+				})
+			})
+		})
+
+		context("when retries are enabled", func() {
+			var (
+				server   *httptest.Server
+				requests []*http.Request
+				handler  func(w http.ResponseWriter, req *http.Request, count int)
+				mutex    sync.Mutex
+			)
+
+			it.Before(func() {
+				transport = transport.WithRetries(3, time.Millisecond)
+
+				requests = nil
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					mutex.Lock()
+					requests = append(requests, req)
+					count := len(requests)
+					mutex.Unlock()
+
+					handler(w, req, count)
+				}))
+			})
+
+			it.After(func() {
+				server.Close()
+			})
+
+			context("when the server returns a server error", func() {
+				it.Before(func() {
+					handler = func(w http.ResponseWriter, req *http.Request, count int) {
+						if count < 3 {
+							w.WriteHeader(http.StatusServiceUnavailable)
+							return
+						}
+
+						fmt.Fprint(w, "some-bundle-contents")
+					}
+				})
+
+				it("retries the request", func() {
+					bundle, err := transport.Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := io.ReadAll(bundle)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(contents)).To(Equal("some-bundle-contents"))
+
+					Expect(bundle.Close()).To(Succeed())
+					Expect(requests).To(HaveLen(3))
+				})
+			})
+
+			context("when the server returns a Retry-After header", func() {
+				it.Before(func() {
+					handler = func(w http.ResponseWriter, req *http.Request, count int) {
+						if count < 2 {
+							w.Header().Set("Retry-After", "1")
+							w.WriteHeader(http.StatusTooManyRequests)
+							return
+						}
+
+						fmt.Fprint(w, "some-bundle-contents")
+					}
+				})
+
+				it("waits before retrying the request", func() {
+					start := time.Now()
+					bundle, err := transport.Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+
+					contents, err := io.ReadAll(bundle)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(contents)).To(Equal("some-bundle-contents"))
+
+					Expect(bundle.Close()).To(Succeed())
+					Expect(requests).To(HaveLen(2))
+				})
+			})
+
+			context("when the server asks for a wait that is longer than MaxBackoff", func() {
+				it.Before(func() {
+					handler = func(w http.ResponseWriter, req *http.Request, count int) {
+						w.Header().Set("Retry-After", "3600")
+						w.WriteHeader(http.StatusServiceUnavailable)
+					}
+				})
+
+				it("clamps the wait to MaxBackoff and gives up when it would end after the context deadline", func() {
+					ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 30*time.Second)
+					defer cancel()
+
+					start := time.Now()
+					_, err := transport.WithContext(ctx).Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+					Expect(err).To(MatchError(ContainSubstring("unexpected status code 503")))
+					Expect(err).To(MatchError(ContainSubstring("next attempt in 1m0s would start after the deadline")))
+					Expect(errors.Is(err, gocontext.DeadlineExceeded)).To(BeTrue())
+					Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+
+					Expect(requests).To(HaveLen(1))
+				})
+			})
+
+			context("when the context is canceled while waiting to retry", func() {
+				it.Before(func() {
+					handler = func(w http.ResponseWriter, req *http.Request, count int) {
+						w.Header().Set("Retry-After", "30")
+						w.WriteHeader(http.StatusServiceUnavailable)
+					}
+				})
+
+				it("stops waiting and returns an error", func() {
+					ctx, cancel := gocontext.WithCancel(gocontext.Background())
+					timer := time.AfterFunc(100*time.Millisecond, cancel)
+					defer timer.Stop()
+
+					start := time.Now()
+					_, err := transport.WithContext(ctx).Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+					Expect(err).To(MatchError(ContainSubstring("unexpected status code 503")))
+					Expect(errors.Is(err, gocontext.Canceled)).To(BeTrue())
+					Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+
+					Expect(requests).To(HaveLen(1))
+				})
+			})
+
+			context("when the download is interrupted", func() {
+				it.Before(func() {
+					handler = func(w http.ResponseWriter, req *http.Request, count int) {
+						if count < 2 {
+							w.Header().Set("ETag", `"some-etag"`)
+							w.Header().Set("Content-Length", "20")
+							fmt.Fprint(w, "some-bundle")
+							w.(http.Flusher).Flush()
+
+							conn, _, err := w.(http.Hijacker).Hijack()
+							if err == nil {
+								conn.Close()
+							}
+							return
+						}
+
+						w.Header().Set("ETag", `"some-etag"`)
+						http.ServeContent(w, req, "some-bundle", time.Time{}, strings.NewReader("some-bundle-contents"))
+					}
+				})
+
+				it("resumes the download from where it was interrupted", func() {
+					bundle, err := transport.Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := io.ReadAll(bundle)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(contents)).To(Equal("some-bundle-contents"))
+
+					Expect(bundle.Close()).To(Succeed())
+					Expect(requests).To(HaveLen(2))
+					Expect(requests[1].Header.Get("Range")).To(Equal("bytes=11-"))
+					Expect(requests[1].Header.Get("If-Range")).To(Equal(`"some-etag"`))
+				})
+
+				it("reports the total size of the content after resuming", func() {
+					reporter := &progressReporter{}
+
+					bundle, err := transport.WithProgress(reporter).Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = io.ReadAll(bundle)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(bundle.Close()).To(Succeed())
+
+					Expect(reporter.reads[len(reporter.reads)-1]).To(BeEquivalentTo(20))
+					Expect(reporter.totals).To(HaveEach(BeEquivalentTo(20)))
+				})
+
+				context("when the server does not support range requests", func() {
+					it.Before(func() {
+						previous := handler
+						handler = func(w http.ResponseWriter, req *http.Request, count int) {
+							if count < 2 {
+								previous(w, req, count)
+								return
+							}
+
+							fmt.Fprint(w, "some-bundle-contents")
+						}
+					})
+
+					it("skips the content that was already received", func() {
+						bundle, err := transport.Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+						Expect(err).NotTo(HaveOccurred())
+
+						contents, err := io.ReadAll(bundle)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(string(contents)).To(Equal("some-bundle-contents"))
+
+						Expect(bundle.Close()).To(Succeed())
+					})
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the server keeps returning a server error", func() {
+					it.Before(func() {
+						handler = func(w http.ResponseWriter, req *http.Request, count int) {
+							w.WriteHeader(http.StatusInternalServerError)
+						}
+					})
+
+					it("returns an error once the retries are exhausted", func() {
+						_, err := transport.Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+						Expect(err).To(MatchError(ContainSubstring("unexpected status code 500 while fetching")))
+						Expect(requests).To(HaveLen(4))
+					})
+				})
+
+				context("when the server returns a client error", func() {
+					it.Before(func() {
+						handler = func(w http.ResponseWriter, req *http.Request, count int) {
+							http.NotFound(w, req)
+						}
+					})
+
+					it("does not retry the request", func() {
+						_, err := transport.Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+						Expect(err).To(MatchError(ContainSubstring("unexpected status code 404 while fetching")))
+						Expect(requests).To(HaveLen(1))
+					})
+				})
+
+				context("when the resumed download returns the wrong content range", func() {
+					it.Before(func() {
+						handler = func(w http.ResponseWriter, req *http.Request, count int) {
+							if count < 2 {
+								w.Header().Set("Content-Length", "20")
+								fmt.Fprint(w, "some-bundle")
+								w.(http.Flusher).Flush()
+
+								conn, _, err := w.(http.Hijacker).Hijack()
+								if err == nil {
+									conn.Close()
+								}
+								return
+							}
+
+							w.Header().Set("Content-Range", "bytes 0-19/20")
+							w.WriteHeader(http.StatusPartialContent)
+							fmt.Fprint(w, "some-bundle-contents")
+						}
+					})
+
+					it("returns an error", func() {
+						bundle, err := transport.Drop("", fmt.Sprintf("%s/some-bundle", server.URL))
+						Expect(err).NotTo(HaveOccurred())
+						defer bundle.Close()
+
+						_, err = io.ReadAll(bundle)
+						Expect(err).To(MatchError(ContainSubstring(`unexpected content range "bytes 0-19/20"`)))
+					})
+				})
+			})
+		})
+
+		context("when credentials are provided", func() {
+			var (
+				server      *httptest.Server
+				otherServer *httptest.Server
+				headers     map[string]http.Header
+				mutex       sync.Mutex
+			)
+
+			it.Before(func() {
+				headers = map[string]http.Header{}
+
+				otherServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					mutex.Lock()
+					headers["other"] = req.Header.Clone()
+					mutex.Unlock()
+
+					fmt.Fprint(w, "other-bundle-contents")
+				}))
+
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					mutex.Lock()
+					headers[req.URL.Path] = req.Header.Clone()
+					mutex.Unlock()
+
+					switch req.URL.Path {
+					case "/redirect":
+						http.Redirect(w, req, fmt.Sprintf("%s/some-bundle", otherServer.URL), http.StatusFound)
+					default:
+						fmt.Fprint(w, "some-bundle-contents")
+					}
+				}))
+			})
+
+			it.After(func() {
+				server.Close()
+				otherServer.Close()
+			})
+
+			context("when the credentials are a username and password", func() {
+				it("uses basic auth", func() {
+					bundle, err := transport.DropWithCredentials("", fmt.Sprintf("%s/some-bundle", server.URL), cargo.Credentials{
+						Username: "some-username",
+						Password: "some-password",
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := io.ReadAll(bundle)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(contents)).To(Equal("some-bundle-contents"))
+					Expect(bundle.Close()).To(Succeed())
+
+					request := &http.Request{Header: headers["/some-bundle"]}
+					username, password, ok := request.BasicAuth()
+					Expect(ok).To(BeTrue())
+					Expect(username).To(Equal("some-username"))
+					Expect(password).To(Equal("some-password"))
+				})
+			})
+
+			context("when the credentials are a token and headers", func() {
+				it("uses bearer auth and sets the headers", func() {
+					bundle, err := transport.DropWithCredentials("", fmt.Sprintf("%s/some-bundle", server.URL), cargo.Credentials{
+						Token: "some-token",
+						Headers: map[string]string{
+							"X-Api-Key": "some-api-key",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(bundle.Close()).To(Succeed())
+
+					Expect(headers["/some-bundle"].Get("Authorization")).To(Equal("Bearer some-token"))
+					Expect(headers["/some-bundle"].Get("X-Api-Key")).To(Equal("some-api-key"))
+				})
+			})
+
+			context("when the request is redirected to another host", func() {
+				it("does not send the credentials to that host", func() {
+					otherURL, err := url.Parse(otherServer.URL)
+					Expect(err).NotTo(HaveOccurred())
+
+					// The test servers share a hostname, so the redirect is made to
+					// "localhost" to make the host differ.
+					otherServer.URL = fmt.Sprintf("http://localhost:%s", otherURL.Port())
+
+					bundle, err := transport.DropWithCredentials("", fmt.Sprintf("%s/redirect", server.URL), cargo.Credentials{
+						Token: "some-token",
+						Headers: map[string]string{
+							"X-Api-Key": "some-api-key",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := io.ReadAll(bundle)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(contents)).To(Equal("other-bundle-contents"))
+					Expect(bundle.Close()).To(Succeed())
+
+					Expect(headers["/redirect"].Get("Authorization")).To(Equal("Bearer some-token"))
+					Expect(headers["other"].Get("Authorization")).To(BeEmpty())
+					Expect(headers["other"].Get("X-Api-Key")).To(BeEmpty())
+				})
+			})
+		})
+
 		context("when the uri is for a file", func() {
 			var (
 				path string
@@ -116,6 +582,23 @@ func testTransport(t *testing.T, context spec.G, it spec.S) {
 				Expect(bundle.Close()).To(Succeed())
 			})
 
+			context("when progress is reported", func() {
+				it("reports the bytes read and the size of the file", func() {
+					reporter := &progressReporter{}
+
+					bundle, err := transport.WithProgress(reporter).Drop(dir, fmt.Sprintf("file://%s", path))
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := io.ReadAll(bundle)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(contents)).To(Equal("some-bundle-contents"))
+					Expect(bundle.Close()).To(Succeed())
+
+					Expect(reporter.reads).To(Equal([]int64{20}))
+					Expect(reporter.totals).To(Equal([]int64{20}))
+				})
+			})
+
 			context("failure cases", func() {
 				it.Before(func() {
 					Expect(os.RemoveAll(dir)).To(Succeed())
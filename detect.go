@@ -1,6 +1,7 @@
 package packit
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
@@ -43,6 +44,10 @@ type DetectContext struct {
 	// Stack is the value of the chosen stack. This value is populated from the
 	// $CNB_STACK_ID environment variable.
 	Stack string
+
+	// Target is the platform that the application image is being built for.
+	// This value is populated from the $CNB_TARGET_* environment variables.
+	Target Target
 }
 
 // DetectResult allows buildpack authors to indicate the result of the detect
@@ -62,6 +67,7 @@ func Detect(f DetectFunc, options ...Option) {
 	config := OptionConfig{
 		exitHandler: internal.NewExitHandler(),
 		args:        os.Args,
+		fileWriter:  internal.NewFileWriter(),
 	}
 
 	for _, option := range options {
@@ -84,18 +90,25 @@ func Detect(f DetectFunc, options ...Option) {
 		cnbPath = filepath.Clean(strings.TrimSuffix(config.args[0], filepath.Join("bin", "detect")))
 	}
 
-	info := Info{}
+	var (
+		info       Info
+		apiVersion string
+	)
 	if isExtension {
 		_, err = toml.DecodeFile(filepath.Join(cnbPath, "extension.toml"), &struct {
-			Extension *Info `toml:"extension"`
+			APIVersion *string `toml:"api"`
+			Extension  *Info   `toml:"extension"`
 		}{
-			Extension: &info,
+			APIVersion: &apiVersion,
+			Extension:  &info,
 		})
 	} else {
 		_, err = toml.DecodeFile(filepath.Join(cnbPath, "buildpack.toml"), &struct {
-			Buildpack *Info `toml:"buildpack"`
+			APIVersion *string `toml:"api"`
+			Buildpack  *Info   `toml:"buildpack"`
 		}{
-			Buildpack: &info,
+			APIVersion: &apiVersion,
+			Buildpack:  &info,
 		})
 	}
 	if err != nil {
@@ -103,6 +116,17 @@ func Detect(f DetectFunc, options ...Option) {
 		return
 	}
 
+	// A buildpack.toml or extension.toml without an api is still accepted, in
+	// which case no compatibility checks are made.
+	var api buildpackAPI
+	if apiVersion != "" {
+		api, err = newBuildpackAPI(apiVersion)
+		if err != nil {
+			config.exitHandler.Error(err)
+			return
+		}
+	}
+
 	platformPath, ok := os.LookupEnv("CNB_PLATFORM_DIR")
 	if !ok {
 		platformPath = config.args[1]
@@ -117,6 +141,7 @@ func Detect(f DetectFunc, options ...Option) {
 		BuildpackInfo: info,
 		Info:          info,
 		Stack:         os.Getenv("CNB_STACK_ID"),
+		Target:        newTarget(api),
 	})
 	if err != nil {
 		config.exitHandler.Error(err)
@@ -128,14 +153,14 @@ func Detect(f DetectFunc, options ...Option) {
 		planPath = config.args[2]
 	}
 
-	file, err := os.OpenFile(planPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	buffer := bytes.NewBuffer(nil)
+	err = toml.NewEncoder(buffer).Encode(result.Plan)
 	if err != nil {
 		config.exitHandler.Error(err)
 		return
 	}
-	defer file.Close()
 
-	err = toml.NewEncoder(file).Encode(result.Plan)
+	err = config.fileWriter.Write(planPath, buffer)
 	if err != nil {
 		config.exitHandler.Error(err)
 		return
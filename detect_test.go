@@ -177,6 +177,76 @@ api = "0.5"
 			})
 		})
 
+		context("when the api version is 0.10 or higher", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+api = "0.10"
+[buildpack]
+  id = "some-id"
+`), 0600)).To(Succeed())
+
+				Expect(os.Setenv("CNB_TARGET_OS", "linux")).To(Succeed())
+				Expect(os.Setenv("CNB_TARGET_ARCH", "amd64")).To(Succeed())
+				Expect(os.Setenv("CNB_TARGET_DISTRO_NAME", "ubuntu")).To(Succeed())
+				Expect(os.Setenv("CNB_TARGET_DISTRO_VERSION", "22.04")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("CNB_TARGET_OS")).To(Succeed())
+				Expect(os.Unsetenv("CNB_TARGET_ARCH")).To(Succeed())
+				Expect(os.Unsetenv("CNB_TARGET_DISTRO_NAME")).To(Succeed())
+				Expect(os.Unsetenv("CNB_TARGET_DISTRO_VERSION")).To(Succeed())
+			})
+
+			it("provides the target in the detect context", func() {
+				var context packit.DetectContext
+
+				packit.Detect(func(ctx packit.DetectContext) (packit.DetectResult, error) {
+					context = ctx
+
+					return packit.DetectResult{}, nil
+				}, packit.WithArgs([]string{binaryPath, platformDir, planPath}), packit.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.ErrorCall.CallCount).To(Equal(0))
+				Expect(context.Target).To(Equal(packit.Target{
+					OS:            "linux",
+					Arch:          "amd64",
+					DistroName:    "ubuntu",
+					DistroVersion: "22.04",
+				}))
+			})
+		})
+
+		context("when the buildpack.toml does not declare an api version", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+[buildpack]
+  id = "some-id"
+`), 0600)).To(Succeed())
+
+				Expect(os.Setenv("CNB_TARGET_OS", "linux")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("CNB_TARGET_OS")).To(Succeed())
+			})
+
+			it("provides the detect context without a target", func() {
+				var context packit.DetectContext
+
+				packit.Detect(func(ctx packit.DetectContext) (packit.DetectResult, error) {
+					context = ctx
+
+					return packit.DetectResult{}, nil
+				}, packit.WithArgs([]string{binaryPath, platformDir, planPath}), packit.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.ErrorCall.CallCount).To(Equal(0))
+				Expect(context.Info.ID).To(Equal("some-id"))
+				Expect(context.Target).To(Equal(packit.Target{}))
+				Expect(planPath).To(BeARegularFile())
+			})
+		})
+
 		it("writes out the buildplan.toml", func() {
 			packit.Detect(func(packit.DetectContext) (packit.DetectResult, error) {
 				return packit.DetectResult{
@@ -489,6 +559,24 @@ api = "0.5"
 		})
 
 		context("failure cases", func() {
+			context("when the buildpack.toml api version is invalid", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+api = "not-a-version"
+[buildpack]
+  id = "some-id"
+`), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					packit.Detect(func(packit.DetectContext) (packit.DetectResult, error) {
+						return packit.DetectResult{}, nil
+					}, packit.WithArgs([]string{binaryPath, platformDir, planPath}), packit.WithExitHandler(exitHandler))
+
+					Expect(exitHandler.ErrorCall.Receives.Error).To(MatchError(ContainSubstring(`failed to parse buildpack API version "not-a-version"`)))
+				})
+			})
+
 			context("when the buildpack.toml cannot be read", func() {
 				it("returns an error", func() {
 					packit.Detect(func(packit.DetectContext) (packit.DetectResult, error) {
@@ -499,10 +587,13 @@ api = "0.5"
 				})
 			})
 
-			context("when the buildplan.toml cannot be opened", func() {
+			context("when the buildplan.toml cannot be written", func() {
 				it.Before(func() {
-					_, err := os.OpenFile(planPath, os.O_CREATE|os.O_RDWR, 0000)
-					Expect(err).NotTo(HaveOccurred())
+					Expect(os.Chmod(planDir, 0555)).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Chmod(planDir, os.ModePerm)).To(Succeed())
 				})
 
 				it("returns an error", func() {
@@ -2,40 +2,108 @@ package fs
 
 import (
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
+)
+
+// A ChecksumAlgorithm is a hash algorithm that a ChecksumCalculator can use.
+type ChecksumAlgorithm string
+
+const (
+	// SHA256 is the sha256 hash algorithm. It is the default.
+	SHA256 ChecksumAlgorithm = "sha256"
+
+	// SHA512 is the sha512 hash algorithm.
+	SHA512 ChecksumAlgorithm = "sha512"
 )
 
 // ChecksumCalculator can be used to calculate the SHA256 checksum of a given file or
 // directory. When given a directory, checksum calculation will be performed in
-// parallel.
-type ChecksumCalculator struct{}
+// parallel. Other algorithms can be selected using WithAlgorithm.
+type ChecksumCalculator struct {
+	algorithm ChecksumAlgorithm
+	metadata  bool
+	excludes  []string
+}
 
 // NewChecksumCalculator returns a new instance of a ChecksumCalculator.
 func NewChecksumCalculator() ChecksumCalculator {
 	return ChecksumCalculator{}
 }
 
+// WithAlgorithm returns a ChecksumCalculator that uses the given hash
+// algorithm.
+func (c ChecksumCalculator) WithAlgorithm(algorithm ChecksumAlgorithm) ChecksumCalculator {
+	c.algorithm = algorithm
+	return c
+}
+
+// WithMetadata returns a ChecksumCalculator whose checksums also cover the
+// layout of the given directories and not only the content of their files.
+// The relative path and mode of every file, directory and symlink, as well as
+// the target of every symlink, are included in the checksum, so that renaming
+// a file, changing its mode, retargeting a symlink or adding an empty
+// directory all change it.
+func (c ChecksumCalculator) WithMetadata() ChecksumCalculator {
+	c.metadata = true
+	return c
+}
+
+// WithExcludes returns a ChecksumCalculator that leaves files matching any of
+// the given glob patterns out of the checksum. A pattern containing a slash is
+// matched against the slash separated path of a file relative to the
+// directory being summed, and any other pattern is matched against the name of
+// a file at any depth. Excluding a directory excludes everything within it.
+func (c ChecksumCalculator) WithExcludes(patterns ...string) ChecksumCalculator {
+	c.excludes = append(append([]string{}, c.excludes...), patterns...)
+	return c
+}
+
 type calculatedFile struct {
 	path     string
 	checksum []byte
 	err      error
 }
 
-// Sum returns a hex-encoded SHA256 checksum value of a file or directory given a path.
+// Sum returns a hex-encoded checksum value of a file or directory given a path.
+// The checksum is SHA256 unless another algorithm was selected.
 func (c ChecksumCalculator) Sum(paths ...string) (string, error) {
+	newHash, err := c.hasher()
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+
+	for _, pattern := range c.excludes {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return "", fmt.Errorf("failed to calculate checksum: invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
 	var files []string
-	for _, path := range paths {
-		err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
 
+			if c.excluded(root, path) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
 			if info.Mode().IsRegular() {
 				files = append(files, path)
 			}
@@ -49,19 +117,28 @@ func (c ChecksumCalculator) Sum(paths ...string) (string, error) {
 
 	//Gather all checksums
 	var sums [][]byte
-	for _, f := range getParallelChecksums(files) {
+	checksums := map[string][]byte{}
+	for _, f := range getParallelChecksums(files, newHash) {
 		if f.err != nil {
 			return "", fmt.Errorf("failed to calculate checksum: %w", f.err)
 		}
 
 		sums = append(sums, f.checksum)
+		checksums[f.path] = f.checksum
+	}
+
+	if c.metadata {
+		sums, err = c.metadataChecksums(paths, checksums, newHash)
+		if err != nil {
+			return "", fmt.Errorf("failed to calculate checksum: %w", err)
+		}
 	}
 
 	if len(sums) == 1 {
 		return hex.EncodeToString(sums[0]), nil
 	}
 
-	hash := sha256.New()
+	hash := newHash()
 	for _, sum := range sums {
 		_, err := hash.Write(sum)
 		if err != nil {
@@ -71,7 +148,100 @@ func (c ChecksumCalculator) Sum(paths ...string) (string, error) {
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
-func getParallelChecksums(filesFromDir []string) []calculatedFile {
+func (c ChecksumCalculator) hasher() (func() hash.Hash, error) {
+	switch c.algorithm {
+	case "", SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", c.algorithm)
+	}
+}
+
+func (c ChecksumCalculator) excluded(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+
+	return matchesAny(c.excludes, filepath.ToSlash(rel))
+}
+
+// matchesAny reports whether the slash separated relative path rel matches
+// any of the given glob patterns. Patterns that do not contain a slash are
+// matched against the last element of rel.
+func matchesAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// metadataChecksums returns a checksum for each of the given paths that covers
+// the relative path, mode and content or symlink target of everything within
+// it. The checksums are sorted so that the order of the paths does not
+// matter.
+func (c ChecksumCalculator) metadataChecksums(paths []string, checksums map[string][]byte, newHash func() hash.Hash) ([][]byte, error) {
+	var sums [][]byte
+	for _, root := range paths {
+		hash := newHash()
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if c.excluded(root, path) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+
+			var value string
+			switch {
+			case info.Mode().IsRegular():
+				value = hex.EncodeToString(checksums[path])
+
+			case info.Mode()&os.ModeSymlink != 0:
+				value, err = os.Readlink(path)
+				if err != nil {
+					return err
+				}
+			}
+
+			_, err = fmt.Fprintf(hash, "%s\x00%o\x00%s\x00", filepath.ToSlash(rel), uint32(info.Mode()), value)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sums = append(sums, hash.Sum(nil))
+	}
+
+	sort.Slice(sums, func(i, j int) bool {
+		return string(sums[i]) < string(sums[j])
+	})
+
+	return sums, nil
+}
+
+func getParallelChecksums(filesFromDir []string, newHash func() hash.Hash) []calculatedFile {
 	var checksumResults []calculatedFile
 	numFiles := len(filesFromDir)
 	files := make(chan string, numFiles)
@@ -79,7 +249,7 @@ func getParallelChecksums(filesFromDir []string) []calculatedFile {
 
 	//Spawns workers
 	for i := 0; i < runtime.NumCPU(); i++ {
-		go fileChecksumer(files, calculatedFiles)
+		go fileChecksumer(files, calculatedFiles, newHash)
 	}
 
 	//Puts files in worker queue
@@ -102,7 +272,7 @@ func getParallelChecksums(filesFromDir []string) []calculatedFile {
 	return checksumResults
 }
 
-func fileChecksumer(files chan string, calculatedFiles chan calculatedFile) {
+func fileChecksumer(files chan string, calculatedFiles chan calculatedFile, newHash func() hash.Hash) {
 	for path := range files {
 		result := calculatedFile{path: path}
 
@@ -113,7 +283,7 @@ func fileChecksumer(files chan string, calculatedFiles chan calculatedFile) {
 			continue
 		}
 
-		hash := sha256.New()
+		hash := newHash()
 		_, err = io.Copy(hash, file)
 		if err != nil {
 			result.err = err
@@ -220,6 +220,138 @@ func testChecksumCalculator(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the SHA512 algorithm is selected", func() {
+			var path string
+
+			it.Before(func() {
+				path = filepath.Join(workingDir, "some-file")
+				Expect(os.WriteFile(path, []byte{}, os.ModePerm)).To(Succeed())
+			})
+
+			it("generates the SHA512 checksum for that file", func() {
+				sum, err := calculator.WithAlgorithm(fs.SHA512).Sum(path)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sum).To(Equal("cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e"))
+			})
+		})
+
+		context("when given excludes", func() {
+			var dir string
+
+			it.Before(func() {
+				dir = filepath.Join(workingDir, "some-dir")
+				Expect(os.MkdirAll(filepath.Join(dir, "node_modules", "some-module"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(dir, "src"), os.ModePerm)).To(Succeed())
+
+				Expect(os.WriteFile(filepath.Join(dir, "src", "some-file"), []byte("some-content"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, "src", "some-file.log"), []byte("some-log"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, "node_modules", "some-module", "index.js"), []byte("some-module"), 0644)).To(Succeed())
+			})
+
+			it("leaves the matching files out of the checksum", func() {
+				sum, err := calculator.WithExcludes("*.log", "node_modules").Sum(dir)
+				Expect(err).ToNot(HaveOccurred())
+
+				expected, err := calculator.Sum(filepath.Join(dir, "src", "some-file"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sum).To(Equal(expected))
+			})
+
+			it("matches patterns containing a slash against the relative path", func() {
+				sum, err := calculator.WithExcludes("src/*.log", "node_modules/*").Sum(dir)
+				Expect(err).ToNot(HaveOccurred())
+
+				expected, err := calculator.Sum(filepath.Join(dir, "src", "some-file"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sum).To(Equal(expected))
+			})
+
+			context("failure cases", func() {
+				context("when a pattern is invalid", func() {
+					it("returns an error", func() {
+						_, err := calculator.WithExcludes("[").Sum(dir)
+						Expect(err).To(MatchError(ContainSubstring(`failed to calculate checksum: invalid exclude pattern "["`)))
+					})
+				})
+			})
+		})
+
+		context("when metadata is included", func() {
+			var (
+				dir string
+				sum string
+			)
+
+			it.Before(func() {
+				calculator = calculator.WithMetadata()
+
+				dir = filepath.Join(workingDir, "some-dir")
+				Expect(os.MkdirAll(filepath.Join(dir, "some-sub-dir"), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, "some-sub-dir", "some-file"), []byte("some-content"), 0644)).To(Succeed())
+				Expect(os.Symlink("some-file", filepath.Join(dir, "some-sub-dir", "some-symlink"))).To(Succeed())
+
+				var err error
+				sum, err = calculator.Sum(dir)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			it("generates the same checksum for the same directory", func() {
+				other := filepath.Join(workingDir, "some-other-dir")
+				Expect(fs.CopyWithOptions(dir, other, fs.CopyOptions{})).To(Succeed())
+				Expect(os.Chmod(other, 0755)).To(Succeed())
+				Expect(os.Chmod(filepath.Join(other, "some-sub-dir"), 0755)).To(Succeed())
+
+				otherSum, err := calculator.Sum(other)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(otherSum).To(Equal(sum))
+
+				contentSum, err := fs.NewChecksumCalculator().Sum(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(contentSum).NotTo(Equal(sum))
+			})
+
+			it("changes when a file is renamed", func() {
+				Expect(os.Rename(filepath.Join(dir, "some-sub-dir", "some-file"), filepath.Join(dir, "some-sub-dir", "other-file"))).To(Succeed())
+
+				newSum, err := calculator.Sum(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newSum).NotTo(Equal(sum))
+			})
+
+			it("changes when the mode of a file changes", func() {
+				Expect(os.Chmod(filepath.Join(dir, "some-sub-dir", "some-file"), 0755)).To(Succeed())
+
+				newSum, err := calculator.Sum(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newSum).NotTo(Equal(sum))
+			})
+
+			it("changes when the target of a symlink changes", func() {
+				Expect(os.Remove(filepath.Join(dir, "some-sub-dir", "some-symlink"))).To(Succeed())
+				Expect(os.Symlink("other-file", filepath.Join(dir, "some-sub-dir", "some-symlink"))).To(Succeed())
+
+				newSum, err := calculator.Sum(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newSum).NotTo(Equal(sum))
+			})
+
+			it("changes when an empty directory is added", func() {
+				Expect(os.Mkdir(filepath.Join(dir, "empty-dir"), 0755)).To(Succeed())
+
+				newSum, err := calculator.Sum(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newSum).NotTo(Equal(sum))
+			})
+
+			it("does not change when an excluded file is added", func() {
+				Expect(os.WriteFile(filepath.Join(dir, "some-file.log"), []byte("some-log"), 0644)).To(Succeed())
+
+				newSum, err := calculator.WithExcludes("*.log").Sum(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newSum).To(Equal(sum))
+			})
+		})
+
 		context("failure cases", func() {
 			context("when any of the given paths do not exist", func() {
 				it("returns an error", func() {
@@ -228,6 +360,13 @@ func testChecksumCalculator(t *testing.T, context spec.G, it spec.S) {
 					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
 				})
 			})
+
+			context("when the algorithm is not supported", func() {
+				it("returns an error", func() {
+					_, err := calculator.WithAlgorithm("md5").Sum(workingDir)
+					Expect(err).To(MatchError(`failed to calculate checksum: unsupported algorithm "md5"`))
+				})
+			})
 		})
 	})
 }
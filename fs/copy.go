@@ -3,16 +3,51 @@ package fs
 import (
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 )
 
+// CopyOptions controls how files are copied by CopyWithOptions. The zero value
+// copies files in the same way as Copy.
+type CopyOptions struct {
+	// PreserveModTimes sets the modification times of the copied files,
+	// directories and symlinks to those of their sources.
+	PreserveModTimes bool
+
+	// PreserveOwnership sets the uid and gid of the copied files, directories
+	// and symlinks to those of their sources. It is ignored unless the process
+	// is running as root.
+	PreserveOwnership bool
+
+	// FollowSymlinks copies the files and directories that symlinks refer to
+	// rather than the symlinks themselves. Symlinks that cannot be resolved, or
+	// that refer to one of their own parent directories, including those they
+	// are reached through by other symlinks, cause the copy to fail.
+	FollowSymlinks bool
+
+	// Concurrency is the number of files that are copied at the same time. It
+	// defaults to the number of CPUs.
+	Concurrency int
+}
+
 // Copy will move a source file or directory to a destination. For directories,
 // move will remap relative symlinks ensuring that they align with the
 // destination directory. If the destination exists prior to invocation, it
 // will be removed.
+//
+// On Linux, files are cloned when the filesystem supports reflinks and are
+// otherwise copied within the kernel, falling back to a buffered copy. The
+// files within a directory are copied in parallel.
 func Copy(source, destination string) error {
+	return CopyWithOptions(source, destination, CopyOptions{})
+}
+
+// CopyWithOptions copies a source file or directory to a destination in the
+// same way as Copy, using the given options.
+func CopyWithOptions(source, destination string, options CopyOptions) error {
 	err := os.Remove(destination)
 	if err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
@@ -25,13 +60,18 @@ func Copy(source, destination string) error {
 		return err
 	}
 
+	c := copier{
+		options: options,
+		root:    os.Geteuid() == 0,
+	}
+
 	if info.IsDir() {
-		err = copyDirectory(source, destination)
+		err = c.copyDirectory(source, destination)
 		if err != nil {
 			return err
 		}
 	} else {
-		err = copyFile(source, destination)
+		err = c.copyFile(source, destination, info)
 		if err != nil {
 			return err
 		}
@@ -40,88 +80,262 @@ func Copy(source, destination string) error {
 	return nil
 }
 
-func copyFile(source, destination string) error {
-	sourceFile, err := os.Open(source)
-	if err != nil {
-		return err
+type copyJob struct {
+	source      string
+	destination string
+	info        os.FileInfo
+}
+
+type copiedEntry struct {
+	path string
+	info os.FileInfo
+}
+
+// A copier walks a source directory, creating its directories and symlinks as
+// it goes and handing its files to a pool of workers. The metadata of the
+// directories and symlinks is preserved once all of the files have been
+// copied, so that creating the files does not change the modification times
+// of their parent directories.
+type copier struct {
+	options CopyOptions
+	root    bool
+
+	jobs  chan copyJob
+	group sync.WaitGroup
+	mutex sync.Mutex
+	err   error
+
+	entries []copiedEntry
+
+	// following holds the resolved directories of the symlinks that are being
+	// followed, from the outermost to the innermost.
+	following []string
+}
+
+func (c *copier) copyDirectory(source, destination string) error {
+	concurrency := c.options.Concurrency
+	if concurrency < 1 {
+		concurrency = runtime.NumCPU()
 	}
-	defer sourceFile.Close()
 
-	destinationFile, err := os.Create(destination)
-	if err != nil {
-		return err
+	c.jobs = make(chan copyJob, concurrency)
+	for i := 0; i < concurrency; i++ {
+		c.group.Add(1)
+		go c.work()
 	}
-	defer destinationFile.Close()
 
-	_, err = io.Copy(destinationFile, sourceFile)
+	err := c.walk(source, destination)
+	close(c.jobs)
+	c.group.Wait()
+
 	if err != nil {
 		return err
 	}
 
-	info, err := sourceFile.Stat()
-	if err != nil {
+	if err := c.failure(); err != nil {
 		return err
 	}
 
-	err = os.Chmod(destination, info.Mode())
-	if err != nil {
-		return err
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		entry := c.entries[i]
+
+		err = c.preserveOwnership(entry.path, entry.info)
+		if err != nil {
+			return err
+		}
+
+		err = c.preserveModTime(entry.path, entry.info)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func copyDirectory(source, destination string) error {
-	err := filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+func (c *copier) work() {
+	defer c.group.Done()
+
+	for job := range c.jobs {
+		if c.failure() != nil {
+			continue
+		}
+
+		err := c.copyFile(job.source, job.destination, job.info)
 		if err != nil {
-			return err
+			c.mutex.Lock()
+			if c.err == nil {
+				c.err = err
+			}
+			c.mutex.Unlock()
 		}
+	}
+}
+
+func (c *copier) failure() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-		path, err = filepath.Rel(source, path)
+	return c.err
+}
+
+func (c *copier) walk(source, destination string) error {
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		switch {
-		case info.IsDir():
-			err = os.Mkdir(filepath.Join(destination, path), os.ModePerm)
-			if err != nil {
-				return err
-			}
+		// Stop walking as soon as one of the workers has failed.
+		if err := c.failure(); err != nil {
+			return err
+		}
 
-		case (info.Mode() & os.ModeSymlink) != 0:
-			err = copyLink(source, destination, path)
-			if err != nil {
-				return err
-			}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
 
-		default:
-			err = copyFile(filepath.Join(source, path), filepath.Join(destination, path))
-			if err != nil {
-				return err
-			}
+		if (info.Mode()&os.ModeSymlink) != 0 && c.options.FollowSymlinks {
+			return c.follow(path, filepath.Join(destination, rel))
 		}
 
-		return nil
+		return c.copyEntry(path, filepath.Join(destination, rel), info)
 	})
+}
+
+func (c *copier) copyEntry(path, destination string, info os.FileInfo) error {
+	switch {
+	case info.IsDir():
+		err := os.Mkdir(destination, os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		c.record(destination, info)
+
+	case (info.Mode() & os.ModeSymlink) != 0:
+		link, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
 
+		err = os.Symlink(link, destination)
+		if err != nil {
+			return err
+		}
+
+		c.record(destination, info)
+
+	default:
+		c.jobs <- copyJob{source: path, destination: destination, info: info}
+	}
+
+	return nil
+}
+
+// follow copies the file or directory that the symlink at path refers to.
+func (c *copier) follow(path, destination string) error {
+	info, err := os.Stat(path)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if !info.IsDir() {
+		return c.copyEntry(path, destination, info)
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	c.following = append(c.following, parent)
+	defer func() { c.following = c.following[:len(c.following)-1] }()
+
+	// A target that contains the directory of this symlink, or of any symlink
+	// that is being followed to reach it, would be walked again from within
+	// itself.
+	for _, dir := range c.following {
+		rel, err := filepath.Rel(target, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			return fmt.Errorf("failed to follow symlink %s: it refers to one of its parent directories", path)
+		}
+	}
+
+	return c.walk(target, destination)
 }
 
-func copyLink(source, destination, path string) error {
-	link, err := os.Readlink(filepath.Join(source, path))
+func (c *copier) record(path string, info os.FileInfo) {
+	if c.options.PreserveModTimes || c.options.PreserveOwnership {
+		c.entries = append(c.entries, copiedEntry{path: path, info: info})
+	}
+}
+
+func (c *copier) copyFile(source, destination string, info os.FileInfo) error {
+	sourceFile, err := os.Open(source)
 	if err != nil {
 		return err
 	}
+	defer sourceFile.Close()
 
-	err = os.Symlink(link, filepath.Join(destination, path))
+	destinationFile, err := os.Create(destination)
 	if err != nil {
 		return err
 	}
+	defer destinationFile.Close()
+
+	err = copyContent(destinationFile, sourceFile)
+	if err != nil {
+		return err
+	}
+
+	// Ownership is preserved before the mode is set as changing it clears the
+	// setuid and setgid bits.
+	err = c.preserveOwnership(destination, info)
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(destination, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	err = c.preserveModTime(destination, info)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *copier) preserveOwnership(path string, info os.FileInfo) error {
+	if !c.options.PreserveOwnership || !c.root {
+		return nil
+	}
+
+	err := copyOwnership(path, info)
+	if err != nil {
+		return fmt.Errorf("failed to preserve ownership of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *copier) preserveModTime(path string, info os.FileInfo) error {
+	if !c.options.PreserveModTimes {
+		return nil
+	}
+
+	err := copyModTime(path, info)
+	if err != nil {
+		return fmt.Errorf("failed to preserve modification time of %s: %w", path, err)
+	}
 
 	return nil
 }
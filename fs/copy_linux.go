@@ -0,0 +1,49 @@
+//go:build linux
+// +build linux
+
+package fs
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyContent copies the content of source to destination. It first attempts
+// to clone source so that the copy shares its blocks on filesystems that
+// support reflinks, such as btrfs and xfs. Otherwise it copies the content
+// within the kernel using copy_file_range, falling back to a buffered copy
+// when that is not supported.
+func copyContent(destination, source *os.File) error {
+	err := unix.IoctlFileClone(int(destination.Fd()), int(source.Fd()))
+	if err == nil {
+		return nil
+	}
+
+	for {
+		n, err := unix.CopyFileRange(int(source.Fd()), nil, int(destination.Fd()), nil, 1<<30, 0)
+		if err != nil {
+			if errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EXDEV) || errors.Is(err, unix.EINVAL) || errors.Is(err, unix.EOPNOTSUPP) {
+				break
+			}
+
+			return err
+		}
+
+		if n == 0 {
+			break
+		}
+	}
+
+	// copy_file_range advances the offsets of both files, so the buffered copy
+	// picks up wherever it stopped. For regular files that it copied in full,
+	// there is nothing left to copy.
+	_, err = io.Copy(destination, source)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
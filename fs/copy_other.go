@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package fs
+
+import (
+	"io"
+	"os"
+)
+
+// copyContent copies the content of source to destination.
+func copyContent(destination, source *os.File) error {
+	_, err := io.Copy(destination, source)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,9 +1,11 @@
 package fs_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/paketo-buildpacks/packit/v2/fs"
 	"github.com/sclevine/spec"
@@ -182,6 +184,138 @@ func testCopy(t *testing.T, context spec.G, it spec.S) {
 				})
 			})
 
+			context("when there are many files", func() {
+				it.Before(func() {
+					for i := 0; i < 100; i++ {
+						err := os.WriteFile(filepath.Join(source, fmt.Sprintf("file-%d", i)), []byte(fmt.Sprintf("content-%d", i)), 0644)
+						Expect(err).NotTo(HaveOccurred())
+					}
+				})
+
+				it("copies all of them", func() {
+					err := fs.CopyWithOptions(source, destination, fs.CopyOptions{Concurrency: 3})
+					Expect(err).NotTo(HaveOccurred())
+
+					for i := 0; i < 100; i++ {
+						content, err := os.ReadFile(filepath.Join(destination, fmt.Sprintf("file-%d", i)))
+						Expect(err).NotTo(HaveOccurred())
+						Expect(string(content)).To(Equal(fmt.Sprintf("content-%d", i)))
+					}
+				})
+			})
+
+			context("when modification times are preserved", func() {
+				var modTime time.Time
+
+				it.Before(func() {
+					modTime = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+					for _, path := range []string{
+						filepath.Join(source, "some-dir", "some-file"),
+						filepath.Join(source, "some-dir"),
+						source,
+					} {
+						Expect(os.Chtimes(path, modTime, modTime)).To(Succeed())
+					}
+				})
+
+				it("sets the modification times of the copies to those of their sources", func() {
+					err := fs.CopyWithOptions(source, destination, fs.CopyOptions{PreserveModTimes: true})
+					Expect(err).NotTo(HaveOccurred())
+
+					for _, path := range []string{
+						filepath.Join(destination, "some-dir", "some-file"),
+						filepath.Join(destination, "some-dir"),
+						destination,
+					} {
+						info, err := os.Stat(path)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(info.ModTime()).To(BeTemporally("==", modTime), path)
+					}
+				})
+			})
+
+			context("when ownership is preserved", func() {
+				it("copies the files", func() {
+					err := fs.CopyWithOptions(source, destination, fs.CopyOptions{PreserveOwnership: true})
+					Expect(err).NotTo(HaveOccurred())
+
+					info, err := os.Lstat(filepath.Join(destination, "some-dir", "some-file"))
+					Expect(err).NotTo(HaveOccurred())
+
+					uid, ok := fileOwner(info)
+					if !ok {
+						t.Skip("file ownership is not available on this platform")
+					}
+					Expect(uid).To(Equal(os.Geteuid()))
+				})
+			})
+
+			context("when symlinks are followed", func() {
+				it.Before(func() {
+					Expect(os.Symlink(external, filepath.Join(source, "external-dir"))).To(Succeed())
+				})
+
+				it("copies the files and directories that they refer to", func() {
+					err := fs.CopyWithOptions(source, destination, fs.CopyOptions{FollowSymlinks: true})
+					Expect(err).NotTo(HaveOccurred())
+
+					for _, path := range []string{
+						filepath.Join(destination, "some-dir", "some-symlink"),
+						filepath.Join(destination, "some-dir", "external-symlink"),
+						filepath.Join(destination, "external-dir", "some-file"),
+					} {
+						info, err := os.Lstat(path)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(info.Mode().IsRegular()).To(BeTrue(), path)
+
+						content, err := os.ReadFile(path)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(string(content)).To(Equal("some-content"))
+					}
+
+					Expect(filepath.Join(destination, "external-dir")).To(BeADirectory())
+				})
+
+				context("when a symlink refers to one of its parent directories", func() {
+					it.Before(func() {
+						Expect(os.Symlink("..", filepath.Join(source, "some-dir", "parent"))).To(Succeed())
+					})
+
+					it("returns an error", func() {
+						err := fs.CopyWithOptions(source, destination, fs.CopyOptions{FollowSymlinks: true})
+						Expect(err).To(MatchError(ContainSubstring("failed to follow symlink")))
+						Expect(err).To(MatchError(ContainSubstring("it refers to one of its parent directories")))
+					})
+				})
+
+				context("when symlinks in sibling directories refer to each other", func() {
+					it.Before(func() {
+						Expect(os.MkdirAll(filepath.Join(source, "a"), os.ModePerm)).To(Succeed())
+						Expect(os.MkdirAll(filepath.Join(source, "b"), os.ModePerm)).To(Succeed())
+						Expect(os.Symlink(filepath.Join("..", "b"), filepath.Join(source, "a", "link"))).To(Succeed())
+						Expect(os.Symlink(filepath.Join("..", "a"), filepath.Join(source, "b", "link"))).To(Succeed())
+					})
+
+					it("returns an error", func() {
+						err := fs.CopyWithOptions(source, destination, fs.CopyOptions{FollowSymlinks: true})
+						Expect(err).To(MatchError(ContainSubstring("failed to follow symlink")))
+						Expect(err).To(MatchError(ContainSubstring("it refers to one of its parent directories")))
+					})
+				})
+
+				context("when a symlink cannot be resolved", func() {
+					it.Before(func() {
+						Expect(os.Symlink("no-such-file", filepath.Join(source, "some-dir", "dangling"))).To(Succeed())
+					})
+
+					it("returns an error", func() {
+						err := fs.CopyWithOptions(source, destination, fs.CopyOptions{FollowSymlinks: true})
+						Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+					})
+				})
+			})
+
 			context("failure cases", func() {
 				context("when the source does not exist", func() {
 					it("returns an error", func() {
@@ -14,5 +14,6 @@ func TestUnitFS(t *testing.T) {
 	suite("Exists", testExists)
 	suite("IsEmptyDir", testIsEmptyDir)
 	suite("Move", testMove)
+	suite("Snapshot", testSnapshot)
 	suite.Run(t)
 }
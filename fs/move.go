@@ -1,19 +1,108 @@
 package fs
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"syscall"
 )
 
+// A ConflictPolicy decides what happens when a file being merged into a
+// directory by MoveWithOptions already exists in that directory.
+type ConflictPolicy uint8
+
+const (
+	// ConflictOverwrite replaces the existing file with the one being moved.
+	ConflictOverwrite ConflictPolicy = iota
+
+	// ConflictSkip keeps the existing file and discards the one being moved.
+	ConflictSkip
+
+	// ConflictError fails the move before anything has been moved.
+	ConflictError
+)
+
+// MoveOptions controls how files are moved by MoveWithOptions. The zero value
+// moves files in the same way as Move.
+type MoveOptions struct {
+	// Merge moves the contents of a source directory into an existing
+	// destination directory rather than replacing it. Directories that exist in
+	// both are merged in turn, and any other file that exists in both is
+	// resolved using Conflict.
+	Merge bool
+
+	// Conflict decides what happens to files that exist in both the source and
+	// the destination when merging.
+	Conflict ConflictPolicy
+}
+
 // Move will move a source file or directory to a destination. For directories,
 // move will remap relative symlinks ensuring that they align with the
 // destination directory. If the destination exists prior to invocation, it
 // will be removed. Additionally, the source will be removed once it has been
 // copied to the destination.
+//
+// The source is renamed when it is on the same filesystem as the destination,
+// and is otherwise copied to the destination and then removed.
 func Move(source, destination string) error {
-	err := Copy(source, destination)
+	return MoveWithOptions(source, destination, MoveOptions{})
+}
+
+// MoveWithOptions moves a source file or directory to a destination in the
+// same way as Move, using the given options.
+func MoveWithOptions(source, destination string, options MoveOptions) error {
+	if options.Merge {
+		if options.Conflict == ConflictError {
+			err := checkConflicts(source, destination)
+			if err != nil {
+				return fmt.Errorf("failed to move: %w", err)
+			}
+		}
+
+		err := merge(source, destination, options.Conflict)
+		if err != nil {
+			return fmt.Errorf("failed to move: %w", err)
+		}
+
+		return nil
+	}
+
+	_, err := os.Lstat(source)
 	if err != nil {
-		return fmt.Errorf("failed to move: %s", err)
+		return fmt.Errorf("failed to move: %w", err)
+	}
+
+	err = os.Remove(destination)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to move: failed to copy: destination exists: %w", err)
+		}
+	}
+
+	err = rename(source, destination)
+	if err != nil {
+		return fmt.Errorf("failed to move: %w", err)
+	}
+
+	return nil
+}
+
+// rename renames source to destination, falling back to copying source and
+// then removing it when they are on different filesystems.
+func rename(source, destination string) error {
+	err := os.Rename(source, destination)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	err = Copy(source, destination)
+	if err != nil {
+		return err
 	}
 
 	err = os.RemoveAll(source)
@@ -23,3 +112,89 @@ func Move(source, destination string) error {
 
 	return nil
 }
+
+// merge moves source to destination, merging it into destination when both
+// are directories.
+func merge(source, destination string, conflict ConflictPolicy) error {
+	sourceInfo, err := os.Lstat(source)
+	if err != nil {
+		return err
+	}
+
+	destinationInfo, err := os.Lstat(destination)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		return rename(source, destination)
+	}
+
+	if sourceInfo.IsDir() && destinationInfo.IsDir() {
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			err = merge(filepath.Join(source, entry.Name()), filepath.Join(destination, entry.Name()), conflict)
+			if err != nil {
+				return err
+			}
+		}
+
+		return os.Remove(source)
+	}
+
+	switch conflict {
+	case ConflictSkip:
+		return os.RemoveAll(source)
+
+	case ConflictError:
+		return fmt.Errorf("destination %s already exists", destination)
+
+	default:
+		err = os.RemoveAll(destination)
+		if err != nil {
+			return err
+		}
+
+		return rename(source, destination)
+	}
+}
+
+// checkConflicts returns an error for the first file that exists in both
+// source and destination, other than directories that would be merged.
+func checkConflicts(source, destination string) error {
+	sourceInfo, err := os.Lstat(source)
+	if err != nil {
+		return err
+	}
+
+	destinationInfo, err := os.Lstat(destination)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if !sourceInfo.IsDir() || !destinationInfo.IsDir() {
+		return fmt.Errorf("destination %s already exists", destination)
+	}
+
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = checkConflicts(filepath.Join(source, entry.Name()), filepath.Join(destination, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package fs_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -60,14 +61,106 @@ func testMove(t *testing.T, context spec.G, it spec.S) {
 				Expect(source).NotTo(BeAnExistingFile())
 			})
 
+			it("renames the source file rather than copying it", func() {
+				sourceInfo, err := os.Stat(source)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = fs.Move(source, destination)
+				Expect(err).NotTo(HaveOccurred())
+
+				destinationInfo, err := os.Stat(destination)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.SameFile(sourceInfo, destinationInfo)).To(BeTrue())
+			})
+
+			context("when the destination is on another filesystem", func() {
+				var otherDir string
+
+				it.Before(func() {
+					var err error
+					otherDir, err = os.MkdirTemp("/dev/shm", "destination")
+					if err != nil {
+						t.Skip("/dev/shm is not available")
+					}
+
+					otherInfo, err := os.Stat(otherDir)
+					Expect(err).NotTo(HaveOccurred())
+
+					sourceInfo, err := os.Stat(sourceDir)
+					Expect(err).NotTo(HaveOccurred())
+
+					otherDevice, ok := fileDevice(otherInfo)
+					if !ok {
+						t.Skip("devices are not available on this platform")
+					}
+
+					sourceDevice, _ := fileDevice(sourceInfo)
+					if otherDevice == sourceDevice {
+						t.Skip("/dev/shm is on the same filesystem as the source")
+					}
+
+					destination = filepath.Join(otherDir, "destination")
+				})
+
+				it.After(func() {
+					Expect(os.RemoveAll(otherDir)).To(Succeed())
+				})
+
+				it("copies the source file to the destination and removes it", func() {
+					err := fs.Move(source, destination)
+					Expect(err).NotTo(HaveOccurred())
+
+					content, err := os.ReadFile(destination)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("some-content"))
+
+					Expect(source).NotTo(BeAnExistingFile())
+				})
+
+				context("failure cases", func() {
+					context("when the source cannot be read", func() {
+						it.Before(func() {
+							Expect(os.Chmod(source, 0000)).To(Succeed())
+						})
+
+						it("returns an error", func() {
+							err := fs.Move(source, destination)
+							Expect(err).To(MatchError(ContainSubstring("permission denied")))
+						})
+					})
+				})
+			})
+
+			context("when the source cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Chmod(source, 0000)).To(Succeed())
+				})
+
+				it("moves the source file to the destination", func() {
+					err := fs.Move(source, destination)
+					Expect(err).NotTo(HaveOccurred())
+
+					info, err := os.Stat(destination)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(info.Mode()).To(Equal(os.FileMode(0000)))
+
+					Expect(source).NotTo(BeAnExistingFile())
+				})
+			})
+
 			context("failure cases", func() {
-				context("when the source cannot be read", func() {
+				context("when the source cannot be renamed", func() {
 					it.Before(func() {
-						Expect(os.Chmod(source, 0000)).To(Succeed())
+						Expect(os.Chmod(sourceDir, 0555)).To(Succeed())
+					})
+
+					it.After(func() {
+						Expect(os.Chmod(sourceDir, 0755)).To(Succeed())
 					})
 
 					it("returns an error", func() {
 						err := fs.Move(source, destination)
+						Expect(err).To(MatchError(ContainSubstring("failed to move:")))
 						Expect(err).To(MatchError(ContainSubstring("permission denied")))
 					})
 				})
@@ -182,6 +275,81 @@ func testMove(t *testing.T, context spec.G, it spec.S) {
 				})
 			})
 
+			context("when merging into an existing destination directory", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(destination, "some-dir"), os.ModePerm)).To(Succeed())
+					Expect(os.MkdirAll(filepath.Join(destination, "other-dir"), os.ModePerm)).To(Succeed())
+
+					err := os.WriteFile(filepath.Join(destination, "some-dir", "some-file"), []byte("existing-content"), 0644)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = os.WriteFile(filepath.Join(destination, "other-dir", "other-file"), []byte("other-content"), 0644)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("overwrites the files that exist in both by default", func() {
+					err := fs.MoveWithOptions(source, destination, fs.MoveOptions{Merge: true})
+					Expect(err).NotTo(HaveOccurred())
+
+					content, err := os.ReadFile(filepath.Join(destination, "some-dir", "some-file"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("some-content"))
+
+					content, err = os.ReadFile(filepath.Join(destination, "other-dir", "other-file"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("other-content"))
+
+					Expect(filepath.Join(destination, "some-dir", "readonly-file")).To(BeAnExistingFile())
+
+					path, err := os.Readlink(filepath.Join(destination, "some-dir", "some-symlink"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path).To(Equal("some-file"))
+
+					Expect(source).NotTo(BeAnExistingFile())
+				})
+
+				context("when conflicts are skipped", func() {
+					it("keeps the files that exist in the destination", func() {
+						err := fs.MoveWithOptions(source, destination, fs.MoveOptions{Merge: true, Conflict: fs.ConflictSkip})
+						Expect(err).NotTo(HaveOccurred())
+
+						content, err := os.ReadFile(filepath.Join(destination, "some-dir", "some-file"))
+						Expect(err).NotTo(HaveOccurred())
+						Expect(string(content)).To(Equal("existing-content"))
+
+						Expect(filepath.Join(destination, "some-dir", "readonly-file")).To(BeAnExistingFile())
+						Expect(filepath.Join(destination, "other-dir", "other-file")).To(BeAnExistingFile())
+
+						Expect(source).NotTo(BeAnExistingFile())
+					})
+				})
+
+				context("when conflicts are errors", func() {
+					it("returns an error without moving anything", func() {
+						err := fs.MoveWithOptions(source, destination, fs.MoveOptions{Merge: true, Conflict: fs.ConflictError})
+						Expect(err).To(MatchError(fmt.Sprintf("failed to move: destination %s already exists", filepath.Join(destination, "some-dir", "some-file"))))
+
+						Expect(filepath.Join(destination, "some-dir", "readonly-file")).NotTo(BeAnExistingFile())
+						Expect(filepath.Join(source, "some-dir", "some-file")).To(BeAnExistingFile())
+					})
+
+					context("when there are no conflicts", func() {
+						it.Before(func() {
+							Expect(os.Remove(filepath.Join(destination, "some-dir", "some-file"))).To(Succeed())
+						})
+
+						it("merges the directories", func() {
+							err := fs.MoveWithOptions(source, destination, fs.MoveOptions{Merge: true, Conflict: fs.ConflictError})
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(filepath.Join(destination, "some-dir", "some-file")).To(BeAnExistingFile())
+							Expect(filepath.Join(destination, "other-dir", "other-file")).To(BeAnExistingFile())
+							Expect(source).NotTo(BeAnExistingFile())
+						})
+					})
+				})
+			})
+
 			context("failure cases", func() {
 				context("when the source does not exist", func() {
 					it("returns an error", func() {
@@ -204,6 +372,22 @@ func testMove(t *testing.T, context spec.G, it spec.S) {
 						Expect(err).To(MatchError(ContainSubstring("permission denied")))
 					})
 				})
+
+				context("when the source cannot be renamed", func() {
+					it.Before(func() {
+						Expect(os.Chmod(sourceDir, 0555)).To(Succeed())
+					})
+
+					it.After(func() {
+						Expect(os.Chmod(sourceDir, 0755)).To(Succeed())
+					})
+
+					it("returns an error", func() {
+						err := fs.Move(source, destination)
+						Expect(err).To(MatchError(ContainSubstring("failed to move:")))
+						Expect(err).To(MatchError(ContainSubstring("permission denied")))
+					})
+				})
 			})
 		})
 	})
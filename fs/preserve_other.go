@@ -0,0 +1,23 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package fs
+
+import (
+	"os"
+)
+
+// copyOwnership does nothing, as ownership is not preserved on this platform.
+func copyOwnership(path string, info os.FileInfo) error {
+	return nil
+}
+
+// copyModTime sets the modification time of path to the one recorded in info.
+// The modification times of symlinks are not preserved on this platform.
+func copyModTime(path string, info os.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,29 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package fs
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyOwnership sets the uid and gid of path, without following symlinks, to
+// those recorded in info.
+func copyOwnership(path string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	return os.Lchown(path, int(stat.Uid), int(stat.Gid))
+}
+
+// copyModTime sets the modification time of path, without following
+// symlinks, to the one recorded in info.
+func copyModTime(path string, info os.FileInfo) error {
+	modTime := unix.NsecToTimespec(info.ModTime().UnixNano())
+	return unix.UtimesNanoAt(unix.AT_FDCWD, path, []unix.Timespec{modTime, modTime}, unix.AT_SYMLINK_NOFOLLOW)
+}
@@ -0,0 +1,245 @@
+package fs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// A DirectorySnapshot records the state of the files within a directory at
+// the time it was taken. It can be stored in layer metadata and compared with
+// a later snapshot using Diff.
+type DirectorySnapshot struct {
+	Entries []SnapshotEntry `toml:"entries" json:"entries"`
+}
+
+// A SnapshotEntry records the state of a single file, directory or symlink.
+type SnapshotEntry struct {
+	// Path is the slash separated path of the entry relative to the directory
+	// that was snapshotted.
+	Path string `toml:"path" json:"path"`
+
+	// Mode is the mode of the entry, including its type bits.
+	Mode os.FileMode `toml:"mode" json:"mode"`
+
+	// Size is the size in bytes of a regular file.
+	Size int64 `toml:"size,omitempty" json:"size,omitempty"`
+
+	// ModTime is the modification time of the entry, truncated to the second
+	// so that it survives being written to layer metadata.
+	ModTime time.Time `toml:"mod-time" json:"mod-time"`
+
+	// Linkname is the target of a symlink.
+	Linkname string `toml:"linkname,omitempty" json:"linkname,omitempty"`
+
+	// SHA256 is the hex encoded sha256 digest of the content of a regular
+	// file. It is only recorded when requested in the SnapshotOptions.
+	SHA256 string `toml:"sha256,omitempty" json:"sha256,omitempty"`
+}
+
+// SnapshotOptions controls what is recorded by SnapshotWithOptions.
+type SnapshotOptions struct {
+	// Checksums records the sha256 digest of the content of every regular file
+	// so that Diff can tell whether a file has changed without relying on its
+	// size and modification time.
+	Checksums bool
+
+	// Excludes leaves the files matching any of the given glob patterns out of
+	// the snapshot. Patterns are matched in the same way as the excludes of a
+	// ChecksumCalculator.
+	Excludes []string
+}
+
+// Snapshot records the paths, sizes, modes and modification times of the
+// files, directories and symlinks within dir.
+func Snapshot(dir string) (DirectorySnapshot, error) {
+	return SnapshotWithOptions(dir, SnapshotOptions{})
+}
+
+// SnapshotWithOptions records the files within dir in the same way as
+// Snapshot, using the given options.
+func SnapshotWithOptions(dir string, options SnapshotOptions) (DirectorySnapshot, error) {
+	for _, pattern := range options.Excludes {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return DirectorySnapshot{}, fmt.Errorf("failed to take snapshot: invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	var snapshot DirectorySnapshot
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if rel == "." {
+			return nil
+		}
+
+		rel = filepath.ToSlash(rel)
+		if matchesAny(options.Excludes, rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		entry := SnapshotEntry{
+			Path:    rel,
+			Mode:    info.Mode(),
+			ModTime: info.ModTime().UTC().Truncate(time.Second),
+		}
+
+		switch {
+		case info.Mode().IsRegular():
+			entry.Size = info.Size()
+
+			if options.Checksums {
+				entry.SHA256, err = fileSHA256(path)
+				if err != nil {
+					return err
+				}
+			}
+
+		case info.Mode()&os.ModeSymlink != 0:
+			entry.Linkname, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		snapshot.Entries = append(snapshot.Entries, entry)
+
+		return nil
+	})
+	if err != nil {
+		return DirectorySnapshot{}, fmt.Errorf("failed to take snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// DecodeSnapshot converts a snapshot that was stored in layer metadata, and
+// has since been read back as generic TOML values, into a DirectorySnapshot.
+func DecodeSnapshot(value interface{}) (DirectorySnapshot, error) {
+	buffer := bytes.NewBuffer(nil)
+	err := toml.NewEncoder(buffer).Encode(map[string]interface{}{"snapshot": value})
+	if err != nil {
+		return DirectorySnapshot{}, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	var wrapper struct {
+		Snapshot DirectorySnapshot `toml:"snapshot"`
+	}
+	_, err = toml.Decode(buffer.String(), &wrapper)
+	if err != nil {
+		return DirectorySnapshot{}, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	return wrapper.Snapshot, nil
+}
+
+// A SnapshotDiff lists the slash separated relative paths of the entries that
+// differ between two snapshots.
+type SnapshotDiff struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// HasChanges reports whether any entries differ between the snapshots.
+func (d SnapshotDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Modified) > 0
+}
+
+// Diff compares the snapshot a with a later snapshot b. An entry is modified
+// when its mode or symlink target has changed, or for a regular file, when its
+// size or content has changed. The content of a file is compared using its
+// checksum when both snapshots recorded one, and otherwise using its
+// modification time. The modification times of directories are not compared,
+// as they change whenever their contents do.
+func Diff(a, b DirectorySnapshot) SnapshotDiff {
+	before := map[string]SnapshotEntry{}
+	for _, entry := range a.Entries {
+		before[entry.Path] = entry
+	}
+
+	var diff SnapshotDiff
+	after := map[string]bool{}
+	for _, entry := range b.Entries {
+		after[entry.Path] = true
+
+		previous, ok := before[entry.Path]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, entry.Path)
+		case modified(previous, entry):
+			diff.Modified = append(diff.Modified, entry.Path)
+		}
+	}
+
+	for _, entry := range a.Entries {
+		if !after[entry.Path] {
+			diff.Removed = append(diff.Removed, entry.Path)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+
+	return diff
+}
+
+func modified(a, b SnapshotEntry) bool {
+	if a.Mode != b.Mode || a.Linkname != b.Linkname {
+		return true
+	}
+
+	if !b.Mode.IsRegular() {
+		return false
+	}
+
+	if a.Size != b.Size {
+		return true
+	}
+
+	if a.SHA256 != "" && b.SHA256 != "" {
+		return a.SHA256 != b.SHA256
+	}
+
+	// Modification times are compared to the second, as that is all that is
+	// kept when a snapshot is stored in layer metadata.
+	return !a.ModTime.Truncate(time.Second).Equal(b.ModTime.Truncate(time.Second))
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,225 @@
+package fs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/fs"
+	"github.com/paketo-buildpacks/packit/v2/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSnapshot(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir     string
+		modTime time.Time
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "snapshot")
+		Expect(err).NotTo(HaveOccurred())
+
+		modTime = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		Expect(os.MkdirAll(filepath.Join(dir, "some-dir"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "some-dir", "some-file"), []byte("some-content"), 0644)).To(Succeed())
+		Expect(os.Symlink("some-file", filepath.Join(dir, "some-dir", "some-symlink"))).To(Succeed())
+		Expect(os.Chtimes(filepath.Join(dir, "some-dir", "some-file"), modTime, modTime)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	context("Snapshot", func() {
+		it("records the entries within the directory", func() {
+			snapshot, err := fs.Snapshot(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(snapshot.Entries).To(HaveLen(3))
+
+			Expect(snapshot.Entries[0].Path).To(Equal("some-dir"))
+			Expect(snapshot.Entries[0].Mode).To(Equal(os.ModeDir | 0755))
+
+			Expect(snapshot.Entries[1]).To(Equal(fs.SnapshotEntry{
+				Path:    "some-dir/some-file",
+				Mode:    0644,
+				Size:    12,
+				ModTime: modTime,
+			}))
+
+			Expect(snapshot.Entries[2].Path).To(Equal("some-dir/some-symlink"))
+			Expect(snapshot.Entries[2].Mode & os.ModeSymlink).NotTo(BeZero())
+			Expect(snapshot.Entries[2].Linkname).To(Equal("some-file"))
+		})
+
+		context("when checksums are requested", func() {
+			it("records the checksums of the files", func() {
+				snapshot, err := fs.SnapshotWithOptions(dir, fs.SnapshotOptions{Checksums: true})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(snapshot.Entries[1].SHA256).To(Equal("0a8cac771ca188eacc57e2c96c31f5611925c5ecedccb16b8c236d6c0d325112"))
+			})
+		})
+
+		context("when given excludes", func() {
+			it("leaves the matching entries out of the snapshot", func() {
+				snapshot, err := fs.SnapshotWithOptions(dir, fs.SnapshotOptions{Excludes: []string{"some-symlink"}})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(snapshot.Entries).To(HaveLen(2))
+				Expect(snapshot.Entries[1].Path).To(Equal("some-dir/some-file"))
+			})
+		})
+
+		context("when the snapshot is stored in layer metadata", func() {
+			var layersDir string
+
+			it.Before(func() {
+				var err error
+				layersDir, err = os.MkdirTemp("", "layers")
+				Expect(err).NotTo(HaveOccurred())
+
+				// Give the file a modification time with sub-second precision, which
+				// is not kept when the metadata is written.
+				modTime = time.Now().Add(-time.Minute)
+				Expect(os.Chtimes(filepath.Join(dir, "some-dir", "some-file"), modTime, modTime)).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(layersDir)).To(Succeed())
+			})
+
+			it("can be decoded and compared once the metadata has been read back", func() {
+				snapshot, err := fs.Snapshot(dir)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = internal.NewTOMLWriter().Write(filepath.Join(layersDir, "some-layer.toml"), map[string]interface{}{
+					"metadata": map[string]interface{}{"snapshot": snapshot},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				layer, err := packit.Layers{Path: layersDir}.Get("some-layer")
+				Expect(err).NotTo(HaveOccurred())
+
+				decoded, err := fs.DecodeSnapshot(layer.Metadata["snapshot"])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decoded).To(Equal(snapshot))
+
+				current, err := fs.Snapshot(dir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fs.Diff(decoded, current).HasChanges()).To(BeFalse())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the directory does not exist", func() {
+				it("returns an error", func() {
+					_, err := fs.Snapshot(filepath.Join(dir, "no-such-dir"))
+					Expect(err).To(MatchError(ContainSubstring("failed to take snapshot:")))
+					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				})
+			})
+
+			context("when an exclude pattern is invalid", func() {
+				it("returns an error", func() {
+					_, err := fs.SnapshotWithOptions(dir, fs.SnapshotOptions{Excludes: []string{"["}})
+					Expect(err).To(MatchError(ContainSubstring(`failed to take snapshot: invalid exclude pattern "["`)))
+				})
+			})
+
+			context("when the metadata is not a snapshot", func() {
+				it("returns an error", func() {
+					_, err := fs.DecodeSnapshot(map[string]interface{}{"entries": "some-value"})
+					Expect(err).To(MatchError(ContainSubstring("failed to decode snapshot:")))
+				})
+			})
+		})
+	})
+
+	context("Diff", func() {
+		var before fs.DirectorySnapshot
+
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(dir, "removed-file"), []byte("some-content"), 0644)).To(Succeed())
+
+			var err error
+			before, err = fs.Snapshot(dir)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("reports no changes when nothing has changed", func() {
+			after, err := fs.Snapshot(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			diff := fs.Diff(before, after)
+			Expect(diff).To(Equal(fs.SnapshotDiff{}))
+			Expect(diff.HasChanges()).To(BeFalse())
+		})
+
+		it("reports the added, removed and modified entries", func() {
+			Expect(os.WriteFile(filepath.Join(dir, "some-dir", "added-file"), []byte("some-content"), 0644)).To(Succeed())
+			Expect(os.Remove(filepath.Join(dir, "removed-file"))).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "some-dir", "some-file"), []byte("other-content"), 0644)).To(Succeed())
+			Expect(os.Remove(filepath.Join(dir, "some-dir", "some-symlink"))).To(Succeed())
+			Expect(os.Symlink("added-file", filepath.Join(dir, "some-dir", "some-symlink"))).To(Succeed())
+
+			after, err := fs.Snapshot(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			diff := fs.Diff(before, after)
+			Expect(diff).To(Equal(fs.SnapshotDiff{
+				Added:    []string{"some-dir/added-file"},
+				Removed:  []string{"removed-file"},
+				Modified: []string{"some-dir/some-file", "some-dir/some-symlink"},
+			}))
+			Expect(diff.HasChanges()).To(BeTrue())
+		})
+
+		it("reports files whose modification time has changed", func() {
+			later := modTime.Add(time.Hour)
+			Expect(os.Chtimes(filepath.Join(dir, "some-dir", "some-file"), later, later)).To(Succeed())
+
+			after, err := fs.Snapshot(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fs.Diff(before, after).Modified).To(Equal([]string{"some-dir/some-file"}))
+		})
+
+		it("reports files whose mode has changed", func() {
+			Expect(os.Chmod(filepath.Join(dir, "some-dir", "some-file"), 0755)).To(Succeed())
+
+			after, err := fs.Snapshot(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fs.Diff(before, after).Modified).To(Equal([]string{"some-dir/some-file"}))
+		})
+
+		context("when both snapshots have checksums", func() {
+			it.Before(func() {
+				var err error
+				before, err = fs.SnapshotWithOptions(dir, fs.SnapshotOptions{Checksums: true})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("compares the content of files rather than their modification times", func() {
+				later := modTime.Add(time.Hour)
+				Expect(os.Chtimes(filepath.Join(dir, "some-dir", "some-file"), later, later)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, "removed-file"), []byte("other-content"), 0644)).To(Succeed())
+
+				after, err := fs.SnapshotWithOptions(dir, fs.SnapshotOptions{Checksums: true})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fs.Diff(before, after).Modified).To(Equal([]string{"removed-file"}))
+			})
+		})
+	})
+}
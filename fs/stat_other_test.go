@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package fs_test
+
+import (
+	"os"
+)
+
+func fileOwner(info os.FileInfo) (int, bool) {
+	return 0, false
+}
+
+func fileDevice(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -1,10 +1,13 @@
 package packit
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/paketo-buildpacks/packit/v2/internal"
@@ -41,6 +44,10 @@ type GenerateContext struct {
 	// $CNB_STACK_ID environment variable.
 	Stack string
 
+	// Target is the platform that the application image is being built for.
+	// This value is populated from the $CNB_TARGET_* environment variables.
+	Target Target
+
 	// WorkingDir is the location of the application source code as provided by
 	// the lifecycle.
 	WorkingDir string
@@ -120,12 +127,24 @@ func Generate(f GenerateFunc, options ...Option) {
 		return
 	}
 
+	// An extension.toml without an api is still accepted, in which case no
+	// compatibility checks are made.
+	var api buildpackAPI
+	if info.APIVersion != "" {
+		api, err = newBuildpackAPI(info.APIVersion)
+		if err != nil {
+			config.exitHandler.Error(err)
+			return
+		}
+	}
+
 	result, err := f(GenerateContext{
 		CNBPath: cnbPath,
 		Platform: Platform{
 			Path: platformPath,
 		},
 		Stack:      os.Getenv("CNB_STACK_ID"),
+		Target:     newTarget(api),
 		WorkingDir: pwd,
 		Plan:       plan,
 		Info:       info.Info,
@@ -143,6 +162,21 @@ func Generate(f GenerateFunc, options ...Option) {
 		}
 	}
 	if result.RunDockerfile != nil {
+		if api.declared() && !api.supports(apiFeatureRunImageExtension) {
+			content, err := io.ReadAll(result.RunDockerfile)
+			if err != nil {
+				config.exitHandler.Error(err)
+				return
+			}
+
+			if extendsRunImage(content) {
+				config.exitHandler.Error(api.require(apiFeatureRunImageExtension))
+				return
+			}
+
+			result.RunDockerfile = bytes.NewReader(content)
+		}
+
 		err = config.fileWriter.Write(filepath.Join(outputPath, "run.Dockerfile"), result.RunDockerfile)
 		if err != nil {
 			config.exitHandler.Error(err)
@@ -155,5 +189,33 @@ func Generate(f GenerateFunc, options ...Option) {
 		config.exitHandler.Error(err)
 		return
 	}
+}
+
+// extendsRunImage returns true if the given run.Dockerfile contains
+// instructions other than ARG and FROM, meaning that it extends the run image
+// rather than only switching it.
+func extendsRunImage(dockerfile []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(dockerfile))
+
+	var continued bool
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		isContinuation := continued
+		continued = strings.HasSuffix(line, "\\")
+		if isContinuation {
+			continue
+		}
+
+		switch strings.ToUpper(strings.Fields(line)[0]) {
+		case "ARG", "FROM":
+		default:
+			return true
+		}
+	}
 
+	return false
 }
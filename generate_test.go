@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/paketo-buildpacks/packit/v2"
@@ -146,7 +147,141 @@ api = "0.9"
 		}))
 	})
 
+	context("when the api version is 0.10 or higher", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(cnbDir, "extension.toml"), []byte(`
+api = "0.10"
+[extension]
+  id = "some-id"
+`), 0600)).To(Succeed())
+
+			Expect(os.Setenv("CNB_TARGET_OS", "linux")).To(Succeed())
+			Expect(os.Setenv("CNB_TARGET_ARCH", "arm64")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("CNB_TARGET_OS")).To(Succeed())
+			Expect(os.Unsetenv("CNB_TARGET_ARCH")).To(Succeed())
+		})
+
+		it("provides the target in the generate context", func() {
+			var context packit.GenerateContext
+			packit.Generate(func(ctx packit.GenerateContext) (packit.GenerateResult, error) {
+				context = ctx
+
+				return packit.GenerateResult{}, nil
+			}, packit.WithArgs([]string{binaryPath}), packit.WithExitHandler(exitHandler))
+
+			Expect(exitHandler.ErrorCall.CallCount).To(Equal(0))
+			Expect(context.Target).To(Equal(packit.Target{
+				OS:   "linux",
+				Arch: "arm64",
+			}))
+		})
+	})
+
+	context("when the result includes a run.Dockerfile that switches the run image", func() {
+		it("writes the run.Dockerfile", func() {
+			packit.Generate(func(ctx packit.GenerateContext) (packit.GenerateResult, error) {
+				return packit.GenerateResult{
+					RunDockerfile: strings.NewReader("ARG base_image\nFROM some-run-image\n"),
+				}, nil
+			}, packit.WithArgs([]string{binaryPath}), packit.WithExitHandler(exitHandler))
+
+			Expect(exitHandler.ErrorCall.CallCount).To(Equal(0))
+
+			content, err := os.ReadFile(filepath.Join(tmpDir, "run.Dockerfile"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("ARG base_image\nFROM some-run-image\n"))
+		})
+	})
+
+	context("when the result includes a run.Dockerfile that extends the run image", func() {
+		context("when the api version is 0.10 or higher", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "extension.toml"), []byte(`
+api = "0.10"
+[extension]
+  id = "some-id"
+`), 0600)).To(Succeed())
+			})
+
+			it("writes the run.Dockerfile", func() {
+				packit.Generate(func(ctx packit.GenerateContext) (packit.GenerateResult, error) {
+					return packit.GenerateResult{
+						RunDockerfile: strings.NewReader("FROM some-run-image\nRUN some-command\n"),
+					}, nil
+				}, packit.WithArgs([]string{binaryPath}), packit.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.ErrorCall.CallCount).To(Equal(0))
+
+				content, err := os.ReadFile(filepath.Join(tmpDir, "run.Dockerfile"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("FROM some-run-image\nRUN some-command\n"))
+			})
+		})
+
+		context("when the extension.toml does not declare an api version", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "extension.toml"), []byte(`
+[extension]
+  id = "some-id"
+`), 0600)).To(Succeed())
+			})
+
+			it("writes the run.Dockerfile", func() {
+				packit.Generate(func(ctx packit.GenerateContext) (packit.GenerateResult, error) {
+					return packit.GenerateResult{
+						RunDockerfile: strings.NewReader("FROM some-run-image\nRUN some-command\n"),
+					}, nil
+				}, packit.WithArgs([]string{binaryPath}), packit.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.ErrorCall.CallCount).To(Equal(0))
+
+				content, err := os.ReadFile(filepath.Join(tmpDir, "run.Dockerfile"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("FROM some-run-image\nRUN some-command\n"))
+			})
+		})
+
+		context("when the api version is less than 0.10", func() {
+			it.Before(func() {
+				exitHandler.ErrorCall.Stub = nil
+			})
+
+			it("calls the exit handler", func() {
+				packit.Generate(func(ctx packit.GenerateContext) (packit.GenerateResult, error) {
+					return packit.GenerateResult{
+						RunDockerfile: strings.NewReader("# switch the run image\nFROM some-run-image\nRUN some-command \\\n  --with-flag\n"),
+					}, nil
+				}, packit.WithArgs([]string{binaryPath}), packit.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.ErrorCall.Receives.Error).To(MatchError("run.Dockerfile instructions other than ARG and FROM are only supported with Buildpack API v0.10 or higher"))
+				Expect(filepath.Join(tmpDir, "run.Dockerfile")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
 	context("failure cases", func() {
+		context("when the extension.toml api version is invalid", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "extension.toml"), []byte(`
+api = "not-a-version"
+[extension]
+  id = "some-id"
+`), 0600)).To(Succeed())
+				exitHandler.ErrorCall.Stub = nil
+			})
+
+			it("calls the exit handler", func() {
+				packit.Generate(func(ctx packit.GenerateContext) (packit.GenerateResult, error) {
+					return packit.GenerateResult{}, nil
+				}, packit.WithArgs([]string{binaryPath}), packit.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.ErrorCall.Receives.Error).To(MatchError(ContainSubstring(`failed to parse buildpack API version "not-a-version"`)))
+			})
+		})
+
 		context("when the buildpack plan.toml is malformed", func() {
 			it.Before(func() {
 				err := os.WriteFile(planPath, []byte("%%%"), 0600)
@@ -2,18 +2,28 @@ package internal
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 )
 
-type EnvironmentWriter struct{}
+type EnvironmentWriter struct {
+	transaction *Transaction
+}
 
 func NewEnvironmentWriter() EnvironmentWriter {
 	return EnvironmentWriter{}
 }
 
+// WithTransaction returns an EnvironmentWriter that stages its files in the
+// given transaction rather than renaming them into place as they are written.
+func (w EnvironmentWriter) WithTransaction(transaction *Transaction) EnvironmentWriter {
+	w.transaction = transaction
+	return w
+}
+
 func (w EnvironmentWriter) Write(dir string, env map[string]string) error {
 	if len(env) == 0 {
 		return nil
@@ -33,7 +43,12 @@ func (w EnvironmentWriter) Write(dir string, env map[string]string) error {
 		if !validEnvVarRegex.MatchString(parts[0]) {
 			return fmt.Errorf("invalid environment variable name '%s'", parts[0])
 		}
-		err := os.WriteFile(filepath.Join(dir, key), []byte(value), 0644)
+
+		value := value
+		err := writeFile(filepath.Join(dir, key), w.transaction, func(writer io.Writer) error {
+			_, err := io.WriteString(writer, value)
+			return err
+		})
 		if err != nil {
 			return err
 		}
@@ -2,26 +2,26 @@ package internal
 
 import (
 	"io"
-	"os"
 )
 
-type FileWriter struct{}
+type FileWriter struct {
+	transaction *Transaction
+}
 
 func NewFileWriter() FileWriter {
 	return FileWriter{}
 }
 
-func (fw FileWriter) Write(path string, reader io.Reader) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// WithTransaction returns a FileWriter that stages its files in the given
+// transaction rather than renaming them into place as they are written.
+func (fw FileWriter) WithTransaction(transaction *Transaction) FileWriter {
+	fw.transaction = transaction
+	return fw
+}
 
-	_, err = io.Copy(file, reader)
-	if err != nil {
+func (fw FileWriter) Write(path string, reader io.Reader) error {
+	return writeFile(path, fw.transaction, func(w io.Writer) error {
+		_, err := io.Copy(w, reader)
 		return err
-	}
-
-	return nil
+	})
 }
@@ -40,10 +40,58 @@ func testFileWriter(t *testing.T, context spec.G, it spec.S) {
 		Expect(string(contents)).To(Equal("some-file-contents"))
 	})
 
+	context("when the file already exists", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(path, []byte("some-previous-contents"), 0000)).To(Succeed())
+		})
+
+		it("replaces it without leaving any temporary files behind", func() {
+			err := fileWriter.Write(path, strings.NewReader("some-file-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-file-contents"))
+
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0644)))
+
+			files, err := filepath.Glob(filepath.Join(tmpDir, "*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf(path))
+		})
+	})
+
+	context("when given a transaction", func() {
+		var transaction *internal.Transaction
+
+		it.Before(func() {
+			transaction = internal.NewTransaction()
+			fileWriter = fileWriter.WithTransaction(transaction)
+		})
+
+		it("writes the file when the transaction is committed", func() {
+			err := fileWriter.Write(path, strings.NewReader("some-file-contents"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).NotTo(BeAnExistingFile())
+
+			Expect(transaction.Commit()).To(Succeed())
+
+			contents, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-file-contents"))
+		})
+	})
+
 	context("failure cases", func() {
 		context("when the file path cannot be created", func() {
 			it.Before(func() {
-				Expect(os.WriteFile(path, nil, 0000)).To(Succeed())
+				Expect(os.Chmod(tmpDir, 0500)).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Chmod(tmpDir, os.ModePerm)).To(Succeed())
 			})
 
 			it("returns an error", func() {
@@ -53,9 +101,21 @@ func testFileWriter(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		context("when the reader throws an error", func() {
-			it("returns an error", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("some-previous-contents"), 0644)).To(Succeed())
+			})
+
+			it("returns an error and leaves the existing file in place", func() {
 				err := fileWriter.Write(path, iotest.ErrReader(errors.New("failed to read")))
 				Expect(err).To(MatchError("failed to read"))
+
+				contents, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("some-previous-contents"))
+
+				files, err := filepath.Glob(filepath.Join(tmpDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf(path))
 			})
 		})
 	})
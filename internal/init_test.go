@@ -14,5 +14,6 @@ func TestUnitInternal(t *testing.T) {
 	suite("Fail", testFail)
 	suite("FileWriter", testFileWriter)
 	suite("TOMLWriter", testTOMLWriter)
+	suite("Transaction", testTransaction)
 	suite.Run(t)
 }
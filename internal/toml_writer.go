@@ -1,23 +1,28 @@
 package internal
 
 import (
-	"os"
+	"io"
 
 	"github.com/pelletier/go-toml"
 )
 
-type TOMLWriter struct{}
+type TOMLWriter struct {
+	transaction *Transaction
+}
 
 func NewTOMLWriter() TOMLWriter {
 	return TOMLWriter{}
 }
 
-func (tw TOMLWriter) Write(path string, value interface{}) error {
-	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// WithTransaction returns a TOMLWriter that stages its files in the given
+// transaction rather than renaming them into place as they are written.
+func (tw TOMLWriter) WithTransaction(transaction *Transaction) TOMLWriter {
+	tw.transaction = transaction
+	return tw
+}
 
-	return toml.NewEncoder(file).Encode(value)
+func (tw TOMLWriter) Write(path string, value interface{}) error {
+	return writeFile(path, tw.transaction, func(w io.Writer) error {
+		return toml.NewEncoder(w).Encode(value)
+	})
 }
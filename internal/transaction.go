@@ -0,0 +1,241 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// A Transaction collects files that have been staged by the writers it was
+// given to, along with files that have been staged for removal, and applies
+// them together when it is committed. Until then, the files that they replace
+// are left untouched, so a build that fails or is killed part way through
+// writing its outputs does not leave a mix of old and new files behind.
+type Transaction struct {
+	mutex  sync.Mutex
+	staged []stagedFile
+}
+
+// A stagedFile is a temporary file that will be renamed to path, or when temp
+// is empty, the removal of path.
+type stagedFile struct {
+	temp string
+	path string
+}
+
+// An appliedFile is a staged file that has been committed. The file that it
+// replaced or removed, if any, is kept at backup until the commit completes.
+type appliedFile struct {
+	stagedFile
+	backup string
+}
+
+func NewTransaction() *Transaction {
+	return &Transaction{}
+}
+
+func (t *Transaction) add(temp, path string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.staged = append(t.staged, stagedFile{temp: temp, path: path})
+}
+
+// Remove stages the removal of the file at path. It is removed when the
+// transaction is committed, in order with the files that are staged around
+// it.
+func (t *Transaction) Remove(path string) {
+	t.add("", path)
+}
+
+// Commit renames the staged files into place and removes the files staged
+// for removal, in the order they were staged. The files that are replaced or
+// removed are first moved aside, so that if any step fails, the steps that
+// have already been applied are undone and the files are left as they were
+// before the commit.
+func (t *Transaction) Commit() error {
+	t.mutex.Lock()
+	staged := t.staged
+	t.staged = nil
+	t.mutex.Unlock()
+
+	var applied []appliedFile
+	for i, file := range staged {
+		backup, err := apply(file)
+		if err != nil {
+			for _, remaining := range staged[i:] {
+				if remaining.temp != "" {
+					_ = os.Remove(remaining.temp)
+				}
+			}
+
+			for j := len(applied) - 1; j >= 0; j-- {
+				undo(applied[j])
+			}
+
+			if file.temp == "" {
+				return fmt.Errorf("failed to remove %s: %w", file.path, err)
+			}
+
+			return fmt.Errorf("failed to commit %s: %w", file.path, err)
+		}
+
+		applied = append(applied, appliedFile{stagedFile: file, backup: backup})
+	}
+
+	synced := map[string]bool{}
+	for _, file := range applied {
+		if file.backup != "" {
+			_ = os.Remove(file.backup)
+		}
+
+		dir := filepath.Dir(file.path)
+		if !synced[dir] {
+			syncDir(dir)
+			synced[dir] = true
+		}
+	}
+
+	return nil
+}
+
+// Rollback removes any files that were staged but not committed.
+func (t *Transaction) Rollback() {
+	t.mutex.Lock()
+	staged := t.staged
+	t.staged = nil
+	t.mutex.Unlock()
+
+	for _, file := range staged {
+		if file.temp != "" {
+			_ = os.Remove(file.temp)
+		}
+	}
+}
+
+// apply moves any existing file at the staged path aside and then renames the
+// staged file into its place. It returns the path the existing file was moved
+// to, or an empty string if there was none.
+func apply(file stagedFile) (string, error) {
+	backup, err := moveAside(file.path)
+	if err != nil {
+		return "", err
+	}
+
+	if file.temp == "" {
+		return backup, nil
+	}
+
+	err = os.Rename(file.temp, file.path)
+	if err != nil {
+		if backup != "" {
+			_ = os.Rename(backup, file.path)
+		}
+
+		return "", err
+	}
+
+	return backup, nil
+}
+
+// undo reverts a staged file that has been applied, restoring the file it
+// replaced or removed.
+func undo(file appliedFile) {
+	if file.temp != "" {
+		_ = os.Remove(file.path)
+	}
+
+	if file.backup != "" {
+		_ = os.Rename(file.backup, file.path)
+	}
+}
+
+// moveAside renames the file at path to a new hidden file in the same
+// directory and returns its name. It returns an empty string if there is no
+// file at path.
+func moveAside(path string) (string, error) {
+	_, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	backup, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s.*.bak", filepath.Base(path)))
+	if err != nil {
+		return "", err
+	}
+
+	err = backup.Close()
+	if err == nil {
+		err = os.Rename(path, backup.Name())
+	}
+
+	if err != nil {
+		_ = os.Remove(backup.Name())
+		return "", err
+	}
+
+	return backup.Name(), nil
+}
+
+// writeFile writes the content produced by write to path atomically. The
+// content is written to a temporary file in the same directory, synced to
+// disk and then renamed over path. When transaction is not nil, the rename is
+// left to the transaction.
+func writeFile(path string, transaction *Transaction, write func(io.Writer) error) error {
+	file, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s.*.tmp", filepath.Base(path)))
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	if transaction != nil {
+		transaction.add(file.Name(), path)
+		return nil
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	syncDir(filepath.Dir(path))
+
+	return nil
+}
+
+// syncDir syncs a directory so that the renames within it are persisted. Not
+// all filesystems support syncing directories, so failures are ignored.
+func syncDir(path string) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer dir.Close()
+
+	_ = dir.Sync()
+}
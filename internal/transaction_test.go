@@ -0,0 +1,168 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testTransaction(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		tmpDir      string
+		transaction *internal.Transaction
+		tomlWriter  internal.TOMLWriter
+		fileWriter  internal.FileWriter
+		envWriter   internal.EnvironmentWriter
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "transaction")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(tmpDir, "layer.toml"), []byte("some-field = \"previous-value\"\n"), 0644)).To(Succeed())
+
+		transaction = internal.NewTransaction()
+		tomlWriter = internal.NewTOMLWriter().WithTransaction(transaction)
+		fileWriter = internal.NewFileWriter().WithTransaction(transaction)
+		envWriter = internal.NewEnvironmentWriter().WithTransaction(transaction)
+
+		Expect(tomlWriter.Write(filepath.Join(tmpDir, "layer.toml"), map[string]string{"some-field": "some-value"})).To(Succeed())
+		Expect(fileWriter.Write(filepath.Join(tmpDir, "layer.sbom.cdx.json"), strings.NewReader("some-sbom"))).To(Succeed())
+		Expect(envWriter.Write(filepath.Join(tmpDir, "env"), map[string]string{"SOME_NAME": "some-value"})).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	it("leaves the existing files in place until it is committed", func() {
+		content, err := os.ReadFile(filepath.Join(tmpDir, "layer.toml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal("some-field = \"previous-value\"\n"))
+
+		Expect(filepath.Join(tmpDir, "layer.sbom.cdx.json")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tmpDir, "env", "SOME_NAME")).NotTo(BeAnExistingFile())
+	})
+
+	context("Commit", func() {
+		it("renames all of the staged files into place", func() {
+			Expect(transaction.Commit()).To(Succeed())
+
+			content, err := os.ReadFile(filepath.Join(tmpDir, "layer.toml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`some-field = "some-value"`))
+
+			content, err = os.ReadFile(filepath.Join(tmpDir, "layer.sbom.cdx.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("some-sbom"))
+
+			content, err = os.ReadFile(filepath.Join(tmpDir, "env", "SOME_NAME"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("some-value"))
+
+			files, err := filepath.Glob(filepath.Join(tmpDir, ".*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(BeEmpty())
+		})
+
+		context("failure cases", func() {
+			context("when a staged file cannot be renamed into place", func() {
+				it.Before(func() {
+					Expect(os.Mkdir(filepath.Join(tmpDir, "layer.sbom.cdx.json"), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(tmpDir, "layer.sbom.cdx.json", "some-file"), nil, 0644)).To(Succeed())
+				})
+
+				it("returns an error, restores the files already committed and removes the remaining staged files", func() {
+					err := transaction.Commit()
+					Expect(err).To(MatchError(ContainSubstring("failed to commit")))
+					Expect(err).To(MatchError(ContainSubstring("layer.sbom.cdx.json")))
+
+					content, err := os.ReadFile(filepath.Join(tmpDir, "layer.toml"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("some-field = \"previous-value\"\n"))
+
+					files, err := filepath.Glob(filepath.Join(tmpDir, ".*"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(BeEmpty())
+
+					files, err = filepath.Glob(filepath.Join(tmpDir, "env", ".*"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(BeEmpty())
+				})
+			})
+		})
+	})
+
+	context("Remove", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(tmpDir, "obsolete.toml"), nil, 0644)).To(Succeed())
+
+			transaction.Remove(filepath.Join(tmpDir, "obsolete.toml"))
+			transaction.Remove(filepath.Join(tmpDir, "missing.toml"))
+		})
+
+		it("removes the file when the transaction is committed", func() {
+			Expect(filepath.Join(tmpDir, "obsolete.toml")).To(BeARegularFile())
+
+			Expect(transaction.Commit()).To(Succeed())
+			Expect(filepath.Join(tmpDir, "obsolete.toml")).NotTo(BeAnExistingFile())
+
+			files, err := filepath.Glob(filepath.Join(tmpDir, ".*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(BeEmpty())
+		})
+
+		it("leaves the file in place when the transaction is rolled back", func() {
+			transaction.Rollback()
+
+			Expect(transaction.Commit()).To(Succeed())
+			Expect(filepath.Join(tmpDir, "obsolete.toml")).To(BeARegularFile())
+		})
+
+		context("when a later staged file cannot be renamed into place", func() {
+			it.Before(func() {
+				Expect(fileWriter.Write(filepath.Join(tmpDir, "some-dir"), strings.NewReader("some-content"))).To(Succeed())
+
+				Expect(os.Mkdir(filepath.Join(tmpDir, "some-dir"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tmpDir, "some-dir", "some-file"), nil, 0644)).To(Succeed())
+			})
+
+			it("restores the removed file", func() {
+				err := transaction.Commit()
+				Expect(err).To(MatchError(ContainSubstring("failed to commit")))
+
+				Expect(filepath.Join(tmpDir, "obsolete.toml")).To(BeARegularFile())
+
+				files, err := filepath.Glob(filepath.Join(tmpDir, ".*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(BeEmpty())
+			})
+		})
+	})
+
+	context("Rollback", func() {
+		it("removes the staged files and leaves the existing files in place", func() {
+			transaction.Rollback()
+
+			content, err := os.ReadFile(filepath.Join(tmpDir, "layer.toml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("some-field = \"previous-value\"\n"))
+
+			files, err := filepath.Glob(filepath.Join(tmpDir, ".*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(BeEmpty())
+
+			Expect(transaction.Commit()).To(Succeed())
+			Expect(filepath.Join(tmpDir, "layer.sbom.cdx.json")).NotTo(BeAnExistingFile())
+		})
+	})
+}
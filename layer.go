@@ -1,10 +1,11 @@
 package packit
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 
-	"github.com/Masterminds/semver/v3"
+	burntsushi "github.com/BurntSushi/toml"
 	"github.com/pelletier/go-toml"
 )
 
@@ -116,9 +117,29 @@ func (l Layer) Reset() (Layer, error) {
 	return l, nil
 }
 
+// DecodeMetadata decodes the layer metadata into the value pointed to by v.
+// The value is decoded using the same TOML rules that apply when the metadata
+// is read from disk by Layers.Get, so struct fields can be annotated with
+// `toml` tags. An error is returned if the shape of the metadata does not
+// match the given value.
+func (l Layer) DecodeMetadata(v interface{}) error {
+	buffer := bytes.NewBuffer(nil)
+	err := burntsushi.NewEncoder(buffer).Encode(l.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode layer metadata: %s", err)
+	}
+
+	_, err = burntsushi.Decode(buffer.String(), v)
+	if err != nil {
+		return fmt.Errorf("failed to decode layer metadata: %s", err)
+	}
+
+	return nil
+}
+
 type formattedLayer struct {
 	layer Layer
-	api   *semver.Version
+	api   buildpackAPI
 }
 
 func (l formattedLayer) MarshalTOML() ([]byte, error) {
@@ -126,8 +147,7 @@ func (l formattedLayer) MarshalTOML() ([]byte, error) {
 		"metadata": l.layer.Metadata,
 	}
 
-	apiV06, _ := semver.NewVersion("0.6")
-	if l.api.LessThan(apiV06) {
+	if !l.api.supports(apiFeatureLayerTypes) {
 		layer["build"] = l.layer.Build
 		layer["launch"] = l.layer.Launch
 		layer["cache"] = l.layer.Cache
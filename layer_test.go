@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/sclevine/spec"
@@ -162,4 +163,70 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 	})
+
+	context("DecodeMetadata", func() {
+		var layers packit.Layers
+
+		it.Before(func() {
+			layers = packit.Layers{Path: layersDir}
+
+			err := os.WriteFile(filepath.Join(layersDir, "some-layer.toml"), []byte(`
+[metadata]
+sha = "some-sha"
+built_at = 2024-01-02T03:04:05Z
+
+[metadata.nested]
+count = 3
+values = ["some-value", "other-value"]`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("decodes the layer metadata into the given value", func() {
+			layer, err := layers.Get("some-layer")
+			Expect(err).NotTo(HaveOccurred())
+
+			var metadata struct {
+				SHA     string    `toml:"sha"`
+				BuiltAt time.Time `toml:"built_at"`
+				Nested  struct {
+					Count  int      `toml:"count"`
+					Values []string `toml:"values"`
+				} `toml:"nested"`
+			}
+			Expect(layer.DecodeMetadata(&metadata)).To(Succeed())
+
+			Expect(metadata.SHA).To(Equal("some-sha"))
+			Expect(metadata.BuiltAt).To(Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+			Expect(metadata.Nested.Count).To(Equal(3))
+			Expect(metadata.Nested.Values).To(Equal([]string{"some-value", "other-value"}))
+		})
+
+		context("when the layer has no metadata", func() {
+			it("leaves the given value unmodified", func() {
+				layer, err := layers.Get("other-layer")
+				Expect(err).NotTo(HaveOccurred())
+
+				metadata := struct {
+					SHA string `toml:"sha"`
+				}{SHA: "some-default"}
+				Expect(layer.DecodeMetadata(&metadata)).To(Succeed())
+				Expect(metadata.SHA).To(Equal("some-default"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the metadata does not match the shape of the given value", func() {
+				it("returns an error", func() {
+					layer, err := layers.Get("some-layer")
+					Expect(err).NotTo(HaveOccurred())
+
+					var metadata struct {
+						SHA int `toml:"sha"`
+					}
+					err = layer.DecodeMetadata(&metadata)
+					Expect(err).To(MatchError(ContainSubstring("failed to decode layer metadata:")))
+				})
+			})
+		})
+	})
 }
@@ -68,3 +68,110 @@ func (l Layers) Get(name string) (Layer, error) {
 
 	return layer, nil
 }
+
+// ReadLaunchMetadata returns the launch metadata persisted in the launch.toml
+// file of the layers directory by a previous build. If no launch.toml exists,
+// an empty LaunchMetadata is returned. Processes that declare their command
+// as a list are returned as DirectProcesses.
+func (l Layers) ReadLaunchMetadata() (LaunchMetadata, error) {
+	var launchTOML struct {
+		Processes []toml.Primitive `toml:"processes"`
+		Slices    []Slice          `toml:"slices"`
+		Labels    []struct {
+			Key   string `toml:"key"`
+			Value string `toml:"value"`
+		} `toml:"labels"`
+		BOM []BOMEntry `toml:"bom"`
+	}
+
+	metadata, err := toml.DecodeFile(filepath.Join(l.Path, "launch.toml"), &launchTOML)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return LaunchMetadata{}, nil
+		}
+
+		return LaunchMetadata{}, fmt.Errorf("failed to parse launch metadata: %s", err)
+	}
+
+	launch := LaunchMetadata{
+		Slices: launchTOML.Slices,
+		BOM:    launchTOML.BOM,
+	}
+
+	for _, primitive := range launchTOML.Processes {
+		var command struct {
+			Command interface{} `toml:"command"`
+		}
+
+		err = metadata.PrimitiveDecode(primitive, &command)
+		if err != nil {
+			return LaunchMetadata{}, fmt.Errorf("failed to parse launch metadata: %s", err)
+		}
+
+		if _, ok := command.Command.([]interface{}); ok {
+			var process DirectProcess
+			err = metadata.PrimitiveDecode(primitive, &process)
+			launch.DirectProcesses = append(launch.DirectProcesses, process)
+		} else {
+			var process Process
+			err = metadata.PrimitiveDecode(primitive, &process)
+			launch.Processes = append(launch.Processes, process)
+		}
+		if err != nil {
+			return LaunchMetadata{}, fmt.Errorf("failed to parse launch metadata: %s", err)
+		}
+	}
+
+	if len(launchTOML.Labels) > 0 {
+		launch.Labels = map[string]string{}
+		for _, label := range launchTOML.Labels {
+			launch.Labels[label.Key] = label.Value
+		}
+	}
+
+	return launch, nil
+}
+
+// ReadBuildMetadata returns the build metadata persisted in the build.toml
+// file of the layers directory by a previous build. If no build.toml exists,
+// an empty BuildMetadata is returned.
+func (l Layers) ReadBuildMetadata() (BuildMetadata, error) {
+	var build BuildMetadata
+	_, err := toml.DecodeFile(filepath.Join(l.Path, "build.toml"), &build)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return BuildMetadata{}, nil
+		}
+
+		return BuildMetadata{}, fmt.Errorf("failed to parse build metadata: %s", err)
+	}
+
+	return build, nil
+}
+
+// ReadStoreMetadata decodes the metadata table of the store.toml file in the
+// layers directory into the value pointed to by v according to the
+// specification:
+// https://github.com/buildpacks/spec/blob/main/buildpack.md#storetoml-toml.
+// If no store.toml exists, v is left unmodified.
+func (l Layers) ReadStoreMetadata(v interface{}) error {
+	var store struct {
+		Metadata toml.Primitive `toml:"metadata"`
+	}
+
+	metadata, err := toml.DecodeFile(filepath.Join(l.Path, "store.toml"), &store)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to parse store metadata: %s", err)
+	}
+
+	err = metadata.PrimitiveDecode(store.Metadata, v)
+	if err != nil {
+		return fmt.Errorf("failed to parse store metadata: %s", err)
+	}
+
+	return nil
+}
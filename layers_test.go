@@ -237,4 +237,195 @@ some-key = "some-value"`), 0644)
 			})
 		})
 	})
+
+	context("layers.ReadLaunchMetadata", func() {
+		it("returns empty launch metadata when there is no launch.toml", func() {
+			launch, err := layers.ReadLaunchMetadata()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(launch).To(Equal(packit.LaunchMetadata{}))
+		})
+
+		context("when there is a launch.toml", func() {
+			it.Before(func() {
+				err := os.WriteFile(filepath.Join(layersDir, "launch.toml"), []byte(`
+[[processes]]
+  type = "web"
+  command = "some-command"
+  args = ["some-arg"]
+  default = true
+
+[[processes]]
+  type = "worker"
+  command = ["some-direct-command"]
+  working-directory = "some-dir"
+
+[[slices]]
+  paths = ["some-path"]
+
+[[labels]]
+  key = "some-key"
+  value = "some-value"
+
+[[bom]]
+  name = "some-bom-entry"
+  [bom.metadata]
+    version = "some-version"
+`), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("returns the launch metadata", func() {
+				launch, err := layers.ReadLaunchMetadata()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(launch).To(Equal(packit.LaunchMetadata{
+					Processes: []packit.Process{
+						{
+							Type:    "web",
+							Command: "some-command",
+							Args:    []string{"some-arg"},
+							Default: true,
+						},
+					},
+					DirectProcesses: []packit.DirectProcess{
+						{
+							Type:             "worker",
+							Command:          []string{"some-direct-command"},
+							WorkingDirectory: "some-dir",
+						},
+					},
+					Slices: []packit.Slice{
+						{Paths: []string{"some-path"}},
+					},
+					Labels: map[string]string{
+						"some-key": "some-value",
+					},
+					BOM: []packit.BOMEntry{
+						{
+							Name: "some-bom-entry",
+							Metadata: map[string]interface{}{
+								"version": "some-version",
+							},
+						},
+					},
+				}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the launch.toml is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(layersDir, "launch.toml"), []byte("%%%"), 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := layers.ReadLaunchMetadata()
+					Expect(err).To(MatchError(ContainSubstring("failed to parse launch metadata:")))
+				})
+			})
+		})
+	})
+
+	context("layers.ReadBuildMetadata", func() {
+		it("returns empty build metadata when there is no build.toml", func() {
+			build, err := layers.ReadBuildMetadata()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(build).To(Equal(packit.BuildMetadata{}))
+		})
+
+		context("when there is a build.toml", func() {
+			it.Before(func() {
+				err := os.WriteFile(filepath.Join(layersDir, "build.toml"), []byte(`
+[[unmet]]
+  name = "some-unmet-entry"
+
+[[bom]]
+  name = "some-bom-entry"
+`), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("returns the build metadata", func() {
+				build, err := layers.ReadBuildMetadata()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(build).To(Equal(packit.BuildMetadata{
+					BOM: []packit.BOMEntry{
+						{Name: "some-bom-entry"},
+					},
+					Unmet: []packit.UnmetEntry{
+						{Name: "some-unmet-entry"},
+					},
+				}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the build.toml is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(layersDir, "build.toml"), []byte("%%%"), 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := layers.ReadBuildMetadata()
+					Expect(err).To(MatchError(ContainSubstring("failed to parse build metadata:")))
+				})
+			})
+		})
+	})
+
+	context("layers.ReadStoreMetadata", func() {
+		type store struct {
+			Version string `toml:"version"`
+		}
+
+		it("leaves the value unmodified when there is no store.toml", func() {
+			metadata := store{Version: "some-default"}
+			Expect(layers.ReadStoreMetadata(&metadata)).To(Succeed())
+			Expect(metadata.Version).To(Equal("some-default"))
+		})
+
+		context("when there is a store.toml", func() {
+			it.Before(func() {
+				err := os.WriteFile(filepath.Join(layersDir, "store.toml"), []byte(`
+[metadata]
+  version = "some-version"
+`), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("decodes the store metadata into the given value", func() {
+				var metadata store
+				Expect(layers.ReadStoreMetadata(&metadata)).To(Succeed())
+				Expect(metadata.Version).To(Equal("some-version"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the store.toml is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(layersDir, "store.toml"), []byte("%%%"), 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					var metadata store
+					err := layers.ReadStoreMetadata(&metadata)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse store metadata:")))
+				})
+			})
+
+			context("when the store metadata does not match the shape of the given value", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(layersDir, "store.toml"), []byte(`
+[metadata]
+  version = 1
+`), 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					var metadata store
+					err := layers.ReadStoreMetadata(&metadata)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse store metadata:")))
+				})
+			})
+		})
+	})
 }
@@ -0,0 +1,557 @@
+package packittest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/internal"
+)
+
+// BuildHarness describes the fixtures that will be provided to a BuildFunc
+// when it is invoked by the Run method. The harness mimics the files and
+// environment variables that the lifecycle provides during the build phase.
+type BuildHarness struct {
+	// WorkingDir is the location of the application source code. The process
+	// working directory is changed to this location for the duration of the
+	// build.
+	WorkingDir string
+
+	// CNBPath is the location of the buildpack contents. If this directory does
+	// not already contain a buildpack.toml, one is generated from the
+	// APIVersion and Info fields.
+	CNBPath string
+
+	// LayersPath is the location of the layers directory.
+	LayersPath string
+
+	// PlatformPath is the location of the platform directory.
+	PlatformPath string
+
+	// PlanPath is the location of the buildpack plan.toml file.
+	PlanPath string
+
+	// APIVersion is the Buildpack API version written into the generated
+	// buildpack.toml.
+	APIVersion string
+
+	// Info is the buildpack information written into the generated
+	// buildpack.toml.
+	Info packit.Info
+
+	// Plan is the buildpack plan provided to the build.
+	Plan packit.BuildpackPlan
+
+	// Stack is the value of $CNB_STACK_ID provided to the build.
+	Stack string
+
+	// Target is the value of the $CNB_TARGET_* environment variables provided
+	// to the build. The target is only made available to buildpacks that
+	// declare Buildpack API v0.10 or higher.
+	Target packit.Target
+
+	// PlatformEnv is the set of environment variables written into the
+	// platform env directory.
+	PlatformEnv map[string]string
+
+	// ExistingLayers are layers that are written into the layers directory
+	// before the build is invoked, as though they were restored from a previous
+	// build. The Path field of each layer is ignored.
+	ExistingLayers []packit.Layer
+}
+
+// BuildOutput is a typed view of everything that packit.Build wrote to disk
+// after invoking a BuildFunc.
+type BuildOutput struct {
+	// Context is the BuildContext that was provided to the BuildFunc.
+	Context packit.BuildContext
+
+	// Result is the BuildResult returned by the BuildFunc.
+	Result packit.BuildResult
+
+	// Plan is the content of the plan.toml file after the build.
+	Plan packit.BuildpackPlan
+
+	// Layers is the set of layers with a <layer>.toml file in the layers
+	// directory, keyed by layer name.
+	Layers map[string]LayerOutput
+
+	// Launch is the content of the launch.toml file. The SBOM field is always
+	// empty; SBOM files are reported in the SBOM field of the BuildOutput.
+	Launch packit.LaunchMetadata
+
+	// Build is the content of the build.toml file. The SBOM field is always
+	// empty; SBOM files are reported in the SBOM field of the BuildOutput.
+	Build packit.BuildMetadata
+
+	// SBOM contains the content of the launch.sbom.* and build.sbom.* files,
+	// keyed by file name.
+	SBOM map[string]string
+}
+
+// LayerOutput is a typed view of a layer that was written by packit.Build.
+type LayerOutput struct {
+	// Name is the name of the layer.
+	Name string
+
+	// Path is the absolute location of the layer on disk.
+	Path string
+
+	// Build, Launch and Cache are the layer types read from the <layer>.toml
+	// file.
+	Build  bool
+	Launch bool
+	Cache  bool
+
+	// Metadata is the metadata table read from the <layer>.toml file.
+	Metadata map[string]interface{}
+
+	// SharedEnv, BuildEnv and LaunchEnv are the files written into the env,
+	// env.build and env.launch directories of the layer.
+	SharedEnv packit.Environment
+	BuildEnv  packit.Environment
+	LaunchEnv packit.Environment
+
+	// ProcessLaunchEnv are the files written into the process-specific
+	// env.launch/<process> directories of the layer, keyed by process type.
+	ProcessLaunchEnv map[string]packit.Environment
+
+	// ExecD is the sorted list of file names in the exec.d directory of the
+	// layer.
+	ExecD []string
+
+	// SBOM contains the content of the <layer>.sbom.* files, keyed by
+	// extension.
+	SBOM map[string]string
+}
+
+// NewBuildHarness returns a BuildHarness with each of its fixture paths
+// located in the given directory.
+func NewBuildHarness(dir string) BuildHarness {
+	return BuildHarness{
+		WorkingDir:   filepath.Join(dir, "workspace"),
+		CNBPath:      filepath.Join(dir, "cnb"),
+		LayersPath:   filepath.Join(dir, "layers"),
+		PlatformPath: filepath.Join(dir, "platform"),
+		PlanPath:     filepath.Join(dir, "plan.toml"),
+		APIVersion:   "0.8",
+	}
+}
+
+// Run sets up the harness fixtures, invokes packit.Build with the given
+// BuildFunc and returns everything that Build wrote to disk. Any error passed
+// to the ExitHandler by Build is returned to the caller.
+//
+// Run modifies the process working directory and $CNB_* environment variables
+// while the build executes and so must not be called concurrently.
+func (h BuildHarness) Run(f packit.BuildFunc, options ...packit.Option) (BuildOutput, error) {
+	err := h.setup()
+	if err != nil {
+		return BuildOutput{}, err
+	}
+
+	var output BuildOutput
+	err = h.build(f, &output, options...)
+	if err != nil {
+		return BuildOutput{}, err
+	}
+
+	err = h.read(&output)
+	if err != nil {
+		return BuildOutput{}, err
+	}
+
+	return output, nil
+}
+
+// build invokes packit.Build with the working directory and environment of
+// the harness, restoring them when it returns, even if the BuildFunc panics.
+func (h BuildHarness) build(f packit.BuildFunc, output *BuildOutput, options ...packit.Option) (err error) {
+	restore, err := h.enter()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		restoreErr := restore()
+		if restoreErr != nil {
+			err = restoreErr
+		}
+	}()
+
+	exitHandler := &exitHandler{}
+
+	options = append(options,
+		packit.WithArgs([]string{filepath.Join(h.CNBPath, "bin", "build"), h.LayersPath, h.PlatformPath, h.PlanPath}),
+		packit.WithExitHandler(exitHandler),
+	)
+
+	packit.Build(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+		output.Context = ctx
+
+		result, err := f(ctx)
+		output.Result = result
+
+		return result, err
+	}, options...)
+
+	return exitHandler.err
+}
+
+func (h BuildHarness) setup() error {
+	for _, dir := range []string{h.WorkingDir, h.CNBPath, h.LayersPath, filepath.Join(h.PlatformPath, "env")} {
+		err := os.MkdirAll(dir, os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("failed to create fixture directory: %w", err)
+		}
+	}
+
+	tomlWriter := internal.NewTOMLWriter()
+
+	bpTOMLPath := filepath.Join(h.CNBPath, "buildpack.toml")
+	_, err := os.Stat(bpTOMLPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to stat buildpack.toml: %w", err)
+		}
+
+		err = writeBuildpackTOML(bpTOMLPath, h.APIVersion, h.Info)
+		if err != nil {
+			return fmt.Errorf("failed to write buildpack.toml: %w", err)
+		}
+	}
+
+	err = tomlWriter.Write(h.PlanPath, h.Plan)
+	if err != nil {
+		return fmt.Errorf("failed to write plan.toml: %w", err)
+	}
+
+	for key, value := range h.PlatformEnv {
+		err = os.WriteFile(filepath.Join(h.PlatformPath, "env", key), []byte(value), 0644)
+		if err != nil {
+			return fmt.Errorf("failed to write platform env: %w", err)
+		}
+	}
+
+	envWriter := internal.NewEnvironmentWriter()
+	for _, layer := range h.ExistingLayers {
+		layerPath := filepath.Join(h.LayersPath, layer.Name)
+
+		err = os.MkdirAll(layerPath, os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("failed to create existing layer: %w", err)
+		}
+
+		err = tomlWriter.Write(filepath.Join(h.LayersPath, fmt.Sprintf("%s.toml", layer.Name)), map[string]interface{}{
+			"types": map[string]bool{
+				"build":  layer.Build,
+				"launch": layer.Launch,
+				"cache":  layer.Cache,
+			},
+			"metadata": layer.Metadata,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write existing layer metadata: %w", err)
+		}
+
+		envs := map[string]packit.Environment{
+			"env":        layer.SharedEnv,
+			"env.build":  layer.BuildEnv,
+			"env.launch": layer.LaunchEnv,
+		}
+		for process, env := range layer.ProcessLaunchEnv {
+			envs[filepath.Join("env.launch", process)] = env
+		}
+
+		for dir, env := range envs {
+			err = envWriter.Write(filepath.Join(layerPath, dir), env)
+			if err != nil {
+				return fmt.Errorf("failed to write existing layer environment: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeBuildpackTOML(path, apiVersion string, info packit.Info) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return toml.NewEncoder(file).Encode(struct {
+		APIVersion string      `toml:"api"`
+		Buildpack  packit.Info `toml:"buildpack"`
+	}{
+		APIVersion: apiVersion,
+		Buildpack:  info,
+	})
+}
+
+func (h BuildHarness) enter() (func() error, error) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{
+		"CNB_BUILDPACK_DIR": h.CNBPath,
+		"CNB_LAYERS_DIR":    h.LayersPath,
+		"CNB_PLATFORM_DIR":  h.PlatformPath,
+		"CNB_BP_PLAN_PATH":  h.PlanPath,
+		"CNB_STACK_ID":      h.Stack,
+
+		"CNB_TARGET_OS":             h.Target.OS,
+		"CNB_TARGET_ARCH":           h.Target.Arch,
+		"CNB_TARGET_ARCH_VARIANT":   h.Target.ArchVariant,
+		"CNB_TARGET_DISTRO_NAME":    h.Target.DistroName,
+		"CNB_TARGET_DISTRO_VERSION": h.Target.DistroVersion,
+	}
+
+	previous := map[string]*string{}
+	for key, value := range env {
+		if original, ok := os.LookupEnv(key); ok {
+			previous[key] = &original
+		} else {
+			previous[key] = nil
+		}
+
+		err = os.Setenv(key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	restoreEnv := func() error {
+		for key, value := range previous {
+			var err error
+			if value == nil {
+				err = os.Unsetenv(key)
+			} else {
+				err = os.Setenv(key, *value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err = os.Chdir(h.WorkingDir)
+	if err != nil {
+		_ = restoreEnv()
+		return nil, err
+	}
+
+	return func() error {
+		err := restoreEnv()
+		if err != nil {
+			return err
+		}
+
+		return os.Chdir(pwd)
+	}, nil
+}
+
+func (h BuildHarness) read(output *BuildOutput) error {
+	_, err := toml.DecodeFile(h.PlanPath, &output.Plan)
+	if err != nil {
+		return fmt.Errorf("failed to parse plan.toml: %w", err)
+	}
+
+	output.Layers = map[string]LayerOutput{}
+	output.SBOM = map[string]string{}
+
+	files, err := filepath.Glob(filepath.Join(h.LayersPath, "*.toml"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		switch filepath.Base(file) {
+		case "launch.toml", "build.toml", "store.toml":
+			continue
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), ".toml")
+		output.Layers[name], err = readLayer(h.LayersPath, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	layers := packit.Layers{Path: h.LayersPath}
+
+	output.Launch, err = layers.ReadLaunchMetadata()
+	if err != nil {
+		return err
+	}
+
+	output.Build, err = layers.ReadBuildMetadata()
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range []string{"launch", "build"} {
+		sboms, err := readSBOMFiles(h.LayersPath, prefix)
+		if err != nil {
+			return err
+		}
+
+		for extension, content := range sboms {
+			output.SBOM[fmt.Sprintf("%s.sbom.%s", prefix, extension)] = content
+		}
+	}
+
+	return nil
+}
+
+func readLayer(layersPath, name string) (LayerOutput, error) {
+	var layerTOML struct {
+		Build  bool `toml:"build"`
+		Launch bool `toml:"launch"`
+		Cache  bool `toml:"cache"`
+		Types  *struct {
+			Build  bool `toml:"build"`
+			Launch bool `toml:"launch"`
+			Cache  bool `toml:"cache"`
+		} `toml:"types"`
+		Metadata map[string]interface{} `toml:"metadata"`
+	}
+
+	_, err := toml.DecodeFile(filepath.Join(layersPath, fmt.Sprintf("%s.toml", name)), &layerTOML)
+	if err != nil {
+		return LayerOutput{}, fmt.Errorf("failed to parse layer content metadata: %w", err)
+	}
+
+	layer := LayerOutput{
+		Name:             name,
+		Path:             filepath.Join(layersPath, name),
+		Build:            layerTOML.Build,
+		Launch:           layerTOML.Launch,
+		Cache:            layerTOML.Cache,
+		Metadata:         layerTOML.Metadata,
+		ProcessLaunchEnv: map[string]packit.Environment{},
+	}
+
+	if layerTOML.Types != nil {
+		layer.Build = layerTOML.Types.Build
+		layer.Launch = layerTOML.Types.Launch
+		layer.Cache = layerTOML.Types.Cache
+	}
+
+	layer.SharedEnv, err = readEnvironment(filepath.Join(layer.Path, "env"))
+	if err != nil {
+		return LayerOutput{}, err
+	}
+
+	layer.BuildEnv, err = readEnvironment(filepath.Join(layer.Path, "env.build"))
+	if err != nil {
+		return LayerOutput{}, err
+	}
+
+	layer.LaunchEnv, err = readEnvironment(filepath.Join(layer.Path, "env.launch"))
+	if err != nil {
+		return LayerOutput{}, err
+	}
+
+	entries, err := readDir(filepath.Join(layer.Path, "env.launch"))
+	if err != nil {
+		return LayerOutput{}, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			layer.ProcessLaunchEnv[entry.Name()], err = readEnvironment(filepath.Join(layer.Path, "env.launch", entry.Name()))
+			if err != nil {
+				return LayerOutput{}, err
+			}
+		}
+	}
+
+	entries, err = readDir(filepath.Join(layer.Path, "exec.d"))
+	if err != nil {
+		return LayerOutput{}, err
+	}
+
+	for _, entry := range entries {
+		layer.ExecD = append(layer.ExecD, entry.Name())
+	}
+	sort.Strings(layer.ExecD)
+
+	layer.SBOM, err = readSBOMFiles(layersPath, name)
+	if err != nil {
+		return LayerOutput{}, err
+	}
+
+	return layer, nil
+}
+
+func readEnvironment(dir string) (packit.Environment, error) {
+	entries, err := readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	env := packit.Environment{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read environment variable: %w", err)
+		}
+
+		env[entry.Name()] = string(content)
+	}
+
+	return env, nil
+}
+
+func readSBOMFiles(layersPath, prefix string) (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(layersPath, fmt.Sprintf("%s.sbom.*", prefix)))
+	if err != nil {
+		return nil, err
+	}
+
+	sboms := map[string]string{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sbom file: %w", err)
+		}
+
+		sboms[strings.TrimPrefix(filepath.Base(file), fmt.Sprintf("%s.sbom.", prefix))] = string(content)
+	}
+
+	return sboms, nil
+}
+
+func readDir(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+type exitHandler struct {
+	err error
+}
+
+func (h *exitHandler) Error(err error) {
+	h.err = err
+}
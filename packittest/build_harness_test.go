@@ -0,0 +1,396 @@
+package packittest_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/packittest"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testBuildHarness(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		tmpDir  string
+		harness packittest.BuildHarness
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "harness")
+		Expect(err).NotTo(HaveOccurred())
+
+		tmpDir, err = filepath.EvalSymlinks(tmpDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		harness = packittest.NewBuildHarness(tmpDir)
+		harness.Info = packit.Info{
+			ID:      "some-id",
+			Name:    "some-name",
+			Version: "some-version",
+		}
+		harness.Stack = "some-stack"
+		harness.Plan = packit.BuildpackPlan{
+			Entries: []packit.BuildpackPlanEntry{
+				{
+					Name: "some-entry",
+					Metadata: map[string]interface{}{
+						"version": "some-version",
+					},
+				},
+			},
+		}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	it("provides the build context to the given BuildFunc", func() {
+		output, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+			return packit.BuildResult{}, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(output.Context).To(Equal(packit.BuildContext{
+			CNBPath:    filepath.Join(tmpDir, "cnb"),
+			Stack:      "some-stack",
+			WorkingDir: filepath.Join(tmpDir, "workspace"),
+			Platform: packit.Platform{
+				Path: filepath.Join(tmpDir, "platform"),
+			},
+			Layers: packit.Layers{
+				Path: filepath.Join(tmpDir, "layers"),
+			},
+			Plan: packit.BuildpackPlan{
+				Entries: []packit.BuildpackPlanEntry{
+					{
+						Name: "some-entry",
+						Metadata: map[string]interface{}{
+							"version": "some-version",
+						},
+					},
+				},
+			},
+			BuildpackInfo: packit.BuildpackInfo{
+				ID:      "some-id",
+				Name:    "some-name",
+				Version: "some-version",
+			},
+		}))
+	})
+
+	it("restores the working directory and environment after the build", func() {
+		pwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Setenv("CNB_STACK_ID", "original-stack")).To(Succeed())
+		defer os.Unsetenv("CNB_STACK_ID")
+
+		_, err = harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+			return packit.BuildResult{}, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Getwd()).To(Equal(pwd))
+		Expect(os.Getenv("CNB_STACK_ID")).To(Equal("original-stack"))
+
+		_, ok := os.LookupEnv("CNB_LAYERS_DIR")
+		Expect(ok).To(BeFalse())
+	})
+
+	it("restores the working directory and environment when the build panics", func() {
+		pwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(func() {
+			_, _ = harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+				panic("some-panic")
+			})
+		}).To(PanicWith("some-panic"))
+
+		Expect(os.Getwd()).To(Equal(pwd))
+
+		_, ok := os.LookupEnv("CNB_LAYERS_DIR")
+		Expect(ok).To(BeFalse())
+	})
+
+	it("writes the platform env fixtures", func() {
+		harness.PlatformEnv = map[string]string{
+			"SOME_VAR": "some-value",
+		}
+
+		var content []byte
+		_, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+			var err error
+			content, err = os.ReadFile(filepath.Join(ctx.Platform.Path, "env", "SOME_VAR"))
+			return packit.BuildResult{}, err
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal("some-value"))
+	})
+
+	it("returns the layers written by the build", func() {
+		execdPath := filepath.Join(tmpDir, "some-execd")
+		Expect(os.WriteFile(execdPath, []byte("exec.d"), 0755)).To(Succeed())
+
+		output, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+			layer, err := ctx.Layers.Get("some-layer")
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			layer.Launch = true
+			layer.Cache = true
+			layer.Metadata = map[string]interface{}{
+				"some-key": "some-value",
+			}
+			layer.SharedEnv.Override("SHARED_VAR", "shared-value")
+			layer.BuildEnv.Default("BUILD_VAR", "build-value")
+			layer.LaunchEnv.Prepend("LAUNCH_VAR", "launch-value", ":")
+			layer.ProcessLaunchEnv["web"] = packit.Environment{}
+			layer.ProcessLaunchEnv["web"].Override("WEB_VAR", "web-value")
+			layer.ExecD = []string{execdPath}
+			layer.SBOM = packit.SBOMFormats{
+				{Extension: "cdx.json", Content: strings.NewReader(`{"cdx": true}`)},
+			}
+
+			return packit.BuildResult{
+				Layers: []packit.Layer{layer},
+			}, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(output.Layers).To(Equal(map[string]packittest.LayerOutput{
+			"some-layer": {
+				Name:   "some-layer",
+				Path:   filepath.Join(tmpDir, "layers", "some-layer"),
+				Launch: true,
+				Cache:  true,
+				Metadata: map[string]interface{}{
+					"some-key": "some-value",
+				},
+				SharedEnv: packit.Environment{
+					"SHARED_VAR.override": "shared-value",
+				},
+				BuildEnv: packit.Environment{
+					"BUILD_VAR.default": "build-value",
+				},
+				LaunchEnv: packit.Environment{
+					"LAUNCH_VAR.prepend": "launch-value",
+					"LAUNCH_VAR.delim":   ":",
+				},
+				ProcessLaunchEnv: map[string]packit.Environment{
+					"web": {
+						"WEB_VAR.override": "web-value",
+					},
+				},
+				ExecD: []string{"0-some-execd"},
+				SBOM: map[string]string{
+					"cdx.json": `{"cdx": true}`,
+				},
+			},
+		}))
+	})
+
+	it("returns the launch and build metadata written by the build", func() {
+		output, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+			return packit.BuildResult{
+				Launch: packit.LaunchMetadata{
+					Processes: []packit.Process{
+						{
+							Type:    "web",
+							Command: "some-command",
+							Args:    []string{"some-arg"},
+							Default: true,
+						},
+					},
+					Labels: map[string]string{
+						"some-label": "some-value",
+					},
+					SBOM: packit.SBOMFormats{
+						{Extension: "spdx.json", Content: strings.NewReader(`{"spdx": true}`)},
+					},
+				},
+				Build: packit.BuildMetadata{
+					Unmet: []packit.UnmetEntry{
+						{Name: "some-unmet-entry"},
+					},
+				},
+			}, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(output.Launch).To(Equal(packit.LaunchMetadata{
+			Processes: []packit.Process{
+				{
+					Type:    "web",
+					Command: "some-command",
+					Args:    []string{"some-arg"},
+					Default: true,
+				},
+			},
+			Labels: map[string]string{
+				"some-label": "some-value",
+			},
+		}))
+
+		Expect(output.Build).To(Equal(packit.BuildMetadata{
+			Unmet: []packit.UnmetEntry{
+				{Name: "some-unmet-entry"},
+			},
+		}))
+
+		Expect(output.SBOM).To(Equal(map[string]string{
+			"launch.sbom.spdx.json": `{"spdx": true}`,
+		}))
+	})
+
+	context("when the buildpack API version is 0.9", func() {
+		it.Before(func() {
+			harness.APIVersion = "0.9"
+		})
+
+		it("returns direct processes", func() {
+			output, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+				return packit.BuildResult{
+					Launch: packit.LaunchMetadata{
+						DirectProcesses: []packit.DirectProcess{
+							{
+								Type:    "web",
+								Command: []string{"some-command"},
+							},
+						},
+					},
+				}, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(output.Launch.DirectProcesses).To(Equal([]packit.DirectProcess{
+				{
+					Type:    "web",
+					Command: []string{"some-command"},
+					Args:    []string{},
+				},
+			}))
+		})
+	})
+
+	context("when the buildpack API version is 0.10", func() {
+		it.Before(func() {
+			harness.APIVersion = "0.10"
+			harness.Target = packit.Target{
+				OS:   "linux",
+				Arch: "arm64",
+			}
+		})
+
+		it("provides the target to the build", func() {
+			output, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+				return packit.BuildResult{}, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(output.Context.Target).To(Equal(packit.Target{
+				OS:   "linux",
+				Arch: "arm64",
+			}))
+		})
+	})
+
+	context("when there are existing layers", func() {
+		it.Before(func() {
+			harness.ExistingLayers = []packit.Layer{
+				{
+					Name: "cached-layer",
+					Metadata: map[string]interface{}{
+						"sha": "some-sha",
+					},
+					SharedEnv: packit.Environment{
+						"SOME_VAR.override": "some-value",
+					},
+				},
+			}
+		})
+
+		it("makes them available to the build", func() {
+			var layer packit.Layer
+			_, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+				var err error
+				layer, err = ctx.Layers.Get("cached-layer")
+				return packit.BuildResult{}, err
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layer.Metadata).To(Equal(map[string]interface{}{
+				"sha": "some-sha",
+			}))
+			Expect(layer.SharedEnv).To(Equal(packit.Environment{
+				"SOME_VAR.override": "some-value",
+			}))
+		})
+	})
+
+	context("when the buildpack.toml already exists", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(tmpDir, "cnb"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpDir, "cnb", "buildpack.toml"), []byte(`
+api = "0.8"
+[buildpack]
+  id = "other-id"
+`), 0600)).To(Succeed())
+		})
+
+		it("does not overwrite it", func() {
+			output, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+				return packit.BuildResult{}, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.Context.BuildpackInfo.ID).To(Equal("other-id"))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the BuildFunc returns an error", func() {
+			it("returns that error", func() {
+				_, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+					return packit.BuildResult{}, errors.New("failed to build")
+				})
+				Expect(err).To(MatchError("failed to build"))
+			})
+		})
+
+		context("when Build rejects the result", func() {
+			it("returns that error", func() {
+				_, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+					return packit.BuildResult{
+						Launch: packit.LaunchMetadata{
+							DirectProcesses: []packit.DirectProcess{{Type: "web"}},
+						},
+					}, nil
+				})
+				Expect(err).To(MatchError("direct processes can only be used with Buildpack API v0.9 or higher"))
+			})
+		})
+
+		context("when the fixture directories cannot be created", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(tmpDir, "layers"), nil, 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := harness.Run(func(ctx packit.BuildContext) (packit.BuildResult, error) {
+					return packit.BuildResult{}, nil
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to create fixture directory")))
+			})
+		})
+	})
+}
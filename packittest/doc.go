@@ -0,0 +1,41 @@
+// Package packittest provides a harness for exercising a packit.BuildFunc
+// end-to-end against fixture directories. The harness sets up the layers,
+// platform and plan fixtures that the lifecycle would normally provide,
+// invokes packit.Build in-process, and returns a typed view of everything that
+// Build wrote to disk.
+//
+// Below is an example showing how you might use a BuildHarness in a test:
+//
+//   package main_test
+//
+//   import (
+//   	"os"
+//   	"testing"
+//
+//   	"github.com/paketo-buildpacks/packit/v2"
+//   	"github.com/paketo-buildpacks/packit/v2/packittest"
+//   )
+//
+//   func TestBuild(t *testing.T) {
+//   	dir, err := os.MkdirTemp("", "harness")
+//   	if err != nil {
+//   		t.Fatal(err)
+//   	}
+//   	defer os.RemoveAll(dir)
+//
+//   	harness := packittest.NewBuildHarness(dir)
+//   	harness.Plan = packit.BuildpackPlan{
+//   		Entries: []packit.BuildpackPlanEntry{{Name: "node"}},
+//   	}
+//
+//   	output, err := harness.Run(Build())
+//   	if err != nil {
+//   		t.Fatal(err)
+//   	}
+//
+//   	if !output.Layers["node"].Launch {
+//   		t.Error("expected node layer to be a launch layer")
+//   	}
+//   }
+//
+package packittest
@@ -0,0 +1,14 @@
+package packittest_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitPackittest(t *testing.T) {
+	suite := spec.New("packit/packittest", spec.Report(report.Terminal{}))
+	suite("BuildHarness", testBuildHarness)
+	suite.Run(t)
+}
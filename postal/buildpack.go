@@ -3,9 +3,11 @@ package postal
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/paketo-buildpacks/packit/v2/cargo"
 )
 
@@ -65,17 +67,53 @@ type Dependency struct {
 	// Stacks is a list of stacks for which the dependency is built.
 	Stacks []string `toml:"stacks"`
 
+	// OS is the operating system for which the dependency is built, eg.
+	// "linux". An empty value or "*" indicates that the dependency supports
+	// any operating system.
+	OS string `toml:"os"`
+
+	// Arch is the CPU architecture for which the dependency is built, eg.
+	// "amd64" or "arm64". An empty value or "*" indicates that the dependency
+	// supports any architecture.
+	Arch string `toml:"arch"`
+
+	// Distros is a list of operating system distributions for which the
+	// dependency is built. An empty list indicates that the dependency
+	// supports any distribution.
+	Distros []DependencyDistro `toml:"distros"`
+
 	// URI is the uri location of the built dependency.
 	URI string `toml:"uri"`
 
 	// Version is the specific version of the dependency.
 	Version string `toml:"version"`
 
+	// SignatureURI is the uri location of a detached signature of the built
+	// dependency. When it is set, the dependency is only delivered if the
+	// signature can be verified using the key identified by SignatureKeyID.
+	SignatureURI string `toml:"signature-uri"`
+
+	// SignatureKeyID is the ID of the public key that is used to verify the
+	// signature found at SignatureURI.
+	SignatureKeyID string `toml:"signature-key-id"`
+
 	// StripComponents behaves like the --strip-components flag on tar command
 	// removing the first n levels from the final decompression destination.
 	StripComponents int `toml:"strip-components"`
 }
 
+// DependencyDistro is a representation of an operating system distribution
+// that a dependency is built for.
+type DependencyDistro struct {
+	// Name is the name of the distribution, eg. "ubuntu". A value of "*"
+	// matches any distribution.
+	Name string `toml:"name"`
+
+	// Version is the version of the distribution, eg. "22.04". An empty value
+	// or "*" matches any version of the named distribution.
+	Version string `toml:"version"`
+}
+
 func parseBuildpack(path, name string) ([]Dependency, string, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -104,3 +142,71 @@ func stacksInclude(stacks []string, stack string) bool {
 	}
 	return false
 }
+
+func targetIncludes(dependency Dependency, target packit.Target) bool {
+	if !targetFieldIncludes(dependency.OS, target.OS) || !targetFieldIncludes(dependency.Arch, target.Arch) {
+		return false
+	}
+
+	if len(dependency.Distros) == 0 {
+		return true
+	}
+
+	for _, distro := range dependency.Distros {
+		if targetFieldIncludes(distro.Name, target.DistroName) && targetFieldIncludes(distro.Version, target.DistroVersion) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func targetFieldIncludes(value, target string) bool {
+	return value == "" || value == "*" || value == target
+}
+
+// targetSpecificity returns the number of target fields that a dependency
+// declares a specific value for when it is matched against the given target.
+// Only the distros that match the target are counted. A dependency with a
+// specificity of zero supports any target.
+func targetSpecificity(dependency Dependency, target packit.Target) int {
+	var specificity int
+	for _, value := range []string{dependency.OS, dependency.Arch} {
+		if value != "" && value != "*" {
+			specificity++
+		}
+	}
+
+	var distroSpecificity int
+	for _, distro := range dependency.Distros {
+		if !targetFieldIncludes(distro.Name, target.DistroName) || !targetFieldIncludes(distro.Version, target.DistroVersion) {
+			continue
+		}
+
+		var count int
+		for _, value := range []string{distro.Name, distro.Version} {
+			if value != "" && value != "*" {
+				count++
+			}
+		}
+
+		if count > distroSpecificity {
+			distroSpecificity = count
+		}
+	}
+
+	return specificity + distroSpecificity
+}
+
+func formatTarget(target packit.Target) string {
+	platform := fmt.Sprintf("%s/%s", target.OS, target.Arch)
+	if target.ArchVariant != "" {
+		platform = fmt.Sprintf("%s/%s", platform, target.ArchVariant)
+	}
+
+	if target.DistroName != "" {
+		platform = strings.TrimSpace(fmt.Sprintf("%s %s %s", platform, target.DistroName, target.DistroVersion))
+	}
+
+	return platform
+}
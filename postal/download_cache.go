@@ -0,0 +1,238 @@
+package postal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+)
+
+//go:generate faux --interface Cache --output fakes/cache.go
+
+// Cache serves as the interface for types that can store and retrieve
+// downloaded dependency artifacts keyed by their checksum. Get must only
+// return content that matches the checksum, and Put must read all of the
+// given content and return cargo.ChecksumValidationError, without storing it,
+// when it does not match, as the Service does not validate the content again.
+// A Cache that cannot store artifacts for a checksum returns an error
+// wrapping ErrUncacheableChecksum, in which case the Service does not use it.
+type Cache interface {
+	Get(checksum cargo.Checksum) (io.ReadCloser, bool, error)
+	Put(checksum cargo.Checksum, reader io.Reader) error
+}
+
+// ErrUncacheableChecksum is returned by a Cache when it cannot store
+// artifacts for the given checksum.
+var ErrUncacheableChecksum = errors.New("checksum cannot be used as a cache key")
+
+var cacheHashPattern = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// DownloadCache is an on-disk, content-addressed store of downloaded
+// dependency artifacts. Artifacts are stored under the cache path according
+// to their checksum and are verified against that checksum both when they are
+// written and when they are read. When the total size of the stored artifacts
+// exceeds the maximum size, the least recently used artifacts are evicted.
+//
+// The cache path is commonly the path of a layer that is marked as a cache
+// layer so that artifacts are retained between builds.
+type DownloadCache struct {
+	path    string
+	maxSize int64
+}
+
+// NewDownloadCache creates an instance of a DownloadCache that stores
+// artifacts in the given path. A maxSize of zero or less disables eviction.
+func NewDownloadCache(path string, maxSize int64) DownloadCache {
+	return DownloadCache{
+		path:    path,
+		maxSize: maxSize,
+	}
+}
+
+// Get returns a reader for the artifact with the given checksum, along with
+// true, if it is present in the cache. An artifact whose content no longer
+// matches its checksum is removed from the cache and reported as missing.
+func (c DownloadCache) Get(checksum cargo.Checksum) (io.ReadCloser, bool, error) {
+	path, err := c.entryPath(checksum)
+	if err != nil {
+		return nil, false, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to open cache entry: %w", err)
+	}
+
+	ok, err := cargo.NewValidatedReader(file, string(checksum)).Valid()
+	if err != nil {
+		file.Close()
+		return nil, false, fmt.Errorf("failed to validate cache entry: %w", err)
+	}
+
+	if !ok {
+		file.Close()
+		err = os.Remove(path)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to remove invalid cache entry: %w", err)
+		}
+
+		return nil, false, nil
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	now := time.Now()
+	err = os.Chtimes(path, now, now)
+	if err != nil {
+		file.Close()
+		return nil, false, fmt.Errorf("failed to update cache entry: %w", err)
+	}
+
+	return file, true, nil
+}
+
+// Put stores the content of the given reader in the cache under the given
+// checksum. The content is only stored if it matches the checksum, otherwise
+// cargo.ChecksumValidationError is returned. Once stored, the least recently
+// used artifacts are evicted until the cache is within its maximum size.
+func (c DownloadCache) Put(checksum cargo.Checksum, reader io.Reader) error {
+	path, err := c.entryPath(checksum)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s-*", checksum.Hash()))
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, cargo.NewValidatedReader(reader, string(checksum)))
+	if err != nil {
+		file.Close()
+
+		if errors.Is(err, cargo.ChecksumValidationError) {
+			return err
+		}
+
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return c.evict(path)
+}
+
+func (c DownloadCache) entryPath(checksum cargo.Checksum) (string, error) {
+	algorithm := checksum.Algorithm()
+	if algorithm != "sha256" && algorithm != "sha512" {
+		return "", fmt.Errorf("%w: unsupported algorithm %q: the following algorithms are supported [sha256, sha512]", ErrUncacheableChecksum, algorithm)
+	}
+
+	hash := checksum.Hash()
+	if !cacheHashPattern.MatchString(hash) {
+		return "", fmt.Errorf("%w: invalid checksum %q: hash must be hexadecimal", ErrUncacheableChecksum, checksum)
+	}
+
+	return filepath.Join(c.path, algorithm, hash), nil
+}
+
+// evict removes the least recently used artifacts until the total size of the
+// cache is within its maximum size. The given path is never evicted so that
+// an artifact that was just stored remains available.
+func (c DownloadCache) evict(keep string) error {
+	if c.maxSize <= 0 {
+		return nil
+	}
+
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var entries []entry
+	var total int64
+	for _, algorithm := range []string{"sha256", "sha512"} {
+		files, err := os.ReadDir(filepath.Join(c.path, algorithm))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return fmt.Errorf("failed to list cache entries: %w", err)
+		}
+
+		for _, f := range files {
+			if f.IsDir() || !cacheHashPattern.MatchString(f.Name()) {
+				continue
+			}
+
+			info, err := f.Info()
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+
+				return fmt.Errorf("failed to list cache entries: %w", err)
+			}
+
+			entries = append(entries, entry{
+				path:    filepath.Join(c.path, algorithm, f.Name()),
+				size:    info.Size(),
+				modTime: info.ModTime(),
+			})
+			total += info.Size()
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+
+	for _, e := range entries {
+		if total <= c.maxSize {
+			break
+		}
+
+		if e.path == keep {
+			continue
+		}
+
+		err := os.Remove(e.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to evict cache entry: %w", err)
+		}
+
+		total -= e.size
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package postal_test
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDownloadCache(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cachePath string
+		cache     postal.DownloadCache
+	)
+
+	sha256Checksum := func(content string) cargo.Checksum {
+		sum := sha256.Sum256([]byte(content))
+		return cargo.Checksum(fmt.Sprintf("sha256:%s", hex.EncodeToString(sum[:])))
+	}
+
+	readEntry := func(checksum cargo.Checksum) (string, bool) {
+		reader, ok, err := cache.Get(checksum)
+		Expect(err).NotTo(HaveOccurred())
+
+		if !ok {
+			return "", false
+		}
+		defer reader.Close()
+
+		content, err := io.ReadAll(reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(content), true
+	}
+
+	it.Before(func() {
+		var err error
+		cachePath, err = os.MkdirTemp("", "cache")
+		Expect(err).NotTo(HaveOccurred())
+
+		cache = postal.NewDownloadCache(cachePath, 0)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(cachePath)).To(Succeed())
+	})
+
+	context("Get", func() {
+		context("when the artifact is not in the cache", func() {
+			it("reports that it is missing", func() {
+				_, ok, err := cache.Get(sha256Checksum("some-content"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+		})
+
+		context("when the artifact in the cache no longer matches its checksum", func() {
+			var checksum cargo.Checksum
+
+			it.Before(func() {
+				checksum = sha256Checksum("some-content")
+				Expect(cache.Put(checksum, strings.NewReader("some-content"))).To(Succeed())
+
+				path := filepath.Join(cachePath, "sha256", checksum.Hash())
+				Expect(os.WriteFile(path, []byte("corrupted-content"), 0600)).To(Succeed())
+			})
+
+			it("removes it and reports that it is missing", func() {
+				_, ok, err := cache.Get(checksum)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+
+				Expect(filepath.Join(cachePath, "sha256", checksum.Hash())).NotTo(BeAnExistingFile())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the checksum algorithm is not supported", func() {
+				it("returns an error", func() {
+					_, _, err := cache.Get(cargo.Checksum("md5:abc123"))
+					Expect(errors.Is(err, postal.ErrUncacheableChecksum)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring(`unsupported algorithm "md5": the following algorithms are supported [sha256, sha512]`)))
+				})
+			})
+
+			context("when the checksum hash is not hexadecimal", func() {
+				it("returns an error", func() {
+					_, _, err := cache.Get(cargo.Checksum("sha256:../../some-file"))
+					Expect(errors.Is(err, postal.ErrUncacheableChecksum)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring(`invalid checksum "sha256:../../some-file": hash must be hexadecimal`)))
+				})
+			})
+		})
+	})
+
+	context("Put", func() {
+		it("stores the artifact so that it can be read back", func() {
+			checksum := sha256Checksum("some-content")
+			Expect(cache.Put(checksum, strings.NewReader("some-content"))).To(Succeed())
+
+			content, ok := readEntry(checksum)
+			Expect(ok).To(BeTrue())
+			Expect(content).To(Equal("some-content"))
+
+			Expect(filepath.Join(cachePath, "sha256", checksum.Hash())).To(BeARegularFile())
+		})
+
+		context("when the checksum uses sha512", func() {
+			it("stores the artifact", func() {
+				sum := sha512.Sum512([]byte("some-content"))
+				checksum := cargo.Checksum(fmt.Sprintf("sha512:%s", hex.EncodeToString(sum[:])))
+				Expect(cache.Put(checksum, strings.NewReader("some-content"))).To(Succeed())
+
+				content, ok := readEntry(checksum)
+				Expect(ok).To(BeTrue())
+				Expect(content).To(Equal("some-content"))
+			})
+		})
+
+		context("when the cache exceeds its maximum size", func() {
+			var first, second, third cargo.Checksum
+
+			it.Before(func() {
+				cache = postal.NewDownloadCache(cachePath, 30)
+
+				first = sha256Checksum("first-content")
+				second = sha256Checksum("second-content")
+				third = sha256Checksum("third-content")
+
+				Expect(cache.Put(first, strings.NewReader("first-content"))).To(Succeed())
+				Expect(cache.Put(second, strings.NewReader("second-content"))).To(Succeed())
+
+				past := time.Now().Add(-time.Hour)
+				Expect(os.Chtimes(filepath.Join(cachePath, "sha256", first.Hash()), past, past)).To(Succeed())
+				Expect(os.Chtimes(filepath.Join(cachePath, "sha256", second.Hash()), past.Add(time.Minute), past.Add(time.Minute))).To(Succeed())
+			})
+
+			it("evicts the least recently used artifacts", func() {
+				_, ok := readEntry(first)
+				Expect(ok).To(BeTrue())
+
+				Expect(cache.Put(third, strings.NewReader("third-content"))).To(Succeed())
+
+				_, ok = readEntry(second)
+				Expect(ok).To(BeFalse())
+
+				_, ok = readEntry(first)
+				Expect(ok).To(BeTrue())
+
+				content, ok := readEntry(third)
+				Expect(ok).To(BeTrue())
+				Expect(content).To(Equal("third-content"))
+			})
+		})
+
+		context("when the artifact alone exceeds the maximum size", func() {
+			it.Before(func() {
+				cache = postal.NewDownloadCache(cachePath, 1)
+			})
+
+			it("keeps the artifact", func() {
+				checksum := sha256Checksum("some-content")
+				Expect(cache.Put(checksum, strings.NewReader("some-content"))).To(Succeed())
+
+				_, ok := readEntry(checksum)
+				Expect(ok).To(BeTrue())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the content does not match the checksum", func() {
+				it("returns an error and does not store the artifact", func() {
+					checksum := sha256Checksum("some-content")
+					err := cache.Put(checksum, strings.NewReader("other-content"))
+					Expect(errors.Is(err, cargo.ChecksumValidationError)).To(BeTrue())
+
+					files, err := os.ReadDir(filepath.Join(cachePath, "sha256"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(BeEmpty())
+				})
+			})
+
+			context("when the checksum algorithm is not supported", func() {
+				it("returns an error", func() {
+					err := cache.Put(cargo.Checksum("md5:abc123"), strings.NewReader("some-content"))
+					Expect(errors.Is(err, postal.ErrUncacheableChecksum)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring(`unsupported algorithm "md5"`)))
+				})
+			})
+
+			context("when the cache directory cannot be created", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(cachePath, "sha256"), nil, 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := cache.Put(sha256Checksum("some-content"), strings.NewReader("some-content"))
+					Expect(err).To(MatchError(ContainSubstring("failed to create cache directory")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,58 @@
+package fakes
+
+import (
+	"io"
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+)
+
+type Cache struct {
+	GetCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Checksum cargo.Checksum
+		}
+		Returns struct {
+			ReadCloser io.ReadCloser
+			Bool       bool
+			Error      error
+		}
+		Stub func(cargo.Checksum) (io.ReadCloser, bool, error)
+	}
+	PutCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Checksum cargo.Checksum
+			Reader   io.Reader
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(cargo.Checksum, io.Reader) error
+	}
+}
+
+func (f *Cache) Get(param1 cargo.Checksum) (io.ReadCloser, bool, error) {
+	f.GetCall.mutex.Lock()
+	defer f.GetCall.mutex.Unlock()
+	f.GetCall.CallCount++
+	f.GetCall.Receives.Checksum = param1
+	if f.GetCall.Stub != nil {
+		return f.GetCall.Stub(param1)
+	}
+	return f.GetCall.Returns.ReadCloser, f.GetCall.Returns.Bool, f.GetCall.Returns.Error
+}
+func (f *Cache) Put(param1 cargo.Checksum, param2 io.Reader) error {
+	f.PutCall.mutex.Lock()
+	defer f.PutCall.mutex.Unlock()
+	f.PutCall.CallCount++
+	f.PutCall.Receives.Checksum = param1
+	f.PutCall.Receives.Reader = param2
+	if f.PutCall.Stub != nil {
+		return f.PutCall.Stub(param1, param2)
+	}
+	return f.PutCall.Returns.Error
+}
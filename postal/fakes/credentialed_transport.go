@@ -0,0 +1,62 @@
+package fakes
+
+import (
+	"io"
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+)
+
+type CredentialedTransport struct {
+	DropCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Root string
+			Uri  string
+		}
+		Returns struct {
+			ReadCloser io.ReadCloser
+			Error      error
+		}
+		Stub func(string, string) (io.ReadCloser, error)
+	}
+	DropWithCredentialsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Root        string
+			Uri         string
+			Credentials cargo.Credentials
+		}
+		Returns struct {
+			ReadCloser io.ReadCloser
+			Error      error
+		}
+		Stub func(string, string, cargo.Credentials) (io.ReadCloser, error)
+	}
+}
+
+func (f *CredentialedTransport) Drop(param1 string, param2 string) (io.ReadCloser, error) {
+	f.DropCall.mutex.Lock()
+	defer f.DropCall.mutex.Unlock()
+	f.DropCall.CallCount++
+	f.DropCall.Receives.Root = param1
+	f.DropCall.Receives.Uri = param2
+	if f.DropCall.Stub != nil {
+		return f.DropCall.Stub(param1, param2)
+	}
+	return f.DropCall.Returns.ReadCloser, f.DropCall.Returns.Error
+}
+func (f *CredentialedTransport) DropWithCredentials(param1 string, param2 string, param3 cargo.Credentials) (io.ReadCloser, error) {
+	f.DropWithCredentialsCall.mutex.Lock()
+	defer f.DropWithCredentialsCall.mutex.Unlock()
+	f.DropWithCredentialsCall.CallCount++
+	f.DropWithCredentialsCall.Receives.Root = param1
+	f.DropWithCredentialsCall.Receives.Uri = param2
+	f.DropWithCredentialsCall.Receives.Credentials = param3
+	if f.DropWithCredentialsCall.Stub != nil {
+		return f.DropWithCredentialsCall.Stub(param1, param2, param3)
+	}
+	return f.DropWithCredentialsCall.Returns.ReadCloser, f.DropWithCredentialsCall.Returns.Error
+}
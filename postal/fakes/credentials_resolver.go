@@ -0,0 +1,35 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+)
+
+type CredentialsResolver struct {
+	FindDependencyCredentialsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Uri         string
+			PlatformDir string
+		}
+		Returns struct {
+			Credentials cargo.Credentials
+			Error       error
+		}
+		Stub func(string, string) (cargo.Credentials, error)
+	}
+}
+
+func (f *CredentialsResolver) FindDependencyCredentials(param1 string, param2 string) (cargo.Credentials, error) {
+	f.FindDependencyCredentialsCall.mutex.Lock()
+	defer f.FindDependencyCredentialsCall.mutex.Unlock()
+	f.FindDependencyCredentialsCall.CallCount++
+	f.FindDependencyCredentialsCall.Receives.Uri = param1
+	f.FindDependencyCredentialsCall.Receives.PlatformDir = param2
+	if f.FindDependencyCredentialsCall.Stub != nil {
+		return f.FindDependencyCredentialsCall.Stub(param1, param2)
+	}
+	return f.FindDependencyCredentialsCall.Returns.Credentials, f.FindDependencyCredentialsCall.Returns.Error
+}
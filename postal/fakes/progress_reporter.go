@@ -0,0 +1,28 @@
+package fakes
+
+import "sync"
+
+type ProgressReporter struct {
+	ProgressCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Entry string
+			Read  int64
+			Total int64
+		}
+		Stub func(string, int64, int64)
+	}
+}
+
+func (f *ProgressReporter) Progress(param1 string, param2 int64, param3 int64) {
+	f.ProgressCall.mutex.Lock()
+	defer f.ProgressCall.mutex.Unlock()
+	f.ProgressCall.CallCount++
+	f.ProgressCall.Receives.Entry = param1
+	f.ProgressCall.Receives.Read = param2
+	f.ProgressCall.Receives.Total = param3
+	if f.ProgressCall.Stub != nil {
+		f.ProgressCall.Stub(param1, param2, param3)
+	}
+}
@@ -0,0 +1,33 @@
+package fakes
+
+import "sync"
+
+type SigningKeyResolver struct {
+	FindSigningKeyCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			KeyID       string
+			CnbPath     string
+			PlatformDir string
+		}
+		Returns struct {
+			ByteSlice []byte
+			Error     error
+		}
+		Stub func(string, string, string) ([]byte, error)
+	}
+}
+
+func (f *SigningKeyResolver) FindSigningKey(param1 string, param2 string, param3 string) ([]byte, error) {
+	f.FindSigningKeyCall.mutex.Lock()
+	defer f.FindSigningKeyCall.mutex.Unlock()
+	f.FindSigningKeyCall.CallCount++
+	f.FindSigningKeyCall.Receives.KeyID = param1
+	f.FindSigningKeyCall.Receives.CnbPath = param2
+	f.FindSigningKeyCall.Receives.PlatformDir = param3
+	if f.FindSigningKeyCall.Stub != nil {
+		return f.FindSigningKeyCall.Stub(param1, param2, param3)
+	}
+	return f.FindSigningKeyCall.Returns.ByteSlice, f.FindSigningKeyCall.Returns.Error
+}
@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+)
+
+const credentialsHeaderPrefix = "header."
+
+type DependencyCredentialsResolver struct {
+	bindingResolver BindingResolver
+}
+
+func NewDependencyCredentialsResolver(bindingResolver BindingResolver) DependencyCredentialsResolver {
+	return DependencyCredentialsResolver{
+		bindingResolver: bindingResolver,
+	}
+}
+
+// FindDependencyCredentials looks up the credentials for the host of the
+// given uri in the `dependency-credentials` bindings. Each binding must have a
+// `host` entry that is either a hostname, a hostname and port, or a wildcard
+// of the form `*.example.com`. A binding for the exact host takes precedence
+// over a wildcard binding. The binding provides credentials using the
+// `username` and `password` entries, the `token` entry, and any number of
+// `header.<name>` entries.
+//
+// Errors returned by this function never contain the credential values.
+func (d DependencyCredentialsResolver) FindDependencyCredentials(uri, platformDir string) (cargo.Credentials, error) {
+	bindings, err := d.bindingResolver.Resolve("dependency-credentials", "", platformDir)
+	if err != nil {
+		return cargo.Credentials{}, fmt.Errorf("failed to resolve 'dependency-credentials' binding: %w", err)
+	}
+
+	if len(bindings) == 0 {
+		return cargo.Credentials{}, nil
+	}
+
+	uriURL, err := url.Parse(uri)
+	if err != nil {
+		return cargo.Credentials{}, err
+	}
+
+	var match *servicebindings.Binding
+	for i, binding := range bindings {
+		entry, ok := binding.Entries["host"]
+		if !ok {
+			return cargo.Credentials{}, fmt.Errorf("binding %q is missing required entry 'host'", binding.Name)
+		}
+
+		host, err := entry.ReadString()
+		if err != nil {
+			return cargo.Credentials{}, err
+		}
+		host = strings.TrimSpace(host)
+
+		if hostMatches(host, uriURL) {
+			match = &bindings[i]
+			break
+		}
+
+		if match == nil && wildcardHostMatches(host, uriURL) {
+			match = &bindings[i]
+		}
+	}
+
+	if match == nil {
+		return cargo.Credentials{}, nil
+	}
+
+	return loadCredentials(*match)
+}
+
+func hostMatches(host string, uri *url.URL) bool {
+	if strings.Contains(host, ":") {
+		return strings.EqualFold(host, uri.Host)
+	}
+
+	return strings.EqualFold(host, uri.Hostname())
+}
+
+func wildcardHostMatches(host string, uri *url.URL) bool {
+	if !strings.HasPrefix(host, "*.") {
+		return false
+	}
+
+	return strings.HasSuffix(strings.ToLower(uri.Hostname()), strings.ToLower(host[1:]))
+}
+
+func loadCredentials(binding servicebindings.Binding) (cargo.Credentials, error) {
+	var credentials cargo.Credentials
+
+	for name, entry := range binding.Entries {
+		var target *string
+		switch {
+		case name == "username":
+			target = &credentials.Username
+		case name == "password":
+			target = &credentials.Password
+		case name == "token":
+			target = &credentials.Token
+		case strings.HasPrefix(name, credentialsHeaderPrefix):
+			if credentials.Headers == nil {
+				credentials.Headers = map[string]string{}
+			}
+
+			value, err := entry.ReadString()
+			if err != nil {
+				return cargo.Credentials{}, fmt.Errorf("failed to read entry %q of binding %q: %w", name, binding.Name, err)
+			}
+
+			credentials.Headers[strings.TrimPrefix(name, credentialsHeaderPrefix)] = strings.TrimSpace(value)
+			continue
+		default:
+			continue
+		}
+
+		value, err := entry.ReadString()
+		if err != nil {
+			return cargo.Credentials{}, fmt.Errorf("failed to read entry %q of binding %q: %w", name, binding.Name, err)
+		}
+
+		*target = strings.TrimSpace(value)
+	}
+
+	if credentials.Token != "" && (credentials.Username != "" || credentials.Password != "") {
+		return cargo.Credentials{}, fmt.Errorf("binding %q must provide either 'token' or 'username' and 'password', not both", binding.Name)
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,158 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/postal/internal"
+	"github.com/paketo-buildpacks/packit/v2/postal/internal/fakes"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDependencyCredentials(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect          = NewWithT(t).Expect
+		resolver        internal.DependencyCredentialsResolver
+		bindingResolver *fakes.BindingResolver
+	)
+
+	it.Before(func() {
+		bindingResolver = &fakes.BindingResolver{}
+		resolver = internal.NewDependencyCredentialsResolver(bindingResolver)
+
+		bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{
+			{
+				Name: "basic-binding",
+				Type: "dependency-credentials",
+				Entries: map[string]*servicebindings.Entry{
+					"host":     servicebindings.NewWithValue([]byte("artifacts.example.com\n")),
+					"username": servicebindings.NewWithValue([]byte("some-username")),
+					"password": servicebindings.NewWithValue([]byte("some-password\n")),
+				},
+			},
+			{
+				Name: "wildcard-binding",
+				Type: "dependency-credentials",
+				Entries: map[string]*servicebindings.Entry{
+					"host":             servicebindings.NewWithValue([]byte("*.example.com")),
+					"token":            servicebindings.NewWithValue([]byte("some-token")),
+					"header.X-Api-Key": servicebindings.NewWithValue([]byte("some-api-key")),
+				},
+			},
+			{
+				Name: "port-binding",
+				Type: "dependency-credentials",
+				Entries: map[string]*servicebindings.Entry{
+					"host":  servicebindings.NewWithValue([]byte("mirror.example.org:8443")),
+					"token": servicebindings.NewWithValue([]byte("other-token")),
+				},
+			},
+		}
+	})
+
+	context("FindDependencyCredentials", func() {
+		it("finds the credentials for the host of the uri", func() {
+			credentials, err := resolver.FindDependencyCredentials("https://artifacts.example.com/some-dep.tgz", "some-platform-dir")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(credentials).To(Equal(cargo.Credentials{
+				Username: "some-username",
+				Password: "some-password",
+			}))
+
+			Expect(bindingResolver.ResolveCall.Receives.Typ).To(Equal("dependency-credentials"))
+			Expect(bindingResolver.ResolveCall.Receives.Provider).To(BeEmpty())
+			Expect(bindingResolver.ResolveCall.Receives.PlatformDir).To(Equal("some-platform-dir"))
+		})
+
+		context("when the host matches a wildcard", func() {
+			it("returns the token and headers", func() {
+				credentials, err := resolver.FindDependencyCredentials("https://other.example.com/some-dep.tgz", "some-platform-dir")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(credentials).To(Equal(cargo.Credentials{
+					Token: "some-token",
+					Headers: map[string]string{
+						"X-Api-Key": "some-api-key",
+					},
+				}))
+			})
+		})
+
+		context("when the binding host includes a port", func() {
+			it("only matches uris with the same port", func() {
+				credentials, err := resolver.FindDependencyCredentials("https://mirror.example.org:8443/some-dep.tgz", "some-platform-dir")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(credentials.Token).To(Equal("other-token"))
+
+				credentials, err = resolver.FindDependencyCredentials("https://mirror.example.org/some-dep.tgz", "some-platform-dir")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(credentials.IsZero()).To(BeTrue())
+			})
+		})
+
+		context("when no binding matches the host", func() {
+			it("returns empty credentials", func() {
+				credentials, err := resolver.FindDependencyCredentials("https://example.net/some-dep.tgz", "some-platform-dir")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(credentials.IsZero()).To(BeTrue())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the binding resolver fails", func() {
+				it.Before(func() {
+					bindingResolver.ResolveCall.Returns.Error = errors.New("some-error")
+				})
+
+				it("returns an error", func() {
+					_, err := resolver.FindDependencyCredentials("https://artifacts.example.com/some-dep.tgz", "some-platform-dir")
+					Expect(err).To(MatchError("failed to resolve 'dependency-credentials' binding: some-error"))
+				})
+			})
+
+			context("when a binding is missing the host entry", func() {
+				it.Before(func() {
+					bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{
+						{
+							Name: "some-binding",
+							Type: "dependency-credentials",
+							Entries: map[string]*servicebindings.Entry{
+								"token": servicebindings.NewWithValue([]byte("some-token")),
+							},
+						},
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := resolver.FindDependencyCredentials("https://artifacts.example.com/some-dep.tgz", "some-platform-dir")
+					Expect(err).To(MatchError(`binding "some-binding" is missing required entry 'host'`))
+				})
+			})
+
+			context("when a binding provides both a token and a username", func() {
+				it.Before(func() {
+					bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{
+						{
+							Name: "some-binding",
+							Type: "dependency-credentials",
+							Entries: map[string]*servicebindings.Entry{
+								"host":     servicebindings.NewWithValue([]byte("artifacts.example.com")),
+								"token":    servicebindings.NewWithValue([]byte("some-token")),
+								"username": servicebindings.NewWithValue([]byte("some-username")),
+							},
+						},
+					}
+				})
+
+				it("returns an error that does not contain the credentials", func() {
+					_, err := resolver.FindDependencyCredentials("https://artifacts.example.com/some-dep.tgz", "some-platform-dir")
+					Expect(err).To(MatchError(`binding "some-binding" must provide either 'token' or 'username' and 'password', not both`))
+					Expect(err.Error()).NotTo(ContainSubstring("some-token"))
+				})
+			})
+		})
+	})
+}
@@ -9,8 +9,11 @@ import (
 
 func TestUnitPostalInternal(t *testing.T) {
 	suite := spec.New("packit/postal/internal", spec.Report(report.Terminal{}))
+	suite("DependencyCredentials", testDependencyCredentials)
 	suite("DependencyMappings", testDependencyMappings)
 	suite("DependencyMirror", testDependencyMirror)
+	suite("Signature", testSignature)
+	suite("SigningKeys", testSigningKeys)
 
 	suite.Run(t)
 }
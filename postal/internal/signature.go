@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrSignatureMismatch is returned by VerifySignature when the signature is
+// well formed but was not produced by the given key for the given artifact.
+var ErrSignatureMismatch = errors.New("signature does not match")
+
+// VerifySignature verifies a detached signature of an artifact against a
+// public key. The format of the signature is determined by the format of the
+// key:
+//
+//   - A PEM encoded "PUBLIC KEY" verifies a base64 encoded signature in the
+//     style of `cosign sign-blob`. ECDSA and RSA (PKCS #1 v1.5) signatures are
+//     made over the SHA-256 digest of the artifact, Ed25519 signatures are made
+//     over the artifact itself.
+//   - An OpenPGP public key, armored or binary, verifies an armored or binary
+//     GPG detached signature.
+//   - A minisign public key verifies a minisign signature, including the
+//     signature of its trusted comment.
+func VerifySignature(key, signature []byte, artifact io.Reader) error {
+	switch {
+	case bytes.Contains(key, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")):
+		keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+		if err != nil {
+			return fmt.Errorf("failed to parse GPG public key: %w", err)
+		}
+
+		return verifyGPG(keyring, signature, artifact)
+
+	case bytes.Contains(key, []byte("-----BEGIN PUBLIC KEY-----")):
+		block, _ := pem.Decode(key)
+		if block == nil {
+			return errors.New("failed to parse PEM public key: no PEM data found")
+		}
+
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse PEM public key: %w", err)
+		}
+
+		return verifyPublicKey(publicKey, signature, artifact)
+
+	case isMinisign(key):
+		return verifyMinisign(key, signature, artifact)
+	}
+
+	keyring, err := openpgp.ReadKeyRing(bytes.NewReader(key))
+	if err != nil {
+		return errors.New("failed to parse public key: the key is not a PEM, GPG or minisign public key")
+	}
+
+	return verifyGPG(keyring, signature, artifact)
+}
+
+func verifyGPG(keyring openpgp.EntityList, signature []byte, artifact io.Reader) error {
+	var err error
+	if bytes.Contains(signature, []byte("-----BEGIN PGP SIGNATURE-----")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, artifact, bytes.NewReader(signature), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, artifact, bytes.NewReader(signature), nil)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrSignatureMismatch, err)
+	}
+
+	return nil
+}
+
+func verifyPublicKey(publicKey crypto.PublicKey, signature []byte, artifact io.Reader) error {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		// The signature is not base64 encoded, so it is assumed to be raw.
+		decoded = signature
+	}
+
+	if key, ok := publicKey.(ed25519.PublicKey); ok {
+		content, err := io.ReadAll(artifact)
+		if err != nil {
+			return fmt.Errorf("failed to read artifact: %w", err)
+		}
+
+		if !ed25519.Verify(key, content, decoded) {
+			return ErrSignatureMismatch
+		}
+
+		return nil
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, artifact)
+	if err != nil {
+		return fmt.Errorf("failed to read artifact: %w", err)
+	}
+	digest := hash.Sum(nil)
+
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest, decoded) {
+			return ErrSignatureMismatch
+		}
+
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, decoded) != nil {
+			return ErrSignatureMismatch
+		}
+
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	return nil
+}
+
+const (
+	minisignAlgorithm         = "Ed"
+	minisignPrehashAlgorithm  = "ED"
+	minisignKeyIDLength       = 8
+	minisignTrustedCommentTag = "trusted comment: "
+)
+
+// minisignLines returns the lines of a minisign file without the untrusted
+// comment.
+func minisignLines(content []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "untrusted comment:") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func isMinisign(key []byte) bool {
+	lines := minisignLines(key)
+	if len(lines) != 1 {
+		return false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(lines[0])
+	return err == nil && len(decoded) == 2+minisignKeyIDLength+ed25519.PublicKeySize && string(decoded[:2]) == minisignAlgorithm
+}
+
+func verifyMinisign(key, signature []byte, artifact io.Reader) error {
+	decodedKey, err := base64.StdEncoding.DecodeString(minisignLines(key)[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse minisign public key: %w", err)
+	}
+	keyID := decodedKey[2 : 2+minisignKeyIDLength]
+	publicKey := ed25519.PublicKey(decodedKey[2+minisignKeyIDLength:])
+
+	lines := minisignLines(signature)
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], minisignTrustedCommentTag) {
+		return errors.New("failed to parse minisign signature: expected a signature, trusted comment and global signature")
+	}
+
+	decodedSignature, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(decodedSignature) != 2+minisignKeyIDLength+ed25519.SignatureSize {
+		return errors.New("failed to parse minisign signature: malformed signature")
+	}
+
+	algorithm := string(decodedSignature[:2])
+	if algorithm != minisignAlgorithm && algorithm != minisignPrehashAlgorithm {
+		return fmt.Errorf("failed to parse minisign signature: unsupported algorithm %q", algorithm)
+	}
+
+	if !bytes.Equal(decodedSignature[2:2+minisignKeyIDLength], keyID) {
+		return fmt.Errorf("%w: signature was made with a different key", ErrSignatureMismatch)
+	}
+	sig := decodedSignature[2+minisignKeyIDLength:]
+
+	var message []byte
+	if algorithm == minisignPrehashAlgorithm {
+		hash, err := blake2b.New512(nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(hash, artifact)
+		if err != nil {
+			return fmt.Errorf("failed to read artifact: %w", err)
+		}
+
+		message = hash.Sum(nil)
+	} else {
+		message, err = io.ReadAll(artifact)
+		if err != nil {
+			return fmt.Errorf("failed to read artifact: %w", err)
+		}
+	}
+
+	if !ed25519.Verify(publicKey, message, sig) {
+		return ErrSignatureMismatch
+	}
+
+	globalSignature, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil {
+		return errors.New("failed to parse minisign signature: malformed global signature")
+	}
+
+	trustedComment := strings.TrimPrefix(lines[1], minisignTrustedCommentTag)
+	if !ed25519.Verify(publicKey, append(append([]byte{}, sig...), trustedComment...), globalSignature) {
+		return fmt.Errorf("%w: trusted comment signature is invalid", ErrSignatureMismatch)
+	}
+
+	return nil
+}
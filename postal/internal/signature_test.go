@@ -0,0 +1,238 @@
+package internal_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/paketo-buildpacks/packit/v2/postal/internal"
+	"github.com/sclevine/spec"
+	"golang.org/x/crypto/blake2b"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSignature(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		artifact []byte
+	)
+
+	pemPublicKey := func(publicKey crypto.PublicKey) []byte {
+		der, err := x509.MarshalPKIXPublicKey(publicKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+
+	it.Before(func() {
+		artifact = []byte("some-artifact-content")
+	})
+
+	context("VerifySignature", func() {
+		context("when the key is a PEM encoded ECDSA public key", func() {
+			var (
+				key       []byte
+				signature []byte
+			)
+
+			it.Before(func() {
+				privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				digest := sha256.Sum256(artifact)
+				sig, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+				Expect(err).NotTo(HaveOccurred())
+
+				key = pemPublicKey(&privateKey.PublicKey)
+				signature = []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+			})
+
+			it("verifies the signature", func() {
+				Expect(internal.VerifySignature(key, signature, bytes.NewReader(artifact))).To(Succeed())
+			})
+
+			it("rejects a signature of different content", func() {
+				err := internal.VerifySignature(key, signature, strings.NewReader("other-content"))
+				Expect(errors.Is(err, internal.ErrSignatureMismatch)).To(BeTrue())
+			})
+		})
+
+		context("when the key is a PEM encoded RSA public key", func() {
+			it("verifies the signature", func() {
+				privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+				Expect(err).NotTo(HaveOccurred())
+
+				digest := sha256.Sum256(artifact)
+				sig, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+				Expect(err).NotTo(HaveOccurred())
+
+				key := pemPublicKey(&privateKey.PublicKey)
+				Expect(internal.VerifySignature(key, sig, bytes.NewReader(artifact))).To(Succeed())
+
+				err = internal.VerifySignature(key, sig, strings.NewReader("other-content"))
+				Expect(errors.Is(err, internal.ErrSignatureMismatch)).To(BeTrue())
+			})
+		})
+
+		context("when the key is a PEM encoded Ed25519 public key", func() {
+			it("verifies the signature", func() {
+				publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, artifact)))
+
+				key := pemPublicKey(publicKey)
+				Expect(internal.VerifySignature(key, signature, bytes.NewReader(artifact))).To(Succeed())
+
+				err = internal.VerifySignature(key, signature, strings.NewReader("other-content"))
+				Expect(errors.Is(err, internal.ErrSignatureMismatch)).To(BeTrue())
+			})
+		})
+
+		context("when the key is a GPG public key", func() {
+			var (
+				entity *openpgp.Entity
+				key    []byte
+			)
+
+			it.Before(func() {
+				var err error
+				entity, err = openpgp.NewEntity("Some Name", "", "some-name@example.com", nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				buffer := bytes.NewBuffer(nil)
+				writer, err := armor.Encode(buffer, openpgp.PublicKeyType, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entity.Serialize(writer)).To(Succeed())
+				Expect(writer.Close()).To(Succeed())
+
+				key = buffer.Bytes()
+			})
+
+			it("verifies an armored signature", func() {
+				signature := bytes.NewBuffer(nil)
+				Expect(openpgp.ArmoredDetachSign(signature, entity, bytes.NewReader(artifact), nil)).To(Succeed())
+
+				Expect(internal.VerifySignature(key, signature.Bytes(), bytes.NewReader(artifact))).To(Succeed())
+
+				err := internal.VerifySignature(key, signature.Bytes(), strings.NewReader("other-content"))
+				Expect(errors.Is(err, internal.ErrSignatureMismatch)).To(BeTrue())
+			})
+
+			it("verifies a binary signature with a binary key", func() {
+				signature := bytes.NewBuffer(nil)
+				Expect(openpgp.DetachSign(signature, entity, bytes.NewReader(artifact), nil)).To(Succeed())
+
+				binaryKey := bytes.NewBuffer(nil)
+				Expect(entity.Serialize(binaryKey)).To(Succeed())
+
+				Expect(internal.VerifySignature(binaryKey.Bytes(), signature.Bytes(), bytes.NewReader(artifact))).To(Succeed())
+			})
+		})
+
+		context("when the key is a minisign public key", func() {
+			var (
+				privateKey ed25519.PrivateKey
+				keyID      []byte
+				key        []byte
+			)
+
+			minisign := func(algorithm string, content []byte, trustedComment string) []byte {
+				message := content
+				if algorithm == "ED" {
+					sum := blake2b.Sum512(content)
+					message = sum[:]
+				}
+
+				sig := ed25519.Sign(privateKey, message)
+				global := ed25519.Sign(privateKey, append(append([]byte{}, sig...), trustedComment...))
+
+				return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+					base64.StdEncoding.EncodeToString(append(append([]byte(algorithm), keyID...), sig...)),
+					trustedComment,
+					base64.StdEncoding.EncodeToString(global),
+				))
+			}
+
+			it.Before(func() {
+				var publicKey ed25519.PublicKey
+				var err error
+				publicKey, privateKey, err = ed25519.GenerateKey(rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				keyID = []byte("somekey1")
+				key = []byte(fmt.Sprintf("untrusted comment: minisign public key\n%s\n",
+					base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), publicKey...)),
+				))
+			})
+
+			it("verifies a prehashed signature", func() {
+				signature := minisign("ED", artifact, "timestamp:1234567890")
+				Expect(internal.VerifySignature(key, signature, bytes.NewReader(artifact))).To(Succeed())
+
+				err := internal.VerifySignature(key, signature, strings.NewReader("other-content"))
+				Expect(errors.Is(err, internal.ErrSignatureMismatch)).To(BeTrue())
+			})
+
+			it("verifies a legacy signature", func() {
+				signature := minisign("Ed", artifact, "timestamp:1234567890")
+				Expect(internal.VerifySignature(key, signature, bytes.NewReader(artifact))).To(Succeed())
+			})
+
+			it("rejects a signature with a tampered trusted comment", func() {
+				signature := minisign("ED", artifact, "timestamp:1234567890")
+				signature = bytes.Replace(signature, []byte("timestamp:1234567890"), []byte("timestamp:0"), 1)
+
+				err := internal.VerifySignature(key, signature, bytes.NewReader(artifact))
+				Expect(err).To(MatchError("signature does not match: trusted comment signature is invalid"))
+			})
+
+			it("rejects a signature made with a different key", func() {
+				keyID = []byte("otherkey")
+				signature := minisign("ED", artifact, "timestamp:1234567890")
+
+				err := internal.VerifySignature(key, signature, bytes.NewReader(artifact))
+				Expect(err).To(MatchError("signature does not match: signature was made with a different key"))
+			})
+
+			context("when the signature is malformed", func() {
+				it("returns an error", func() {
+					err := internal.VerifySignature(key, []byte("not a signature"), bytes.NewReader(artifact))
+					Expect(err).To(MatchError("failed to parse minisign signature: expected a signature, trusted comment and global signature"))
+				})
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the key is not a known format", func() {
+				it("returns an error", func() {
+					err := internal.VerifySignature([]byte("not a key"), []byte("some-signature"), bytes.NewReader(artifact))
+					Expect(err).To(MatchError("failed to parse public key: the key is not a PEM, GPG or minisign public key"))
+				})
+			})
+
+			context("when the PEM key is malformed", func() {
+				it("returns an error", func() {
+					err := internal.VerifySignature([]byte("-----BEGIN PUBLIC KEY-----\nnot-base64\n-----END PUBLIC KEY-----\n"), []byte("some-signature"), bytes.NewReader(artifact))
+					Expect(err).To(MatchError(ContainSubstring("failed to parse PEM public key")))
+				})
+			})
+		})
+	})
+}
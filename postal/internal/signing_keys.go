@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type SigningKeyResolver struct {
+	bindingResolver BindingResolver
+}
+
+func NewSigningKeyResolver(bindingResolver BindingResolver) SigningKeyResolver {
+	return SigningKeyResolver{
+		bindingResolver: bindingResolver,
+	}
+}
+
+// FindSigningKey looks up the public key with the given key ID. Keys supplied
+// by a `dependency-signing-keys` binding, as an entry named after the key ID,
+// take precedence over keys shipped in the buildpack at
+// `<cnbPath>/signing-keys/<key ID>`.
+func (s SigningKeyResolver) FindSigningKey(keyID, cnbPath, platformDir string) ([]byte, error) {
+	if keyID == "" || strings.ContainsAny(keyID, `/\`) || keyID == "." || keyID == ".." {
+		return nil, fmt.Errorf("invalid signing key ID %q", keyID)
+	}
+
+	bindings, err := s.bindingResolver.Resolve("dependency-signing-keys", "", platformDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve 'dependency-signing-keys' binding: %w", err)
+	}
+
+	for _, binding := range bindings {
+		if entry, ok := binding.Entries[keyID]; ok {
+			return entry.ReadBytes()
+		}
+	}
+
+	key, err := os.ReadFile(filepath.Join(cnbPath, "signing-keys", keyID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("could not find signing key %q", keyID)
+		}
+
+		return nil, err
+	}
+
+	return key, nil
+}
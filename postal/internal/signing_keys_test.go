@@ -0,0 +1,98 @@
+package internal_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/postal/internal"
+	"github.com/paketo-buildpacks/packit/v2/postal/internal/fakes"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSigningKeys(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect          = NewWithT(t).Expect
+		cnbPath         string
+		resolver        internal.SigningKeyResolver
+		bindingResolver *fakes.BindingResolver
+	)
+
+	it.Before(func() {
+		var err error
+		cnbPath, err = os.MkdirTemp("", "cnb")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(cnbPath, "signing-keys"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cnbPath, "signing-keys", "some-key-id"), []byte("buildpack-key"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cnbPath, "signing-keys", "other-key-id"), []byte("other-buildpack-key"), 0600)).To(Succeed())
+
+		bindingResolver = &fakes.BindingResolver{}
+		bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{
+			{
+				Name: "some-binding",
+				Type: "dependency-signing-keys",
+				Entries: map[string]*servicebindings.Entry{
+					"other-key-id": servicebindings.NewWithValue([]byte("binding-key")),
+				},
+			},
+		}
+
+		resolver = internal.NewSigningKeyResolver(bindingResolver)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(cnbPath)).To(Succeed())
+	})
+
+	context("FindSigningKey", func() {
+		it("finds the key shipped in the buildpack", func() {
+			key, err := resolver.FindSigningKey("some-key-id", cnbPath, "some-platform-dir")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(key)).To(Equal("buildpack-key"))
+
+			Expect(bindingResolver.ResolveCall.Receives.Typ).To(Equal("dependency-signing-keys"))
+			Expect(bindingResolver.ResolveCall.Receives.Provider).To(BeEmpty())
+			Expect(bindingResolver.ResolveCall.Receives.PlatformDir).To(Equal("some-platform-dir"))
+		})
+
+		context("when the key is supplied by a binding", func() {
+			it("prefers the key from the binding", func() {
+				key, err := resolver.FindSigningKey("other-key-id", cnbPath, "some-platform-dir")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(key)).To(Equal("binding-key"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the key cannot be found", func() {
+				it("returns an error", func() {
+					_, err := resolver.FindSigningKey("missing-key-id", cnbPath, "some-platform-dir")
+					Expect(err).To(MatchError(`could not find signing key "missing-key-id"`))
+				})
+			})
+
+			context("when the key ID is a path", func() {
+				it("returns an error", func() {
+					_, err := resolver.FindSigningKey("../some-key-id", cnbPath, "some-platform-dir")
+					Expect(err).To(MatchError(`invalid signing key ID "../some-key-id"`))
+				})
+			})
+
+			context("when the binding resolver fails", func() {
+				it.Before(func() {
+					bindingResolver.ResolveCall.Returns.Error = errors.New("some-error")
+				})
+
+				it("returns an error", func() {
+					_, err := resolver.FindSigningKey("some-key-id", cnbPath, "some-platform-dir")
+					Expect(err).To(MatchError("failed to resolve 'dependency-signing-keys' binding: some-error"))
+				})
+			})
+		})
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -45,6 +47,42 @@ type MirrorResolver interface {
 	FindDependencyMirror(uri, platformDir string) (string, error)
 }
 
+// CredentialsResolver serves as the interface that looks up the credentials
+// needed to fetch a dependency from a given uri via binding
+//
+//go:generate faux --interface CredentialsResolver --output fakes/credentials_resolver.go
+type CredentialsResolver interface {
+	FindDependencyCredentials(uri, platformDir string) (cargo.Credentials, error)
+}
+
+// SigningKeyResolver serves as the interface that looks up the public key
+// used to verify the signature of a dependency given a key ID
+//
+//go:generate faux --interface SigningKeyResolver --output fakes/signing_key_resolver.go
+type SigningKeyResolver interface {
+	FindSigningKey(keyID, cnbPath, platformDir string) ([]byte, error)
+}
+
+// CredentialedTransport serves as the interface for Transports that are able
+// to attach credentials to the requests that they make. Credentials are only
+// ever given to Transports that implement this interface.
+//
+//go:generate faux --interface CredentialedTransport --output fakes/credentialed_transport.go
+type CredentialedTransport interface {
+	Transport
+	DropWithCredentials(root, uri string, credentials cargo.Credentials) (io.ReadCloser, error)
+}
+
+// ProgressReporter serves as the interface for types that receive updates on
+// the progress of extracting a dependency. Progress is called with the current
+// entry in the dependency archive, the number of bytes of the dependency that
+// have been read, and its total size, or -1 when it is not known.
+//
+//go:generate faux --interface ProgressReporter --output fakes/progress_reporter.go
+type ProgressReporter interface {
+	Progress(entry string, read, total int64)
+}
+
 // ErrNoDeps is a typed error indicating that no dependencies were resolved during Service.Resolve()
 //
 // errors can be tested against this type with: errors.As()
@@ -52,11 +90,21 @@ type ErrNoDeps struct {
 	id                string
 	version           string
 	stack             string
+	target            string
 	supportedVersions []string
 }
 
 // Error implements the error.Error interface
 func (e *ErrNoDeps) Error() string {
+	if e.target != "" {
+		return fmt.Sprintf("failed to satisfy %q dependency version constraint %q: no compatible versions for %q target. Supported versions are: [%s]",
+			e.id,
+			e.version,
+			e.target,
+			strings.Join(e.supportedVersions, ", "),
+		)
+	}
+
 	return fmt.Sprintf("failed to satisfy %q dependency version constraint %q: no compatible versions on %q stack. Supported versions are: [%s]",
 		e.id,
 		e.version,
@@ -65,12 +113,64 @@ func (e *ErrNoDeps) Error() string {
 	)
 }
 
+// DefaultConcurrency is the number of dependencies that Service.DeliverAll
+// will deliver at the same time unless configured otherwise using
+// Service.WithConcurrency.
+const DefaultConcurrency = 4
+
+// Delivery pairs a dependency with the layer path that it should be
+// delivered into by Service.DeliverAll.
+type Delivery struct {
+	Dependency Dependency
+	LayerPath  string
+}
+
+// DeliveryError is the error returned when a single dependency cannot be
+// delivered during Service.DeliverAll.
+type DeliveryError struct {
+	Delivery Delivery
+	Err      error
+}
+
+// Error implements the error.Error interface
+func (e DeliveryError) Error() string {
+	return fmt.Sprintf("failed to deliver %q dependency version %q: %s", e.Delivery.Dependency.ID, e.Delivery.Dependency.Version, e.Err)
+}
+
+// Unwrap returns the underlying error that caused the delivery to fail.
+func (e DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// DeliveryErrors is a typed error returned by Service.DeliverAll that contains
+// an error for each dependency that could not be delivered, in the order that
+// the deliveries were given.
+//
+// errors can be tested against this type with: errors.As()
+type DeliveryErrors []DeliveryError
+
+// Error implements the error.Error interface
+func (e DeliveryErrors) Error() string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
 // Service provides a mechanism for resolving and installing dependencies given
 // a Transport.
 type Service struct {
-	transport       Transport
-	mappingResolver MappingResolver
-	mirrorResolver  MirrorResolver
+	transport           Transport
+	mappingResolver     MappingResolver
+	mirrorResolver      MirrorResolver
+	credentialsResolver CredentialsResolver
+	signingKeyResolver  SigningKeyResolver
+	cache               Cache
+	concurrency         int
+	linkPolicy          vacation.LinkPolicy
+	progress            ProgressReporter
 }
 
 // NewService creates an instance of a Service given a Transport.
@@ -83,6 +183,13 @@ func NewService(transport Transport) Service {
 		mirrorResolver: internal.NewDependencyMirrorResolver(
 			servicebindings.NewResolver(),
 		),
+		credentialsResolver: internal.NewDependencyCredentialsResolver(
+			servicebindings.NewResolver(),
+		),
+		signingKeyResolver: internal.NewSigningKeyResolver(
+			servicebindings.NewResolver(),
+		),
+		concurrency: DefaultConcurrency,
 	}
 }
 
@@ -96,6 +203,47 @@ func (s Service) WithDependencyMirrorResolver(mirrorResolver MirrorResolver) Ser
 	return s
 }
 
+func (s Service) WithDependencyCredentialsResolver(credentialsResolver CredentialsResolver) Service {
+	s.credentialsResolver = credentialsResolver
+	return s
+}
+
+func (s Service) WithSigningKeyResolver(signingKeyResolver SigningKeyResolver) Service {
+	s.signingKeyResolver = signingKeyResolver
+	return s
+}
+
+// WithCache sets a Cache that Deliver consults before fetching a dependency.
+// Dependencies that are not already present in the cache are stored in it once
+// they have been fetched. Dependencies without a checksum are never cached.
+func (s Service) WithCache(cache Cache) Service {
+	s.cache = cache
+	return s
+}
+
+// WithConcurrency sets the maximum number of dependencies that DeliverAll
+// will fetch and extract at the same time. Values less than 1 are treated as
+// 1.
+func (s Service) WithConcurrency(concurrency int) Service {
+	s.concurrency = concurrency
+	return s
+}
+
+// WithLinkPolicy sets the policy that the symlinks and hard links in a
+// dependency must satisfy when Deliver extracts it.
+func (s Service) WithLinkPolicy(policy vacation.LinkPolicy) Service {
+	s.linkPolicy = policy
+	return s
+}
+
+// WithProgress sets a ProgressReporter that Deliver reports the progress of
+// fetching and extracting a dependency to. A scribe.ProgressLogger prints
+// the progress as it is reported.
+func (s Service) WithProgress(reporter ProgressReporter) Service {
+	s.progress = reporter
+	return s
+}
+
 // Resolve will pick the best matching dependency given a path to a
 // buildpack.toml file, and the id, version, and stack value of a dependency.
 // The version value is treated as a SemVer constraint and will pick the
@@ -109,58 +257,20 @@ func (s Service) Resolve(path, id, version, stack string) (Dependency, error) {
 		return Dependency{}, err
 	}
 
-	if version == "" {
-		version = "default"
-	}
-
-	if version == "default" {
-		version = "*"
-		if defaultVersion != "" {
-			version = defaultVersion
-		}
-	}
-
-	// Handle the pessmistic operator (~>)
-	var re = regexp.MustCompile(`~>`)
-	if re.MatchString(version) {
-		res := re.ReplaceAllString(version, "")
-		parts := strings.Split(res, ".")
-
-		// if the version contains a major, minor, and patch use "~" Tilde Range Comparison
-		// if the version contains a major and minor only, or a major version only use "^" Caret Range Comparison
-		if len(parts) == 3 {
-			version = "~" + res
-		} else {
-			version = "^" + res
-		}
-	}
-
-	var compatibleVersions []Dependency
-	versionConstraint, err := semver.NewConstraint(version)
+	version, versionConstraint, err := parseVersionConstraint(version, defaultVersion)
 	if err != nil {
 		return Dependency{}, err
 	}
 
-	var supportedVersions []string
-	for _, dependency := range dependencies {
-		if dependency.ID != id || !stacksInclude(dependency.Stacks, stack) {
-			continue
-		}
-
-		sVersion, err := semver.NewVersion(dependency.Version)
-		if err != nil {
-			return Dependency{}, err
-		}
-
-		if versionConstraint.Check(sVersion) {
-			compatibleVersions = append(compatibleVersions, dependency)
-		}
-
-		supportedVersions = append(supportedVersions, dependency.Version)
+	compatibleVersions, supportedVersions, err := filterDependencies(dependencies, id, versionConstraint, func(dependency Dependency) bool {
+		return stacksInclude(dependency.Stacks, stack)
+	})
+	if err != nil {
+		return Dependency{}, err
 	}
 
 	if len(compatibleVersions) == 0 {
-		return Dependency{}, &ErrNoDeps{id, version, stack, supportedVersions}
+		return Dependency{}, &ErrNoDeps{id: id, version: version, stack: stack, supportedVersions: supportedVersions}
 	}
 
 	stacksForVersion := map[string][]string{}
@@ -220,6 +330,140 @@ func (s Service) Resolve(path, id, version, stack string) (Dependency, error) {
 	return compatibleVersions[0], nil
 }
 
+// ResolveForTarget will pick the best matching dependency given a path to a
+// buildpack.toml file, and the id, version, and target of a dependency. The
+// version value is handled in the same way as it is by Resolve. Dependencies
+// are matched against the OS, architecture and distribution of the target. A
+// dependency that declares a specific OS, architecture or distribution has
+// higher priority than one that supports any value, in the same way that
+// Resolve prefers a specific stack over the wildcard stack. An error is
+// returned when more than one dependency of the selected version matches the
+// target equally specifically.
+func (s Service) ResolveForTarget(path, id, version string, target packit.Target) (Dependency, error) {
+	dependencies, defaultVersion, err := parseBuildpack(path, id)
+	if err != nil {
+		return Dependency{}, err
+	}
+
+	version, versionConstraint, err := parseVersionConstraint(version, defaultVersion)
+	if err != nil {
+		return Dependency{}, err
+	}
+
+	compatibleVersions, supportedVersions, err := filterDependencies(dependencies, id, versionConstraint, func(dependency Dependency) bool {
+		return targetIncludes(dependency, target)
+	})
+	if err != nil {
+		return Dependency{}, err
+	}
+
+	if len(compatibleVersions) == 0 {
+		return Dependency{}, &ErrNoDeps{id: id, version: version, target: formatTarget(target), supportedVersions: supportedVersions}
+	}
+
+	wildcardsForVersion := map[string]int{}
+	for _, dep := range compatibleVersions {
+		if targetSpecificity(dep, target) == 0 {
+			wildcardsForVersion[dep.Version]++
+		}
+	}
+
+	for version, count := range wildcardsForVersion {
+		if count > 1 {
+			return Dependency{}, fmt.Errorf("multiple dependencies support wildcard target for version: %q", version)
+		}
+	}
+
+	sort.SliceStable(compatibleVersions, func(i, j int) bool {
+		iDep := compatibleVersions[i]
+		jDep := compatibleVersions[j]
+
+		jVersion := semver.MustParse(jDep.Version)
+		iVersion := semver.MustParse(iDep.Version)
+
+		if !iVersion.Equal(jVersion) {
+			return iVersion.GreaterThan(jVersion)
+		}
+
+		return targetSpecificity(iDep, target) > targetSpecificity(jDep, target)
+	})
+
+	// Dependencies of the selected version that match the target equally
+	// specifically are ambiguous, as there is no way to choose between them.
+	if len(compatibleVersions) > 1 {
+		best, next := compatibleVersions[0], compatibleVersions[1]
+		if semver.MustParse(best.Version).Equal(semver.MustParse(next.Version)) && targetSpecificity(best, target) == targetSpecificity(next, target) {
+			return Dependency{}, fmt.Errorf("multiple dependencies support target %q for version: %q", formatTarget(target), best.Version)
+		}
+	}
+
+	return compatibleVersions[0], nil
+}
+
+// parseVersionConstraint converts the given version into a SemVer constraint,
+// resolving the "default" version and the pessimistic operator (~>). The
+// returned string is the constraint as it was parsed.
+func parseVersionConstraint(version, defaultVersion string) (string, *semver.Constraints, error) {
+	if version == "" {
+		version = "default"
+	}
+
+	if version == "default" {
+		version = "*"
+		if defaultVersion != "" {
+			version = defaultVersion
+		}
+	}
+
+	// Handle the pessmistic operator (~>)
+	var re = regexp.MustCompile(`~>`)
+	if re.MatchString(version) {
+		res := re.ReplaceAllString(version, "")
+		parts := strings.Split(res, ".")
+
+		// if the version contains a major, minor, and patch use "~" Tilde Range Comparison
+		// if the version contains a major and minor only, or a major version only use "^" Caret Range Comparison
+		if len(parts) == 3 {
+			version = "~" + res
+		} else {
+			version = "^" + res
+		}
+	}
+
+	versionConstraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return version, versionConstraint, nil
+}
+
+// filterDependencies returns the dependencies with the given id that are
+// included by the given function and match the version constraint, along with
+// the versions of all included dependencies with that id.
+func filterDependencies(dependencies []Dependency, id string, versionConstraint *semver.Constraints, include func(Dependency) bool) ([]Dependency, []string, error) {
+	var compatibleVersions []Dependency
+	var supportedVersions []string
+	for _, dependency := range dependencies {
+		if dependency.ID != id || !include(dependency) {
+			continue
+		}
+
+		sVersion, err := semver.NewVersion(dependency.Version)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if versionConstraint.Check(sVersion) {
+			compatibleVersions = append(compatibleVersions, dependency)
+		}
+
+		supportedVersions = append(supportedVersions, dependency.Version)
+	}
+
+	return compatibleVersions, supportedVersions, nil
+}
+
 func stringSliceContains(slice []string, str string) bool {
 	for _, s := range slice {
 		if s == str {
@@ -257,39 +501,87 @@ func (s Service) Deliver(dependency Dependency, cnbPath, layerPath, platformPath
 		dependencyChecksum = fmt.Sprintf("sha256:%s", dependency.SHA256)
 	}
 
-	dependencyMirrorURI, err := s.mirrorResolver.FindDependencyMirror(dependency.URI, platformPath)
-	if err != nil {
-		return fmt.Errorf("failure checking for dependency mirror: %s", err)
-	}
+	useCache := s.cache != nil && dependencyChecksum != ""
 
-	dependencyMappingURI, err := s.mappingResolver.FindDependencyMapping(dependencyChecksum, platformPath)
-	if err != nil {
-		return fmt.Errorf("failure checking for dependency mappings: %s", err)
+	// Content that is read from or written to the cache has already been
+	// validated against the checksum by the cache, so it is not validated
+	// again.
+	var (
+		bundle    io.ReadCloser
+		validated bool
+	)
+	if useCache {
+		var err error
+		bundle, validated, err = s.cache.Get(cargo.Checksum(dependencyChecksum))
+		if err != nil {
+			if !errors.Is(err, ErrUncacheableChecksum) {
+				return fmt.Errorf("failed to read dependency from cache: %s", err)
+			}
+
+			useCache = false
+		}
 	}
 
-	if dependencyMappingURI != "" {
-		dependency.URI = dependencyMappingURI
-	} else if dependencyMirrorURI != "" {
-		dependency.URI = dependencyMirrorURI
+	if bundle == nil {
+		dependencyMirrorURI, err := s.mirrorResolver.FindDependencyMirror(dependency.URI, platformPath)
+		if err != nil {
+			return fmt.Errorf("failure checking for dependency mirror: %s", err)
+		}
+
+		dependencyMappingURI, err := s.mappingResolver.FindDependencyMapping(dependencyChecksum, platformPath)
+		if err != nil {
+			return fmt.Errorf("failure checking for dependency mappings: %s", err)
+		}
+
+		if dependencyMappingURI != "" {
+			dependency.URI = dependencyMappingURI
+		} else if dependencyMirrorURI != "" {
+			dependency.URI = dependencyMirrorURI
+		}
+
+		bundle, err = s.fetch("dependency", dependency.URI, cnbPath, platformPath)
+		if err != nil {
+			return err
+		}
+
+		if useCache {
+			bundle, err = s.cacheBundle(bundle, cargo.Checksum(dependencyChecksum))
+			if err != nil {
+				return err
+			}
+
+			validated = true
+		}
 	}
 
-	bundle, err := s.transport.Drop(cnbPath, dependency.URI)
-	if err != nil {
-		return fmt.Errorf("failed to fetch dependency: %s", err)
+	if dependency.SignatureURI != "" {
+		var err error
+		bundle, err = s.verifySignature(bundle, dependency, cnbPath, platformPath)
+		if err != nil {
+			return err
+		}
 	}
 	defer bundle.Close()
 
+	var reader io.Reader = bundle
 	validatedReader := cargo.NewValidatedReader(bundle, dependencyChecksum)
+	if !validated {
+		reader = validatedReader
+	}
 
 	name := dependency.Name
 	if name == "" {
 		name = filepath.Base(dependency.URI)
 	}
-	err = vacation.NewArchive(validatedReader).WithName(name).StripComponents(dependency.StripComponents).Decompress(layerPath)
+	err := vacation.NewArchive(reader).WithName(name).WithLinkPolicy(s.linkPolicy).WithProgress(s.progress).StripComponents(dependency.StripComponents).Decompress(layerPath)
 	if err != nil {
 		return err
 	}
 
+	if validated {
+		return nil
+	}
+
 	ok, err := validatedReader.Valid()
 	if err != nil {
 		return fmt.Errorf("failed to validate dependency: %s", err)
@@ -302,6 +594,190 @@ func (s Service) Deliver(dependency Dependency, cnbPath, layerPath, platformPath
 	return nil
 }
 
+// DeliverAll will fetch, validate and expand each of the given deliveries
+// into their layer path in the same way as Deliver. Deliveries are processed
+// concurrently, with at most the number of deliveries configured using
+// WithConcurrency in progress at any time. All deliveries are attempted even
+// if some of them fail. If any fail, the returned error is a DeliveryErrors
+// containing an error for each of the failed deliveries.
+func (s Service) DeliverAll(deliveries []Delivery, cnbPath, platformPath string) error {
+	concurrency := s.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(deliveries))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(deliveries); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				delivery := deliveries[index]
+				errs[index] = s.Deliver(delivery.Dependency, cnbPath, delivery.LayerPath, platformPath)
+			}
+		}()
+	}
+
+	for index := range deliveries {
+		queue <- index
+	}
+	close(queue)
+	wg.Wait()
+
+	var failures DeliveryErrors
+	for index, err := range errs {
+		if err != nil {
+			failures = append(failures, DeliveryError{Delivery: deliveries[index], Err: err})
+		}
+	}
+
+	if len(failures) > 0 {
+		return failures
+	}
+
+	return nil
+}
+
+// fetch drops the given uri using the transport, attaching any credentials
+// that have been provided for it via binding. The kind describes what is being
+// fetched in any error that is returned.
+func (s Service) fetch(kind, uri, cnbPath, platformPath string) (io.ReadCloser, error) {
+	credentials, err := s.credentialsResolver.FindDependencyCredentials(uri, platformPath)
+	if err != nil {
+		return nil, fmt.Errorf("failure checking for dependency credentials: %s", err)
+	}
+
+	var bundle io.ReadCloser
+	if credentials.IsZero() {
+		bundle, err = s.transport.Drop(cnbPath, uri)
+	} else {
+		transport, ok := s.transport.(CredentialedTransport)
+		if !ok {
+			return nil, fmt.Errorf("failed to fetch %s: credentials were provided but the transport does not support them", kind)
+		}
+
+		bundle, err = transport.DropWithCredentials(cnbPath, uri, credentials)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %s", kind, err)
+	}
+
+	return bundle, nil
+}
+
+// verifySignature writes the given bundle to a temporary file and verifies its
+// signature. It returns a reader for that file only if the signature is
+// valid, so that an artifact is never extracted before it has been verified.
+func (s Service) verifySignature(bundle io.ReadCloser, dependency Dependency, cnbPath, platformPath string) (io.ReadCloser, error) {
+	defer bundle.Close()
+
+	if dependency.SignatureKeyID == "" {
+		return nil, errors.New("failed to verify dependency signature: signature key ID is missing")
+	}
+
+	key, err := s.signingKeyResolver.FindSigningKey(dependency.SignatureKeyID, cnbPath, platformPath)
+	if err != nil {
+		return nil, fmt.Errorf("failure checking for dependency signing key: %s", err)
+	}
+
+	signatureURI := dependency.SignatureURI
+	signatureMirrorURI, err := s.mirrorResolver.FindDependencyMirror(signatureURI, platformPath)
+	if err != nil {
+		return nil, fmt.Errorf("failure checking for dependency mirror: %s", err)
+	}
+
+	if signatureMirrorURI != "" {
+		signatureURI = signatureMirrorURI
+	}
+
+	signatureReader, err := s.fetch("dependency signature", signatureURI, cnbPath, platformPath)
+	if err != nil {
+		return nil, err
+	}
+	defer signatureReader.Close()
+
+	signature, err := io.ReadAll(signatureReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dependency signature: %s", err)
+	}
+
+	file, err := os.CreateTemp("", "dependency")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %s", err)
+	}
+
+	artifact := temporaryFile{file}
+	_, err = io.Copy(file, bundle)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		artifact.Close()
+		return nil, fmt.Errorf("failed to fetch dependency: %s", err)
+	}
+
+	err = internal.VerifySignature(key, signature, file)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		artifact.Close()
+		return nil, fmt.Errorf("failed to verify dependency signature: %s", err)
+	}
+
+	return artifact, nil
+}
+
+// temporaryFile is a file that is removed when it is closed.
+type temporaryFile struct {
+	*os.File
+}
+
+func (f temporaryFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+
+	return err
+}
+
+// cacheBundle stores the given bundle in the cache and returns a reader for
+// a temporary copy of it that is written at the same time, so that the
+// dependency can still be delivered if the cache evicts it straight away.
+func (s Service) cacheBundle(bundle io.ReadCloser, checksum cargo.Checksum) (io.ReadCloser, error) {
+	defer bundle.Close()
+
+	file, err := os.CreateTemp("", "dependency")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %s", err)
+	}
+
+	artifact := temporaryFile{file}
+
+	err = s.cache.Put(checksum, io.TeeReader(bundle, artifact))
+	if err != nil {
+		artifact.Close()
+
+		if errors.Is(err, cargo.ChecksumValidationError) {
+			return nil, errors.New("failed to validate dependency: checksum does not match")
+		}
+
+		return nil, fmt.Errorf("failed to write dependency to cache: %s", err)
+	}
+
+	_, err = artifact.Seek(0, io.SeekStart)
+	if err != nil {
+		artifact.Close()
+		return nil, fmt.Errorf("failed to read dependency: %s", err)
+	}
+
+	return artifact, nil
+}
+
 // GenerateBillOfMaterials will generate a list of BOMEntry values given a
 // collection of Dependency values.
 //
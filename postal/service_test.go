@@ -4,20 +4,29 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/cargo"
 	"github.com/paketo-buildpacks/packit/v2/postal"
 	"github.com/paketo-buildpacks/packit/v2/postal/fakes"
+	"github.com/paketo-buildpacks/packit/v2/vacation"
 	"github.com/sclevine/spec"
 
 	//nolint Ignore SA1019, usage of deprecated package within a deprecated test case
@@ -32,9 +41,10 @@ func testService(t *testing.T, context spec.G, it spec.S) {
 
 		path string
 
-		transport       *fakes.Transport
-		mappingResolver *fakes.MappingResolver
-		mirrorResolver  *fakes.MirrorResolver
+		transport           *fakes.Transport
+		mappingResolver     *fakes.MappingResolver
+		mirrorResolver      *fakes.MirrorResolver
+		credentialsResolver *fakes.CredentialsResolver
 
 		service postal.Service
 	)
@@ -112,9 +122,12 @@ strip-components = 1
 
 		mirrorResolver = &fakes.MirrorResolver{}
 
+		credentialsResolver = &fakes.CredentialsResolver{}
+
 		service = postal.NewService(transport).
 			WithDependencyMappingResolver(mappingResolver).
-			WithDependencyMirrorResolver(mirrorResolver)
+			WithDependencyMirrorResolver(mirrorResolver).
+			WithDependencyCredentialsResolver(credentialsResolver)
 	})
 
 	context("Resolve", func() {
@@ -435,6 +448,257 @@ version = "1.2.3"
 		})
 	})
 
+	context("ResolveForTarget", func() {
+		var target packit.Target
+
+		it.Before(func() {
+			target = packit.Target{
+				OS:            "linux",
+				Arch:          "arm64",
+				DistroName:    "ubuntu",
+				DistroVersion: "22.04",
+			}
+
+			err := os.WriteFile(path, []byte(`
+[metadata]
+[metadata.default-versions]
+some-entry = "1.2.x"
+
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha"
+uri = "some-uri-any-target"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha"
+os = "linux"
+arch = "arm64"
+uri = "some-uri-linux-arm64"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha"
+os = "linux"
+arch = "arm64"
+uri = "some-uri-linux-arm64-jammy"
+version = "1.2.3"
+
+  [[metadata.dependencies.distros]]
+  name = "ubuntu"
+  version = "22.04"
+
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha"
+os = "linux"
+arch = "amd64"
+uri = "some-uri-linux-amd64"
+version = "1.2.4"
+
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha"
+os = "linux"
+arch = "*"
+uri = "some-uri-linux-any-arch"
+version = "2.0.0"
+
+  [[metadata.dependencies.distros]]
+  name = "ubuntu"
+  version = "20.04"
+`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("finds the most specific dependency for the target", func() {
+			dependency, err := service.ResolveForTarget(path, "some-entry", "1.2.*", target)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dependency).To(Equal(postal.Dependency{
+				ID:     "some-entry",
+				SHA256: "some-sha",
+				OS:     "linux",
+				Arch:   "arm64",
+				Distros: []postal.DependencyDistro{
+					{
+						Name:    "ubuntu",
+						Version: "22.04",
+					},
+				},
+				URI:     "some-uri-linux-arm64-jammy",
+				Version: "1.2.3",
+			}))
+		})
+
+		context("when the version is the default", func() {
+			it("uses the default version", func() {
+				dependency, err := service.ResolveForTarget(path, "some-entry", "default", target)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dependency.URI).To(Equal("some-uri-linux-arm64-jammy"))
+			})
+		})
+
+		context("when only a wildcard dependency supports the target", func() {
+			it.Before(func() {
+				target.Arch = "ppc64le"
+				target.DistroName = "alpine"
+			})
+
+			it("selects the wildcard dependency", func() {
+				dependency, err := service.ResolveForTarget(path, "some-entry", "1.2.*", target)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dependency).To(Equal(postal.Dependency{
+					ID:      "some-entry",
+					SHA256:  "some-sha",
+					URI:     "some-uri-any-target",
+					Version: "1.2.3",
+				}))
+			})
+		})
+
+		context("when a dependency declares a distro that matches the target", func() {
+			it.Before(func() {
+				target.DistroVersion = "20.04"
+			})
+
+			it("selects the newest version that supports the target", func() {
+				dependency, err := service.ResolveForTarget(path, "some-entry", "*", target)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dependency.URI).To(Equal("some-uri-linux-any-arch"))
+				Expect(dependency.Version).To(Equal("2.0.0"))
+			})
+		})
+
+		context("when a dependency declares distros that do not match the target", func() {
+			it.Before(func() {
+				target.DistroVersion = "24.04"
+
+				err := os.WriteFile(path, []byte(`
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha"
+os = "linux"
+arch = "arm64"
+uri = "some-uri-any-ubuntu"
+version = "1.2.3"
+
+  [[metadata.dependencies.distros]]
+  name = "ubuntu"
+  version = "*"
+
+  [[metadata.dependencies.distros]]
+  name = "ubuntu"
+  version = "22.04"
+
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha"
+os = "linux"
+arch = "arm64"
+uri = "some-uri-noble"
+version = "1.2.3"
+
+  [[metadata.dependencies.distros]]
+  name = "ubuntu"
+  version = "24.04"
+`), 0600)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("only counts the distros that match the target", func() {
+				dependency, err := service.ResolveForTarget(path, "some-entry", "1.2.3", target)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dependency.URI).To(Equal("some-uri-noble"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the buildpack.toml is malformed", func() {
+				it.Before(func() {
+					err := os.WriteFile(path, []byte("this is not toml"), 0600)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("returns an error", func() {
+					_, err := service.ResolveForTarget(path, "some-entry", "1.2.3", target)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse buildpack.toml")))
+				})
+			})
+
+			context("when the entry version constraint is not valid", func() {
+				it("returns an error", func() {
+					_, err := service.ResolveForTarget(path, "some-entry", "this-is-not-semver", target)
+					Expect(err).To(MatchError(ContainSubstring("improper constraint")))
+				})
+			})
+
+			context("when multiple dependencies have a wildcard target for the same version", func() {
+				it.Before(func() {
+					err := os.WriteFile(path, []byte(`
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha-A"
+uri = "some-uri-A"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha-B"
+os = "*"
+arch = "*"
+uri = "some-uri-B"
+version = "1.2.3"
+`), 0600)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("returns an error", func() {
+					_, err := service.ResolveForTarget(path, "some-entry", "1.2.3", target)
+					Expect(err).To(MatchError(ContainSubstring(`multiple dependencies support wildcard target for version: "1.2.3"`)))
+				})
+			})
+
+			context("when multiple dependencies match the target equally specifically for the same version", func() {
+				it.Before(func() {
+					err := os.WriteFile(path, []byte(`
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha-A"
+os = "linux"
+arch = "*"
+uri = "some-uri-A"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id = "some-entry"
+sha256 = "some-sha-B"
+os = "*"
+arch = "arm64"
+uri = "some-uri-B"
+version = "1.2.3"
+`), 0600)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("returns an error", func() {
+					_, err := service.ResolveForTarget(path, "some-entry", "1.2.3", target)
+					Expect(err).To(MatchError(`multiple dependencies support target "linux/arm64 ubuntu 22.04" for version: "1.2.3"`))
+				})
+			})
+
+			context("when the entry version constraint cannot be satisfied", func() {
+				it("returns a typed error with all the supported versions listed", func() {
+					expectedErr := &postal.ErrNoDeps{}
+					_, err := service.ResolveForTarget(path, "some-entry", "9.9.9", target)
+					Expect(errors.As(err, &expectedErr)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring("failed to satisfy \"some-entry\" dependency version constraint \"9.9.9\": no compatible versions for \"linux/arm64 ubuntu 22.04\" target. Supported versions are: [1.2.3, 1.2.3, 1.2.3]")))
+				})
+			})
+		})
+	})
+
 	context("Deliver", func() {
 		var (
 			dependencyHash string
@@ -707,6 +971,64 @@ version = "1.2.3"
 			})
 		})
 
+		context("when progress is reported", func() {
+			var progress *fakes.ProgressReporter
+
+			it.Before(func() {
+				progress = &fakes.ProgressReporter{}
+				service = service.WithProgress(progress)
+			})
+
+			it("reports the progress of extracting the dependency", func() {
+				err := deliver()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(progress.ProgressCall.CallCount).NotTo(BeZero())
+				Expect(progress.ProgressCall.Receives.Entry).To(Equal("symlink"))
+				Expect(progress.ProgressCall.Receives.Read).To(BeNumerically(">", 0))
+				Expect(progress.ProgressCall.Receives.Total).To(BeEquivalentTo(-1))
+			})
+		})
+
+		context("when the dependency has a dangling symlink", func() {
+			it.Before(func() {
+				buffer := bytes.NewBuffer(nil)
+				zw := gzip.NewWriter(buffer)
+				tw := tar.NewWriter(zw)
+
+				Expect(tw.WriteHeader(&tar.Header{Name: "./some-dir", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+				Expect(tw.WriteHeader(&tar.Header{Name: "./some-dir/some-doc", Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: "../missing-doc"})).To(Succeed())
+
+				Expect(tw.Close()).To(Succeed())
+				Expect(zw.Close()).To(Succeed())
+
+				sum := sha256.Sum256(buffer.Bytes())
+				dependencyHash = hex.EncodeToString(sum[:])
+
+				transport.DropCall.Returns.ReadCloser = io.NopCloser(buffer)
+			})
+
+			it("fails to extract it by default", func() {
+				err := deliver()
+				Expect(err).To(MatchError(ContainSubstring("failed to evaluate symlink")))
+			})
+
+			context("when the link policy allows dangling symlinks", func() {
+				it.Before(func() {
+					service = service.WithLinkPolicy(vacation.LinkPolicyAllowDangling)
+				})
+
+				it("extracts the symlink", func() {
+					err := deliver()
+					Expect(err).NotTo(HaveOccurred())
+
+					link, err := os.Readlink(filepath.Join(layerPath, "some-dir", "some-doc"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(link).To(Equal("../missing-doc"))
+				})
+			})
+		})
+
 		context("when there is a dependency mapping via binding", func() {
 			it.Before(func() {
 				mappingResolver.FindDependencyMappingCall.Returns.String = "dependency-mapping-entry.tgz"
@@ -831,6 +1153,340 @@ version = "1.2.3"
 			})
 		})
 
+		context("when the dependency has a signature", func() {
+			var (
+				signingKeyResolver *fakes.SigningKeyResolver
+				signature          []byte
+				dependency         postal.Dependency
+			)
+
+			it.Before(func() {
+				archive, err := io.ReadAll(transport.DropCall.Returns.ReadCloser)
+				Expect(err).NotTo(HaveOccurred())
+
+				privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				digest := sha256.Sum256(archive)
+				sig, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+				Expect(err).NotTo(HaveOccurred())
+				signature = []byte(base64.StdEncoding.EncodeToString(sig))
+
+				der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				signingKeyResolver = &fakes.SigningKeyResolver{}
+				signingKeyResolver.FindSigningKeyCall.Returns.ByteSlice = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+				transport.DropCall.Stub = func(root, uri string) (io.ReadCloser, error) {
+					if uri == "some-entry.tgz.sig" {
+						return io.NopCloser(bytes.NewReader(signature)), nil
+					}
+
+					return io.NopCloser(bytes.NewReader(archive)), nil
+				}
+
+				service = service.WithSigningKeyResolver(signingKeyResolver)
+
+				dependency = postal.Dependency{
+					ID:             "some-entry",
+					Stacks:         []string{"some-stack"},
+					URI:            "some-entry.tgz",
+					SHA256:         dependencyHash,
+					SignatureURI:   "some-entry.tgz.sig",
+					SignatureKeyID: "some-key-id",
+					Version:        "1.2.3",
+				}
+			})
+
+			it("verifies the signature and unpackages the dependency", func() {
+				err := service.Deliver(dependency, "some-cnb-path", layerPath, "some-platform-dir")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(signingKeyResolver.FindSigningKeyCall.Receives.KeyID).To(Equal("some-key-id"))
+				Expect(signingKeyResolver.FindSigningKeyCall.Receives.CnbPath).To(Equal("some-cnb-path"))
+				Expect(signingKeyResolver.FindSigningKeyCall.Receives.PlatformDir).To(Equal("some-platform-dir"))
+				Expect(transport.DropCall.CallCount).To(Equal(2))
+
+				files, err := filepath.Glob(fmt.Sprintf("%s/*", layerPath))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(HaveLen(5))
+			})
+
+			context("failure cases", func() {
+				context("when the signature does not match", func() {
+					it.Before(func() {
+						signature = []byte(base64.StdEncoding.EncodeToString([]byte("some-other-signature")))
+					})
+
+					it("returns an error and does not unpackage the dependency", func() {
+						err := service.Deliver(dependency, "some-cnb-path", layerPath, "some-platform-dir")
+						Expect(err).To(MatchError("failed to verify dependency signature: signature does not match"))
+
+						files, err := filepath.Glob(fmt.Sprintf("%s/*", layerPath))
+						Expect(err).NotTo(HaveOccurred())
+						Expect(files).To(BeEmpty())
+					})
+				})
+
+				context("when the signature key ID is missing", func() {
+					it.Before(func() {
+						dependency.SignatureKeyID = ""
+					})
+
+					it("returns an error", func() {
+						err := service.Deliver(dependency, "some-cnb-path", layerPath, "some-platform-dir")
+						Expect(err).To(MatchError("failed to verify dependency signature: signature key ID is missing"))
+					})
+				})
+
+				context("when the signing key cannot be found", func() {
+					it.Before(func() {
+						signingKeyResolver.FindSigningKeyCall.Returns.Error = errors.New("some signing key error")
+					})
+
+					it("returns an error", func() {
+						err := service.Deliver(dependency, "some-cnb-path", layerPath, "some-platform-dir")
+						Expect(err).To(MatchError("failure checking for dependency signing key: some signing key error"))
+					})
+				})
+
+				context("when the signature cannot be fetched", func() {
+					it.Before(func() {
+						transport.DropCall.Stub = func(root, uri string) (io.ReadCloser, error) {
+							if uri == "some-entry.tgz.sig" {
+								return nil, errors.New("some fetch error")
+							}
+
+							return io.NopCloser(bytes.NewReader(nil)), nil
+						}
+					})
+
+					it("returns an error", func() {
+						err := service.Deliver(dependency, "some-cnb-path", layerPath, "some-platform-dir")
+						Expect(err).To(MatchError("failed to fetch dependency signature: some fetch error"))
+					})
+				})
+			})
+		})
+
+		context("when there are dependency credentials via binding", func() {
+			it.Before(func() {
+				credentialsResolver.FindDependencyCredentialsCall.Returns.Credentials = cargo.Credentials{
+					Token: "some-token",
+				}
+			})
+
+			context("when the transport supports credentials", func() {
+				var credentialedTransport *fakes.CredentialedTransport
+
+				it.Before(func() {
+					credentialedTransport = &fakes.CredentialedTransport{}
+					credentialedTransport.DropWithCredentialsCall.Returns.ReadCloser = transport.DropCall.Returns.ReadCloser
+
+					service = postal.NewService(credentialedTransport).
+						WithDependencyMappingResolver(mappingResolver).
+						WithDependencyMirrorResolver(mirrorResolver).
+						WithDependencyCredentialsResolver(credentialsResolver)
+				})
+
+				it("downloads the dependency using the credentials", func() {
+					err := deliver()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(credentialsResolver.FindDependencyCredentialsCall.Receives.Uri).To(Equal("some-entry.tgz"))
+					Expect(credentialsResolver.FindDependencyCredentialsCall.Receives.PlatformDir).To(Equal("some-platform-dir"))
+
+					Expect(credentialedTransport.DropCall.CallCount).To(Equal(0))
+					Expect(credentialedTransport.DropWithCredentialsCall.Receives.Root).To(Equal("some-cnb-path"))
+					Expect(credentialedTransport.DropWithCredentialsCall.Receives.Uri).To(Equal("some-entry.tgz"))
+					Expect(credentialedTransport.DropWithCredentialsCall.Receives.Credentials).To(Equal(cargo.Credentials{
+						Token: "some-token",
+					}))
+
+					files, err := filepath.Glob(fmt.Sprintf("%s/*", layerPath))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(HaveLen(5))
+				})
+
+				context("when there is also a dependency mirror", func() {
+					it.Before(func() {
+						mirrorResolver.FindDependencyMirrorCall.Returns.String = "dependency-mirror-url"
+					})
+
+					it("looks up the credentials for the mirror", func() {
+						err := deliver()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(credentialsResolver.FindDependencyCredentialsCall.Receives.Uri).To(Equal("dependency-mirror-url"))
+						Expect(credentialedTransport.DropWithCredentialsCall.Receives.Uri).To(Equal("dependency-mirror-url"))
+					})
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the transport does not support credentials", func() {
+					it("returns an error", func() {
+						err := deliver()
+						Expect(err).To(MatchError("failed to fetch dependency: credentials were provided but the transport does not support them"))
+
+						Expect(transport.DropCall.CallCount).To(Equal(0))
+					})
+				})
+
+				context("when the credentials cannot be resolved", func() {
+					it.Before(func() {
+						credentialsResolver.FindDependencyCredentialsCall.Returns.Error = errors.New("some dependency credentials error")
+					})
+
+					it("returns an error", func() {
+						err := deliver()
+						Expect(err).To(MatchError("failure checking for dependency credentials: some dependency credentials error"))
+					})
+				})
+			})
+		})
+
+		context("when there is a cache", func() {
+			var cache *fakes.Cache
+
+			it.Before(func() {
+				cache = &fakes.Cache{}
+				service = service.WithCache(cache)
+			})
+
+			context("when the dependency is in the cache", func() {
+				it.Before(func() {
+					cache.GetCall.Returns.ReadCloser = transport.DropCall.Returns.ReadCloser
+					cache.GetCall.Returns.Bool = true
+				})
+
+				it("does not fetch the dependency", func() {
+					err := deliver()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(cache.GetCall.Receives.Checksum).To(Equal(cargo.Checksum(fmt.Sprintf("sha256:%s", dependencyHash))))
+					Expect(cache.PutCall.CallCount).To(Equal(0))
+					Expect(mirrorResolver.FindDependencyMirrorCall.CallCount).To(Equal(0))
+					Expect(mappingResolver.FindDependencyMappingCall.CallCount).To(Equal(0))
+					Expect(transport.DropCall.CallCount).To(Equal(0))
+
+					files, err := filepath.Glob(fmt.Sprintf("%s/*", layerPath))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(ConsistOf([]string{
+						filepath.Join(layerPath, "first"),
+						filepath.Join(layerPath, "second"),
+						filepath.Join(layerPath, "third"),
+						filepath.Join(layerPath, "some-dir"),
+						filepath.Join(layerPath, "symlink"),
+					}))
+				})
+			})
+
+			context("when the dependency is not in the cache", func() {
+				var cached []byte
+
+				it.Before(func() {
+					cached = nil
+					cache.PutCall.Stub = func(checksum cargo.Checksum, reader io.Reader) error {
+						var err error
+						cached, err = io.ReadAll(reader)
+						return err
+					}
+				})
+
+				it("fetches the dependency, stores it in the cache and delivers the copy it wrote", func() {
+					err := deliver()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(transport.DropCall.Receives.Uri).To(Equal("some-entry.tgz"))
+					Expect(cache.PutCall.CallCount).To(Equal(1))
+					Expect(cache.PutCall.Receives.Checksum).To(Equal(cargo.Checksum(fmt.Sprintf("sha256:%s", dependencyHash))))
+					Expect(cached).NotTo(BeEmpty())
+
+					// The dependency is not read back from the cache, which may already
+					// have evicted it.
+					Expect(cache.GetCall.CallCount).To(Equal(1))
+
+					files, err := filepath.Glob(fmt.Sprintf("%s/*", layerPath))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(HaveLen(5))
+				})
+			})
+
+			context("when the cache cannot store the dependency checksum", func() {
+				it.Before(func() {
+					cache.GetCall.Returns.Error = fmt.Errorf("%w: unsupported algorithm", postal.ErrUncacheableChecksum)
+				})
+
+				it("fetches the dependency without using the cache", func() {
+					err := deliver()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(transport.DropCall.Receives.Uri).To(Equal("some-entry.tgz"))
+					Expect(cache.PutCall.CallCount).To(Equal(0))
+
+					files, err := filepath.Glob(fmt.Sprintf("%s/*", layerPath))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(HaveLen(5))
+				})
+			})
+
+			context("when the dependency does not have a checksum", func() {
+				it("does not use the cache", func() {
+					err := service.Deliver(
+						postal.Dependency{
+							ID:      "some-entry",
+							URI:     "some-entry.tgz",
+							Version: "1.2.3",
+						},
+						"some-cnb-path",
+						layerPath,
+						"some-platform-dir",
+					)
+					Expect(err).To(HaveOccurred())
+
+					Expect(cache.GetCall.CallCount).To(Equal(0))
+					Expect(cache.PutCall.CallCount).To(Equal(0))
+				})
+			})
+
+			context("failure cases", func() {
+				context("when the cache cannot be read", func() {
+					it.Before(func() {
+						cache.GetCall.Returns.Error = errors.New("failed to get")
+					})
+
+					it("returns an error", func() {
+						err := deliver()
+						Expect(err).To(MatchError("failed to read dependency from cache: failed to get"))
+					})
+				})
+
+				context("when the fetched dependency does not match the checksum", func() {
+					it.Before(func() {
+						cache.PutCall.Returns.Error = cargo.ChecksumValidationError
+					})
+
+					it("returns an error", func() {
+						err := deliver()
+						Expect(err).To(MatchError("failed to validate dependency: checksum does not match"))
+					})
+				})
+
+				context("when the dependency cannot be written to the cache", func() {
+					it.Before(func() {
+						cache.PutCall.Returns.Error = errors.New("failed to put")
+					})
+
+					it("returns an error", func() {
+						err := deliver()
+						Expect(err).To(MatchError("failed to write dependency to cache: failed to put"))
+					})
+				})
+			})
+		})
+
 		context("failure cases", func() {
 			context("when dependency mapping resolver fails", func() {
 				it.Before(func() {
@@ -1053,6 +1709,142 @@ version = "1.2.3"
 		})
 	})
 
+	context("DeliverAll", func() {
+		var (
+			archives   map[string][]byte
+			layersPath string
+			deliveries []postal.Delivery
+
+			inFlight    int
+			maxInFlight int
+			mutex       sync.Mutex
+		)
+
+		it.Before(func() {
+			var err error
+			layersPath, err = os.MkdirTemp("", "layers")
+			Expect(err).NotTo(HaveOccurred())
+
+			archives = map[string][]byte{}
+			deliveries = nil
+			for _, name := range []string{"first", "second", "third", "fourth", "fifth"} {
+				buffer := bytes.NewBuffer(nil)
+				zw := gzip.NewWriter(buffer)
+				tw := tar.NewWriter(zw)
+
+				Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(name))})).To(Succeed())
+				_, err = tw.Write([]byte(name))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tw.Close()).To(Succeed())
+				Expect(zw.Close()).To(Succeed())
+
+				sum := sha256.Sum256(buffer.Bytes())
+				archives[fmt.Sprintf("%s.tgz", name)] = buffer.Bytes()
+
+				deliveries = append(deliveries, postal.Delivery{
+					Dependency: postal.Dependency{
+						ID:       name,
+						URI:      fmt.Sprintf("%s.tgz", name),
+						Checksum: fmt.Sprintf("sha256:%s", hex.EncodeToString(sum[:])),
+						Version:  "1.2.3",
+					},
+					LayerPath: filepath.Join(layersPath, name),
+				})
+			}
+
+			inFlight = 0
+			maxInFlight = 0
+			transport.DropCall.Stub = func(root, uri string) (io.ReadCloser, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+
+				return trackedReadCloser{
+					Reader: bytes.NewReader(archives[uri]),
+					close: func() {
+						mutex.Lock()
+						defer mutex.Unlock()
+						inFlight--
+					},
+				}, nil
+			}
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(layersPath)).To(Succeed())
+		})
+
+		it("downloads each dependency and unpackages it into its layer path", func() {
+			err := service.DeliverAll(deliveries, "some-cnb-path", "some-platform-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(transport.DropCall.CallCount).To(Equal(5))
+
+			for _, name := range []string{"first", "second", "third", "fourth", "fifth"} {
+				content, err := os.ReadFile(filepath.Join(layersPath, name, name))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal(name))
+			}
+		})
+
+		context("when the concurrency is limited", func() {
+			it.Before(func() {
+				service = service.WithConcurrency(2)
+			})
+
+			it("never delivers more dependencies than the limit at the same time", func() {
+				err := service.DeliverAll(deliveries, "some-cnb-path", "some-platform-dir")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(transport.DropCall.CallCount).To(Equal(5))
+				Expect(maxInFlight).To(BeNumerically("<=", 2))
+			})
+		})
+
+		context("when there are no deliveries", func() {
+			it("does nothing", func() {
+				err := service.DeliverAll(nil, "some-cnb-path", "some-platform-dir")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(transport.DropCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when some of the dependencies fail to deliver", func() {
+				it.Before(func() {
+					deliveries[1].Dependency.Checksum = "sha256:some-other-checksum"
+					archives["fourth.tgz"] = []byte("this is not an archive")
+				})
+
+				it("delivers the remaining dependencies and returns an error for each failure", func() {
+					err := service.DeliverAll(deliveries, "some-cnb-path", "some-platform-dir")
+					Expect(err).To(HaveOccurred())
+
+					var deliveryErrs postal.DeliveryErrors
+					Expect(errors.As(err, &deliveryErrs)).To(BeTrue())
+					Expect(deliveryErrs).To(HaveLen(2))
+
+					Expect(deliveryErrs[0].Delivery).To(Equal(deliveries[1]))
+					Expect(deliveryErrs[0]).To(MatchError(ContainSubstring(`failed to deliver "second" dependency version "1.2.3"`)))
+					Expect(deliveryErrs[0].Unwrap()).To(MatchError(ContainSubstring("checksum does not match")))
+
+					Expect(deliveryErrs[1].Delivery).To(Equal(deliveries[3]))
+					Expect(deliveryErrs[1]).To(MatchError(ContainSubstring(`failed to deliver "fourth" dependency version "1.2.3"`)))
+
+					for _, name := range []string{"first", "third", "fifth"} {
+						Expect(filepath.Join(layersPath, name, name)).To(BeARegularFile())
+					}
+				})
+			})
+		})
+	})
+
 	context("GenerateBillOfMaterials", func() {
 		it("returns a list of BOMEntry values", func() {
 			entries := service.GenerateBillOfMaterials(
@@ -1490,3 +2282,13 @@ version = "1.2.3"
 		})
 	})
 }
+
+type trackedReadCloser struct {
+	io.Reader
+	close func()
+}
+
+func (r trackedReadCloser) Close() error {
+	r.close()
+	return nil
+}
@@ -14,6 +14,7 @@ func TestUnitScribe(t *testing.T) {
 	suite("FormattedList", testFormattedList)
 	suite("FormattedMap", testFormattedMap)
 	suite("Logger", testLogger)
+	suite("ProgressLogger", testProgressLogger)
 	suite("Writer", testWriter)
 	suite.Run(t)
 }
@@ -0,0 +1,94 @@
+package scribe
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultProgressInterval is the shortest time between the lines printed by a
+// ProgressLogger.
+const DefaultProgressInterval = 2 * time.Second
+
+// A ProgressLogger prints the progress reported by a postal.Service,
+// cargo.Transport or vacation.Archive as lines at the Action level of
+// indentation. As progress is reported very often, lines are printed at most
+// once per interval, with a final line once the total has been read.
+type ProgressLogger struct {
+	logger   LeveledLogger
+	interval time.Duration
+	state    *progressState
+}
+
+type progressState struct {
+	mutex   sync.Mutex
+	printed time.Time
+	read    int64
+}
+
+// NewProgressLogger returns a ProgressLogger that prints to the given logger.
+func NewProgressLogger(logger LeveledLogger) ProgressLogger {
+	return ProgressLogger{
+		logger:   logger,
+		interval: DefaultProgressInterval,
+		state:    &progressState{},
+	}
+}
+
+// WithInterval sets the shortest time between the lines that are printed.
+func (p ProgressLogger) WithInterval(interval time.Duration) ProgressLogger {
+	p.interval = interval
+	return p
+}
+
+// Progress prints the progress of the given entry, the number of bytes read,
+// and the total number of bytes, which is -1 when it is not known.
+func (p ProgressLogger) Progress(entry string, read, total int64) {
+	p.state.mutex.Lock()
+	defer p.state.mutex.Unlock()
+
+	complete := total >= 0 && read >= total
+	if !p.state.printed.IsZero() {
+		if complete && p.state.read == read {
+			return
+		}
+
+		if !complete && time.Since(p.state.printed) < p.interval {
+			return
+		}
+	}
+
+	prefix := ""
+	if entry != "" {
+		prefix = entry + ": "
+	}
+
+	if total >= 0 {
+		percent := int64(100)
+		if total > 0 {
+			percent = read * 100 / total
+		}
+
+		p.logger.Action("%s%s of %s (%d%%)", prefix, formatBytes(read), formatBytes(total), percent)
+	} else {
+		p.logger.Action("%s%s", prefix, formatBytes(read))
+	}
+
+	p.state.printed = time.Now()
+	p.state.read = read
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
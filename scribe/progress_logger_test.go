@@ -0,0 +1,65 @@
+package scribe_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testProgressLogger(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buffer   *bytes.Buffer
+		progress scribe.ProgressLogger
+	)
+
+	it.Before(func() {
+		buffer = bytes.NewBuffer(nil)
+		progress = scribe.NewProgressLogger(scribe.NewLogger(buffer).LeveledLogger).WithInterval(0)
+	})
+
+	context("Progress", func() {
+		it("prints the progress at the action level of indentation", func() {
+			progress.Progress("some-entry", 512, 2048)
+			progress.Progress("some-entry", 1536*1024, 3*1024*1024)
+
+			Expect(buffer.String()).To(Equal(
+				"      some-entry: 512 B of 2.0 KiB (25%)\n" +
+					"      some-entry: 1.5 MiB of 3.0 MiB (50%)\n",
+			))
+		})
+
+		context("when the total is not known", func() {
+			it("prints the bytes read", func() {
+				progress.Progress("", 2048, -1)
+
+				Expect(buffer.String()).To(Equal("      2.0 KiB\n"))
+			})
+		})
+
+		context("when the progress is reported more often than the interval", func() {
+			it.Before(func() {
+				progress = progress.WithInterval(time.Hour)
+			})
+
+			it("only prints the first and final lines", func() {
+				progress.Progress("some-entry", 1, 4)
+				progress.Progress("some-entry", 2, 4)
+				progress.Progress("some-entry", 3, 4)
+				progress.Progress("some-entry", 4, 4)
+				progress.Progress("some-entry", 4, 4)
+
+				Expect(buffer.String()).To(Equal(
+					"      some-entry: 1 B of 4 B (25%)\n" +
+						"      some-entry: 4 B of 4 B (100%)\n",
+				))
+			})
+		})
+	})
+}
@@ -0,0 +1,47 @@
+package packit
+
+import "os"
+
+// Target represents the platform that the application image is being built
+// for as provided by the lifecycle according to the specification:
+// https://github.com/buildpacks/spec/blob/main/buildpack.md#targets. The
+// target details are only provided to buildpacks and extensions that declare
+// Buildpack API v0.10 or higher.
+type Target struct {
+	// OS is the operating system of the target, eg. "linux". This value is
+	// populated from the $CNB_TARGET_OS environment variable.
+	OS string
+
+	// Arch is the CPU architecture of the target, eg. "amd64" or "arm64". This
+	// value is populated from the $CNB_TARGET_ARCH environment variable.
+	Arch string
+
+	// ArchVariant is the variant of the CPU architecture of the target, eg.
+	// "v8". This value is populated from the $CNB_TARGET_ARCH_VARIANT
+	// environment variable.
+	ArchVariant string
+
+	// DistroName is the name of the operating system distribution of the
+	// target, eg. "ubuntu". This value is populated from the
+	// $CNB_TARGET_DISTRO_NAME environment variable.
+	DistroName string
+
+	// DistroVersion is the version of the operating system distribution of the
+	// target, eg. "22.04". This value is populated from the
+	// $CNB_TARGET_DISTRO_VERSION environment variable.
+	DistroVersion string
+}
+
+func newTarget(api buildpackAPI) Target {
+	if !api.declared() || !api.supports(apiFeatureTargets) {
+		return Target{}
+	}
+
+	return Target{
+		OS:            os.Getenv("CNB_TARGET_OS"),
+		Arch:          os.Getenv("CNB_TARGET_ARCH"),
+		ArchVariant:   os.Getenv("CNB_TARGET_ARCH_VARIANT"),
+		DistroName:    os.Getenv("CNB_TARGET_DISTRO_NAME"),
+		DistroVersion: os.Getenv("CNB_TARGET_DISTRO_VERSION"),
+	}
+}
@@ -2,6 +2,7 @@ package vacation
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 
@@ -12,12 +13,18 @@ type Decompressor interface {
 	Decompress(destination string) error
 }
 
-// An Archive decompresses tar, gzip, xz, and bzip2 compressed tar, and zip files from
-// an input stream.
+// lz4Magic is the magic number that begins an LZ4 frame. The mimetype library
+// does not detect LZ4, so it is matched directly.
+var lz4Magic = []byte{0x04, 0x22, 0x4d, 0x18}
+
+// An Archive decompresses tar, gzip, xz, bzip2, zstd, and lz4 compressed tar,
+// zip, Debian package, and RPM package files from an input stream.
 type Archive struct {
-	reader     io.Reader
-	components int
-	name       string
+	extractOptions
+
+	reader   io.Reader
+	name     string
+	spoolDir string
 }
 
 // NewArchive returns a new Archive that reads from inputReader.
@@ -40,7 +47,9 @@ func NewArchive(inputReader io.Reader) Archive {
 func (a Archive) Decompress(destination string) error {
 	// Convert reader into a buffered read so that the header can be peeked to
 	// determine the type.
-	bufferedReader := bufio.NewReader(a.reader)
+	limits, reader := a.limits.count(a.reader)
+	progress, reader := trackProgress(a.progress, reader)
+	bufferedReader := bufio.NewReader(reader)
 
 	// The number 3072 is lifted from the mimetype library and the definition of
 	// the constant at the time of writing this functionality is listed below.
@@ -50,29 +59,49 @@ func (a Archive) Decompress(destination string) error {
 		return err
 	}
 
-	mime := mimetype.Detect(header)
+	mime := mimetype.Detect(header).String()
+	if bytes.HasPrefix(header, lz4Magic) {
+		mime = "application/x-lz4"
+	}
+
+	options := a.forward(limits, progress)
 
 	// This switch case is responsible for determining the decompression strategy
 	var decompressor Decompressor
-	switch mime.String() {
+	switch mime {
 	case "application/x-tar":
-		decompressor = NewTarArchive(bufferedReader).StripComponents(a.components)
+		decompressor = TarArchive{extractOptions: options, reader: bufferedReader}
 	case "application/gzip":
-		decompressor = NewGzipArchive(bufferedReader).StripComponents(a.components).WithName(a.name)
+		decompressor = GzipArchive{extractOptions: options, reader: bufferedReader, name: a.name}
 	case "application/x-xz":
-		decompressor = NewXZArchive(bufferedReader).StripComponents(a.components).WithName(a.name)
+		decompressor = XZArchive{extractOptions: options, reader: bufferedReader, name: a.name}
 	case "application/x-bzip2":
-		decompressor = NewBzip2Archive(bufferedReader).StripComponents(a.components).WithName(a.name)
+		decompressor = Bzip2Archive{extractOptions: options, reader: bufferedReader, name: a.name}
+	case "application/zstd":
+		decompressor = ZstdArchive{extractOptions: options, reader: bufferedReader, name: a.name}
+	case "application/x-lz4":
+		decompressor = LZ4Archive{extractOptions: options, reader: bufferedReader, name: a.name}
 	case "application/zip":
-		decompressor = NewZipArchive(bufferedReader).StripComponents(a.components)
+		// Zip files are read from the input directly when possible, as the header
+		// that was peeked is read again by offset.
+		var input io.Reader = bufferedReader
+		if _, _, ok := readerAt(a.reader); ok {
+			input = a.reader
+		}
+
+		decompressor = ZipArchive{extractOptions: options, reader: input, spoolDir: a.spoolDir}
+	case "application/vnd.debian.binary-package":
+		decompressor = DebArchive{extractOptions: options, reader: bufferedReader}
+	case "application/x-rpm":
+		decompressor = RPMArchive{extractOptions: options, reader: bufferedReader}
 	case "application/x-executable":
-		decompressor = NewExecutable(bufferedReader).WithName(a.name)
+		decompressor = NewExecutable(bufferedReader).WithLimits(limits).WithManifest(a.manifest).WithProgress(progress).WithName(a.name)
 	case "text/plain; charset=utf-8",
 		"application/jar",
 		"application/octet-stream":
-		decompressor = NewNopArchive(bufferedReader).WithName(a.name)
+		decompressor = NewNopArchive(bufferedReader).WithLimits(limits).WithManifest(a.manifest).WithProgress(progress).WithName(a.name)
 	default:
-		return fmt.Errorf("unsupported archive type: %s", mime.String())
+		return fmt.Errorf("unsupported archive type: %s", mime)
 	}
 
 	return decompressor.Decompress(destination)
@@ -93,3 +122,65 @@ func (a Archive) WithName(name string) Archive {
 	a.name = name
 	return a
 }
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+// Exceeding a limit fails decompression with a *LimitError and removes any
+// files that were written.
+func (a Archive) WithLimits(limits Limits) Archive {
+	a.limits = limits
+	return a
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs. The globs use the syntax of path.Match and are matched against the
+// path of an entry after its leading components are stripped. A glob that
+// matches a directory includes everything below it. Setting this is a no-op
+// for files that are not archives.
+func (a Archive) WithIncludes(globs ...string) Archive {
+	a.includes = globs
+	return a
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs. The globs are matched in the same way as those given to
+// WithIncludes and take precedence over them.
+func (a Archive) WithExcludes(globs ...string) Archive {
+	a.excludes = globs
+	return a
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files. Setting this is a no-op for files
+// that are not archives.
+func (a Archive) WithPreserve(options PreserveOptions) Archive {
+	a.preserve = options
+	return a
+}
+
+// WithSpoolDir buffers zip files that cannot be read from the input directly
+// in a temporary file in the given directory, rather than extracting their
+// entries as they are streamed.
+func (a Archive) WithSpoolDir(dir string) Archive {
+	a.spoolDir = dir
+	return a
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (a Archive) WithManifest(manifest *Manifest) Archive {
+	a.manifest = manifest
+	return a
+}
+
+// WithLinkPolicy sets the policy that symlinks and hard links must satisfy
+// before they are created.
+func (a Archive) WithLinkPolicy(policy LinkPolicy) Archive {
+	a.linkPolicy = policy
+	return a
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (a Archive) WithProgress(reporter ProgressReporter) Archive {
+	a.progress = reporter
+	return a
+}
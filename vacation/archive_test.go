@@ -13,7 +13,9 @@ import (
 	"testing"
 
 	dsnetBzip2 "github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
 	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/pierrec/lz4/v4"
 	"github.com/sclevine/spec"
 	"github.com/ulikunitz/xz"
 
@@ -212,6 +214,133 @@ func testArchive(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when passed the reader of a tar zstd file", func() {
+			var (
+				archive vacation.Archive
+				tempDir string
+			)
+
+			it.Before(func() {
+				var err error
+				tempDir, err = os.MkdirTemp("", "vacation")
+				Expect(err).NotTo(HaveOccurred())
+
+				buffer := bytes.NewBuffer(nil)
+				zstdw, err := zstd.NewWriter(buffer)
+				Expect(err).NotTo(HaveOccurred())
+
+				tw := tar.NewWriter(zstdw)
+
+				Expect(tw.WriteHeader(&tar.Header{Name: "some-dir", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+				_, err = tw.Write(nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				nestedFile := filepath.Join("some-dir", "some-nested-file")
+				Expect(tw.WriteHeader(&tar.Header{Name: nestedFile, Mode: 0755, Size: int64(len(nestedFile))})).To(Succeed())
+				_, err = tw.Write([]byte(nestedFile))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tw.WriteHeader(&tar.Header{Name: "some-file", Mode: 0755, Size: int64(len("some-file"))})).To(Succeed())
+				_, err = tw.Write([]byte("some-file"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tw.Close()).To(Succeed())
+				Expect(zstdw.Close()).To(Succeed())
+
+				archive = vacation.NewArchive(buffer)
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(tempDir)).To(Succeed())
+			})
+
+			it("unpackages the archive into the path", func() {
+				err := archive.Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "some-dir"),
+					filepath.Join(tempDir, "some-file"),
+				}))
+			})
+
+			it("unpackages the archive into the path but also strips the first component", func() {
+				err := archive.StripComponents(1).Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "some-nested-file"),
+				}))
+			})
+		})
+
+		context("when passed the reader of a tar lz4 file", func() {
+			var (
+				archive vacation.Archive
+				tempDir string
+			)
+
+			it.Before(func() {
+				var err error
+				tempDir, err = os.MkdirTemp("", "vacation")
+				Expect(err).NotTo(HaveOccurred())
+
+				buffer := bytes.NewBuffer(nil)
+				lz4w := lz4.NewWriter(buffer)
+
+				tw := tar.NewWriter(lz4w)
+
+				Expect(tw.WriteHeader(&tar.Header{Name: "some-dir", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+				_, err = tw.Write(nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				nestedFile := filepath.Join("some-dir", "some-nested-file")
+				Expect(tw.WriteHeader(&tar.Header{Name: nestedFile, Mode: 0755, Size: int64(len(nestedFile))})).To(Succeed())
+				_, err = tw.Write([]byte(nestedFile))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tw.WriteHeader(&tar.Header{Name: "some-file", Mode: 0755, Size: int64(len("some-file"))})).To(Succeed())
+				_, err = tw.Write([]byte("some-file"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tw.Close()).To(Succeed())
+				Expect(lz4w.Close()).To(Succeed())
+
+				archive = vacation.NewArchive(buffer)
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(tempDir)).To(Succeed())
+			})
+
+			it("unpackages the archive into the path", func() {
+				err := archive.Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "some-dir"),
+					filepath.Join(tempDir, "some-file"),
+				}))
+			})
+
+			it("unpackages the archive into the path but also strips the first component", func() {
+				err := archive.StripComponents(1).Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "some-nested-file"),
+				}))
+			})
+		})
+
 		context("when passed the reader of a bzip2 file", func() {
 			var (
 				archive vacation.Archive
@@ -349,6 +478,116 @@ func testArchive(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when passed the reader of a deb package", func() {
+			var (
+				archive vacation.Archive
+				tempDir string
+			)
+
+			it.Before(func() {
+				var err error
+				tempDir, err = os.MkdirTemp("", "vacation")
+				Expect(err).NotTo(HaveOccurred())
+
+				buffer := bytes.NewBuffer(nil)
+				gw := gzip.NewWriter(buffer)
+				tw := tar.NewWriter(gw)
+
+				Expect(tw.WriteHeader(&tar.Header{Name: "./some-dir", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+
+				nestedFile := filepath.Join("some-dir", "some-nested-file")
+				Expect(tw.WriteHeader(&tar.Header{Name: "./" + nestedFile, Mode: 0755, Size: int64(len(nestedFile))})).To(Succeed())
+				_, err = tw.Write([]byte(nestedFile))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tw.WriteHeader(&tar.Header{Name: "./some-file", Mode: 0755, Size: int64(len("some-file"))})).To(Succeed())
+				_, err = tw.Write([]byte("some-file"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tw.Close()).To(Succeed())
+				Expect(gw.Close()).To(Succeed())
+
+				archive = vacation.NewArchive(bytes.NewReader(newDebPackage(t, map[string][]byte{
+					"data.tar.gz": buffer.Bytes(),
+				}, "data.tar.gz")))
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(tempDir)).To(Succeed())
+			})
+
+			it("unpackages the archive into the path", func() {
+				err := archive.Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "some-dir"),
+					filepath.Join(tempDir, "some-file"),
+				}))
+			})
+
+			it("unpackages the archive into the path but also strips the first component", func() {
+				err := archive.StripComponents(1).Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "some-nested-file"),
+				}))
+			})
+		})
+
+		context("when passed the reader of an rpm package", func() {
+			var (
+				archive vacation.Archive
+				tempDir string
+			)
+
+			it.Before(func() {
+				var err error
+				tempDir, err = os.MkdirTemp("", "vacation")
+				Expect(err).NotTo(HaveOccurred())
+
+				archive = vacation.NewArchive(bytes.NewReader(newRPMPackage(t, []cpioEntry{
+					{name: "./some-dir", mode: 0040755},
+					{name: "./some-dir/some-nested-file", mode: 0100755, content: "some-dir/some-nested-file"},
+					{name: "./some-file", mode: 0100755, content: "some-file"},
+				}, func(w io.Writer) io.WriteCloser {
+					return gzip.NewWriter(w)
+				})))
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(tempDir)).To(Succeed())
+			})
+
+			it("unpackages the archive into the path", func() {
+				err := archive.Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "some-dir"),
+					filepath.Join(tempDir, "some-file"),
+				}))
+			})
+
+			it("unpackages the archive into the path but also strips the first component", func() {
+				err := archive.StripComponents(1).Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "some-nested-file"),
+				}))
+			})
+		})
+
 		context("when passed the reader of an executable file", func() {
 			var (
 				archive vacation.Archive
@@ -0,0 +1,266 @@
+package vacation
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ulikunitz/xz"
+)
+
+// An ArchiveFormat is an output format supported by Archiver.
+type ArchiveFormat string
+
+const (
+	TarFormat     ArchiveFormat = "tar"
+	TarGzipFormat ArchiveFormat = "tar.gz"
+	TarXZFormat   ArchiveFormat = "tar.xz"
+	ZipFormat     ArchiveFormat = "zip"
+)
+
+// zipEpoch is the earliest modification time that can be represented in a zip
+// file header.
+var zipEpoch = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// An Archiver creates reproducible archives from the contents of a directory.
+// Entries are written in lexical order with their ownership normalized to
+// root and their modification time set to the value of SOURCE_DATE_EPOCH, or
+// the Unix epoch when it is unset, so that archiving the same content always
+// produces the same output. Symlinks are preserved and, in tar formats, so
+// are hard links.
+type Archiver struct {
+	sourceDir string
+	format    ArchiveFormat
+}
+
+// NewArchiver returns a new Archiver that archives the contents of sourceDir
+// as a tar file.
+func NewArchiver(sourceDir string) Archiver {
+	return Archiver{
+		sourceDir: sourceDir,
+		format:    TarFormat,
+	}
+}
+
+// WithFormat sets the format of the archive that is written.
+func (a Archiver) WithFormat(format ArchiveFormat) Archiver {
+	a.format = format
+	return a
+}
+
+// Archive writes an archive of the source directory to output.
+func (a Archiver) Archive(output io.Writer) error {
+	modTime, err := sourceDateEpoch()
+	if err != nil {
+		return err
+	}
+
+	switch a.format {
+	case TarFormat:
+		return a.writeTar(output, modTime)
+
+	case TarGzipFormat:
+		gw := gzip.NewWriter(output)
+		err = a.writeTar(gw, modTime)
+		if err != nil {
+			return err
+		}
+
+		return gw.Close()
+
+	case TarXZFormat:
+		xw, err := xz.NewWriter(output)
+		if err != nil {
+			return fmt.Errorf("failed to create xz writer: %w", err)
+		}
+
+		err = a.writeTar(xw, modTime)
+		if err != nil {
+			return err
+		}
+
+		return xw.Close()
+
+	case ZipFormat:
+		if modTime.Before(zipEpoch) {
+			modTime = zipEpoch
+		}
+
+		return a.writeZip(output, modTime)
+
+	default:
+		return fmt.Errorf("unsupported archive format: %q", a.format)
+	}
+}
+
+func (a Archiver) writeTar(output io.Writer, modTime time.Time) error {
+	tw := tar.NewWriter(output)
+
+	// Maps the device and inode of files with more than one link to the name of
+	// the first entry written for them.
+	inodes := map[[2]uint64]string{}
+
+	err := a.walk(func(name, path string, info fs.FileInfo) error {
+		var linkname string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			var err error
+			linkname, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(info, linkname)
+		if err != nil {
+			return err
+		}
+
+		hdr.Name = name
+		hdr.Uid, hdr.Gid = 0, 0
+		hdr.Uname, hdr.Gname = "", ""
+		hdr.ModTime = modTime
+		hdr.AccessTime, hdr.ChangeTime = time.Time{}, time.Time{}
+
+		if info.Mode().IsRegular() {
+			if dev, ino, nlink, ok := fileInode(info); ok && nlink > 1 {
+				inode := [2]uint64{dev, ino}
+				if target, ok := inodes[inode]; ok {
+					hdr.Typeflag = tar.TypeLink
+					hdr.Linkname = target
+					hdr.Size = 0
+				} else {
+					inodes[inode] = name
+				}
+			}
+		}
+
+		err = tw.WriteHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			return nil
+		}
+
+		return copyFile(tw, path)
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+func (a Archiver) writeZip(output io.Writer, modTime time.Time) error {
+	zw := zip.NewWriter(output)
+
+	err := a.walk(func(name, path string, info fs.FileInfo) error {
+		hdr := &zip.FileHeader{
+			Name:     name,
+			Method:   zip.Store,
+			Modified: modTime,
+		}
+		hdr.SetMode(info.Mode())
+
+		if info.Mode().IsRegular() {
+			hdr.Method = zip.Deflate
+		}
+
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			linkname, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.WriteString(w, linkname)
+			return err
+
+		case info.Mode().IsRegular():
+			return copyFile(w, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// walk calls fn for each file in the source directory, in lexical order, with
+// its slash separated name relative to the source directory. Directory names
+// have a trailing slash.
+func (a Archiver) walk(fn func(name, path string, info fs.FileInfo) error) error {
+	return filepath.Walk(a.sourceDir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to archive %q: %w", path, err)
+		}
+
+		rel, err := filepath.Rel(a.sourceDir, path)
+		if err != nil {
+			return err
+		}
+
+		if rel == "." {
+			return nil
+		}
+
+		name := filepath.ToSlash(rel)
+		switch {
+		case info.IsDir():
+			name += "/"
+		case info.Mode().IsRegular(), info.Mode()&fs.ModeSymlink != 0:
+		default:
+			return fmt.Errorf("failed to archive %q: unsupported file type %s", name, info.Mode().Type())
+		}
+
+		err = fn(name, path, info)
+		if err != nil {
+			return fmt.Errorf("failed to archive %q: %w", name, err)
+		}
+
+		return nil
+	})
+}
+
+func copyFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// sourceDateEpoch returns the time given in the SOURCE_DATE_EPOCH environment
+// variable, or the Unix epoch when it is unset.
+func sourceDateEpoch() (time.Time, error) {
+	value, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok || value == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse SOURCE_DATE_EPOCH: %w", err)
+	}
+
+	return time.Unix(seconds, 0).UTC(), nil
+}
@@ -0,0 +1,220 @@
+package vacation_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testArchiver(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		sourceDir string
+		tempDir   string
+	)
+
+	it.Before(func() {
+		var err error
+		sourceDir, err = os.MkdirTemp("", "source")
+		Expect(err).NotTo(HaveOccurred())
+
+		tempDir, err = os.MkdirTemp("", "vacation")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(sourceDir, "some-dir", "some-other-dir"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(sourceDir, "some-dir", "some-other-dir", "some-file"), []byte("some-file"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(sourceDir, "b-file"), []byte("b-file"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(sourceDir, "a-file"), []byte("a-file"), 0644)).To(Succeed())
+		Expect(os.Symlink("a-file", filepath.Join(sourceDir, "symlink"))).To(Succeed())
+		Expect(os.Link(filepath.Join(sourceDir, "b-file"), filepath.Join(sourceDir, "hardlink"))).To(Succeed())
+
+		Expect(os.Unsetenv("SOURCE_DATE_EPOCH")).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("SOURCE_DATE_EPOCH")).To(Succeed())
+		Expect(os.RemoveAll(sourceDir)).To(Succeed())
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	context("Archive", func() {
+		it("writes a tar archive with sorted and normalized entries", func() {
+			Expect(os.Setenv("SOURCE_DATE_EPOCH", "1600000000")).To(Succeed())
+
+			buffer := bytes.NewBuffer(nil)
+			err := vacation.NewArchiver(sourceDir).Archive(buffer)
+			Expect(err).NotTo(HaveOccurred())
+
+			var names []string
+			tr := tar.NewReader(buffer)
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+
+				names = append(names, hdr.Name)
+				Expect(hdr.Uid).To(Equal(0))
+				Expect(hdr.Gid).To(Equal(0))
+				Expect(hdr.Uname).To(BeEmpty())
+				Expect(hdr.Gname).To(BeEmpty())
+				Expect(hdr.ModTime).To(BeTemporally("==", time.Unix(1600000000, 0)))
+
+				switch hdr.Name {
+				case "b-file":
+					Expect(hdr.Typeflag).To(Equal(byte(tar.TypeReg)))
+					Expect(hdr.Mode).To(Equal(int64(0755)))
+				case "hardlink":
+					Expect(hdr.Typeflag).To(Equal(byte(tar.TypeLink)))
+					Expect(hdr.Linkname).To(Equal("b-file"))
+				case "symlink":
+					Expect(hdr.Typeflag).To(Equal(byte(tar.TypeSymlink)))
+					Expect(hdr.Linkname).To(Equal("a-file"))
+				}
+			}
+
+			Expect(names).To(Equal([]string{
+				"a-file",
+				"b-file",
+				"hardlink",
+				"some-dir/",
+				"some-dir/some-other-dir/",
+				"some-dir/some-other-dir/some-file",
+				"symlink",
+			}))
+		})
+
+		it("writes the same archive regardless of file modification times", func() {
+			first := bytes.NewBuffer(nil)
+			Expect(vacation.NewArchiver(sourceDir).WithFormat(vacation.TarGzipFormat).Archive(first)).To(Succeed())
+
+			Expect(os.Chtimes(filepath.Join(sourceDir, "a-file"), time.Now(), time.Now().Add(time.Hour))).To(Succeed())
+
+			second := bytes.NewBuffer(nil)
+			Expect(vacation.NewArchiver(sourceDir).WithFormat(vacation.TarGzipFormat).Archive(second)).To(Succeed())
+
+			Expect(first.Bytes()).To(Equal(second.Bytes()))
+		})
+
+		for _, format := range []vacation.ArchiveFormat{vacation.TarFormat, vacation.TarGzipFormat, vacation.TarXZFormat, vacation.ZipFormat} {
+			format := format
+
+			context("when the format is "+string(format), func() {
+				it("round-trips through Archive.Decompress", func() {
+					buffer := bytes.NewBuffer(nil)
+					err := vacation.NewArchiver(sourceDir).WithFormat(format).Archive(buffer)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = vacation.NewArchive(buffer).Decompress(tempDir)
+					Expect(err).NotTo(HaveOccurred())
+
+					files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(ConsistOf([]string{
+						filepath.Join(tempDir, "a-file"),
+						filepath.Join(tempDir, "b-file"),
+						filepath.Join(tempDir, "hardlink"),
+						filepath.Join(tempDir, "some-dir"),
+						filepath.Join(tempDir, "symlink"),
+					}))
+
+					content, err := os.ReadFile(filepath.Join(tempDir, "some-dir", "some-other-dir", "some-file"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("some-file"))
+
+					content, err = os.ReadFile(filepath.Join(tempDir, "hardlink"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("b-file"))
+
+					info, err := os.Stat(filepath.Join(tempDir, "b-file"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(info.Mode()).To(Equal(os.FileMode(0755)))
+
+					link, err := os.Readlink(filepath.Join(tempDir, "symlink"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(link).To(Equal("a-file"))
+				})
+			})
+		}
+
+		context("when the format is zip", func() {
+			it("clamps modification times to the earliest time zip supports", func() {
+				buffer := bytes.NewBuffer(nil)
+				err := vacation.NewArchiver(sourceDir).WithFormat(vacation.ZipFormat).Archive(buffer)
+				Expect(err).NotTo(HaveOccurred())
+
+				zr, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+				Expect(err).NotTo(HaveOccurred())
+
+				var names []string
+				for _, f := range zr.File {
+					names = append(names, f.Name)
+					Expect(f.Modified).To(BeTemporally("==", time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)))
+				}
+
+				Expect(names).To(Equal([]string{
+					"a-file",
+					"b-file",
+					"hardlink",
+					"some-dir/",
+					"some-dir/some-other-dir/",
+					"some-dir/some-other-dir/some-file",
+					"symlink",
+				}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when SOURCE_DATE_EPOCH is not an integer", func() {
+				it("returns an error", func() {
+					Expect(os.Setenv("SOURCE_DATE_EPOCH", "some-time")).To(Succeed())
+
+					err := vacation.NewArchiver(sourceDir).Archive(bytes.NewBuffer(nil))
+					Expect(err).To(MatchError(ContainSubstring("failed to parse SOURCE_DATE_EPOCH")))
+				})
+			})
+
+			context("when the format is not supported", func() {
+				it("returns an error", func() {
+					err := vacation.NewArchiver(sourceDir).WithFormat("rar").Archive(bytes.NewBuffer(nil))
+					Expect(err).To(MatchError(`unsupported archive format: "rar"`))
+				})
+			})
+
+			context("when the source directory does not exist", func() {
+				it("returns an error", func() {
+					err := vacation.NewArchiver(filepath.Join(sourceDir, "missing")).Archive(bytes.NewBuffer(nil))
+					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				})
+			})
+
+			context("when a file cannot be read", func() {
+				it.Before(func() {
+					if os.Geteuid() == 0 {
+						t.Skip("files can always be read by root")
+					}
+
+					Expect(os.Chmod(filepath.Join(sourceDir, "a-file"), 0000)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := vacation.NewArchiver(sourceDir).Archive(bytes.NewBuffer(nil))
+					Expect(err).To(MatchError(ContainSubstring(`failed to archive "a-file"`)))
+					Expect(err).To(MatchError(ContainSubstring("permission denied")))
+				})
+			})
+		})
+	})
+}
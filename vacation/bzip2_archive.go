@@ -7,9 +7,10 @@ import (
 
 // A Bzip2Archive decompresses bzip2 files from an input stream.
 type Bzip2Archive struct {
-	reader     io.Reader
-	components int
-	name       string
+	extractOptions
+
+	reader io.Reader
+	name   string
 }
 
 // NewBzip2Archive returns a new Bzip2Archive that reads from inputReader.
@@ -20,7 +21,9 @@ func NewBzip2Archive(inputReader io.Reader) Bzip2Archive {
 // Decompress reads from Bzip2Archive and writes files into the destination
 // specified.
 func (bz Bzip2Archive) Decompress(destination string) error {
-	return NewArchive(bzip2.NewReader(bz.reader)).WithName(bz.name).StripComponents(bz.components).Decompress(destination)
+	limits, reader := bz.limits.count(bz.reader)
+	progress, reader := trackProgress(bz.progress, reader)
+	return Archive{extractOptions: bz.forward(limits, progress), reader: bzip2.NewReader(reader), name: bz.name}.Decompress(destination)
 }
 
 // StripComponents behaves like the --strip-components flag on tar command
@@ -36,3 +39,50 @@ func (bz Bzip2Archive) WithName(name string) Bzip2Archive {
 	bz.name = name
 	return bz
 }
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (bz Bzip2Archive) WithLimits(limits Limits) Bzip2Archive {
+	bz.limits = limits
+	return bz
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs.
+func (bz Bzip2Archive) WithIncludes(globs ...string) Bzip2Archive {
+	bz.includes = globs
+	return bz
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs.
+func (bz Bzip2Archive) WithExcludes(globs ...string) Bzip2Archive {
+	bz.excludes = globs
+	return bz
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files.
+func (bz Bzip2Archive) WithPreserve(options PreserveOptions) Bzip2Archive {
+	bz.preserve = options
+	return bz
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (bz Bzip2Archive) WithManifest(manifest *Manifest) Bzip2Archive {
+	bz.manifest = manifest
+	return bz
+}
+
+// WithLinkPolicy sets the policy that symlinks and hard links must satisfy
+// before they are created.
+func (bz Bzip2Archive) WithLinkPolicy(policy LinkPolicy) Bzip2Archive {
+	bz.linkPolicy = policy
+	return bz
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (bz Bzip2Archive) WithProgress(reporter ProgressReporter) Bzip2Archive {
+	bz.progress = reporter
+	return bz
+}
@@ -0,0 +1,146 @@
+package vacation
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	arMagic        = "!<arch>\n"
+	arHeaderLength = 60
+)
+
+// A DebArchive decompresses Debian .deb packages from an input stream. The
+// package is an ar archive and only the contents of its data.tar member,
+// which may itself be compressed, are extracted.
+type DebArchive struct {
+	extractOptions
+
+	reader io.Reader
+}
+
+// NewDebArchive returns a new DebArchive that reads from inputReader.
+func NewDebArchive(inputReader io.Reader) DebArchive {
+	return DebArchive{reader: inputReader}
+}
+
+// Decompress reads from DebArchive and writes the files in its data.tar
+// member into the destination specified.
+func (da DebArchive) Decompress(destination string) error {
+	limits, input := da.limits.count(da.reader)
+	progress, input := trackProgress(da.progress, input)
+	reader := bufio.NewReader(input)
+
+	magic := make([]byte, len(arMagic))
+	_, err := io.ReadFull(reader, magic)
+	if err != nil || string(magic) != arMagic {
+		return errors.New("failed to read deb archive: invalid ar header")
+	}
+
+	for {
+		name, size, err := readArHeader(reader)
+		if err == io.EOF {
+			return errors.New("failed to read deb archive: data.tar member not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read deb archive: %w", err)
+		}
+
+		if strings.HasPrefix(name, "data.tar") {
+			return Archive{extractOptions: da.forward(limits, progress), reader: io.LimitReader(reader, size)}.Decompress(destination)
+		}
+
+		// Members are padded to an even number of bytes.
+		_, err = io.CopyN(io.Discard, reader, size+size%2)
+		if err != nil {
+			return fmt.Errorf("failed to read deb archive: %w", err)
+		}
+	}
+}
+
+// StripComponents behaves like the --strip-components flag on tar command
+// removing the first n levels from the final decompression destination.
+func (da DebArchive) StripComponents(components int) DebArchive {
+	da.components = components
+	return da
+}
+
+// readArHeader reads the header of the next ar member and returns the member
+// name and size.
+func readArHeader(reader io.Reader) (string, int64, error) {
+	header := make([]byte, arHeaderLength)
+	n, err := io.ReadFull(reader, header)
+	if err != nil {
+		if n == 0 && err == io.EOF {
+			return "", 0, io.EOF
+		}
+
+		return "", 0, fmt.Errorf("invalid member header: %s", err)
+	}
+
+	if !bytes.Equal(header[58:60], []byte("`\n")) {
+		return "", 0, errors.New("invalid member header: bad terminator")
+	}
+
+	// GNU ar terminates member names with a "/".
+	name := strings.TrimSuffix(strings.TrimSpace(string(header[0:16])), "/")
+
+	size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+	if err != nil || size < 0 {
+		return "", 0, fmt.Errorf("invalid member size for %q", name)
+	}
+
+	return name, size, nil
+}
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (da DebArchive) WithLimits(limits Limits) DebArchive {
+	da.limits = limits
+	return da
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs.
+func (da DebArchive) WithIncludes(globs ...string) DebArchive {
+	da.includes = globs
+	return da
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs.
+func (da DebArchive) WithExcludes(globs ...string) DebArchive {
+	da.excludes = globs
+	return da
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files.
+func (da DebArchive) WithPreserve(options PreserveOptions) DebArchive {
+	da.preserve = options
+	return da
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (da DebArchive) WithManifest(manifest *Manifest) DebArchive {
+	da.manifest = manifest
+	return da
+}
+
+// WithLinkPolicy sets the policy that symlinks and hard links must satisfy
+// before they are created.
+func (da DebArchive) WithLinkPolicy(policy LinkPolicy) DebArchive {
+	da.linkPolicy = policy
+	return da
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (da DebArchive) WithProgress(reporter ProgressReporter) DebArchive {
+	da.progress = reporter
+	return da
+}
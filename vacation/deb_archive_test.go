@@ -0,0 +1,198 @@
+package vacation_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+// newDebPackage returns an ar archive laid out like a Debian package with the
+// given members following the debian-binary member.
+func newDebPackage(t *testing.T, members map[string][]byte, order ...string) []byte {
+	buffer := bytes.NewBuffer([]byte("!<arch>\n"))
+	for _, name := range append([]string{"debian-binary"}, order...) {
+		content, ok := members[name]
+		if !ok {
+			content = []byte("2.0\n")
+		}
+
+		_, err := fmt.Fprintf(buffer, "%-16s%-12d%-6d%-6d%-8s%-10d`\n", name+"/", 0, 0, 0, "100644", len(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buffer.Write(content)
+		if len(content)%2 != 0 {
+			buffer.WriteByte('\n')
+		}
+	}
+
+	return buffer.Bytes()
+}
+
+func testDebArchive(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("Decompress", func() {
+		var (
+			tempDir    string
+			debArchive vacation.DebArchive
+		)
+
+		it.Before(func() {
+			var err error
+			tempDir, err = os.MkdirTemp("", "vacation")
+			Expect(err).NotTo(HaveOccurred())
+
+			control := bytes.NewBuffer(nil)
+			gw := gzip.NewWriter(control)
+			tw := tar.NewWriter(gw)
+			Expect(tw.WriteHeader(&tar.Header{Name: "./control", Mode: 0644, Size: int64(len("Package: some-package\n"))})).To(Succeed())
+			_, err = tw.Write([]byte("Package: some-package\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tw.Close()).To(Succeed())
+			Expect(gw.Close()).To(Succeed())
+
+			data := bytes.NewBuffer(nil)
+			gw = gzip.NewWriter(data)
+			tw = tar.NewWriter(gw)
+
+			Expect(tw.WriteHeader(&tar.Header{Name: "./usr", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+			Expect(tw.WriteHeader(&tar.Header{Name: "./usr/bin", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+
+			Expect(tw.WriteHeader(&tar.Header{Name: "./usr/bin/some-binary", Mode: 0755, Size: int64(len("some-binary"))})).To(Succeed())
+			_, err = tw.Write([]byte("some-binary"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tw.WriteHeader(&tar.Header{Name: "./usr/bin/some-symlink", Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: "some-binary"})).To(Succeed())
+
+			Expect(tw.WriteHeader(&tar.Header{Name: "./some-file", Mode: 0644, Size: int64(len("some-file"))})).To(Succeed())
+			_, err = tw.Write([]byte("some-file"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tw.Close()).To(Succeed())
+			Expect(gw.Close()).To(Succeed())
+
+			debArchive = vacation.NewDebArchive(bytes.NewReader(newDebPackage(t, map[string][]byte{
+				"control.tar.gz": control.Bytes(),
+				"data.tar.gz":    data.Bytes(),
+			}, "control.tar.gz", "data.tar.gz")))
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(tempDir)).To(Succeed())
+		})
+
+		it("unpackages the data member of the package into the path", func() {
+			err := debArchive.Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf([]string{
+				filepath.Join(tempDir, "usr"),
+				filepath.Join(tempDir, "some-file"),
+			}))
+
+			info, err := os.Stat(filepath.Join(tempDir, "usr", "bin", "some-binary"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0755)))
+
+			data, err := os.ReadFile(filepath.Join(tempDir, "usr", "bin", "some-symlink"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("some-binary"))
+		})
+
+		it("unpackages the data member into the path but also strips the first component", func() {
+			err := debArchive.StripComponents(1).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf([]string{
+				filepath.Join(tempDir, "bin"),
+			}))
+
+			Expect(filepath.Join(tempDir, "bin", "some-binary")).To(BeARegularFile())
+		})
+
+		context("when the data member is not compressed", func() {
+			it.Before(func() {
+				data := bytes.NewBuffer(nil)
+				tw := tar.NewWriter(data)
+				Expect(tw.WriteHeader(&tar.Header{Name: "./some-file", Mode: 0644, Size: int64(len("some-file"))})).To(Succeed())
+				_, err := tw.Write([]byte("some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tw.Close()).To(Succeed())
+
+				debArchive = vacation.NewDebArchive(bytes.NewReader(newDebPackage(t, map[string][]byte{
+					"control.tar": []byte("odd"),
+					"data.tar":    data.Bytes(),
+				}, "control.tar", "data.tar")))
+			})
+
+			it("unpackages the data member into the path", func() {
+				err := debArchive.Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(tempDir, "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-file"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the input is not an ar archive", func() {
+				it("returns an error", func() {
+					err := vacation.NewDebArchive(bytes.NewBuffer([]byte("something"))).Decompress(tempDir)
+					Expect(err).To(MatchError("failed to read deb archive: invalid ar header"))
+				})
+			})
+
+			context("when the package does not have a data member", func() {
+				it("returns an error", func() {
+					err := vacation.NewDebArchive(bytes.NewReader(newDebPackage(t, nil))).Decompress(tempDir)
+					Expect(err).To(MatchError("failed to read deb archive: data.tar member not found"))
+				})
+			})
+
+			context("when a member header is malformed", func() {
+				it("returns an error", func() {
+					err := vacation.NewDebArchive(bytes.NewBufferString("!<arch>\nsome-truncated-header")).Decompress(tempDir)
+					Expect(err).To(MatchError(ContainSubstring("failed to read deb archive: invalid member header")))
+				})
+			})
+
+			context("when the data member contains a zip-slip path", func() {
+				it.Before(func() {
+					data := bytes.NewBuffer(nil)
+					tw := tar.NewWriter(data)
+					Expect(tw.WriteHeader(&tar.Header{Name: "../some-file", Mode: 0644, Size: int64(len("some-file"))})).To(Succeed())
+					_, err := tw.Write([]byte("some-file"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(tw.Close()).To(Succeed())
+
+					debArchive = vacation.NewDebArchive(bytes.NewReader(newDebPackage(t, map[string][]byte{
+						"data.tar": data.Bytes(),
+					}, "data.tar")))
+				})
+
+				it("returns an error", func() {
+					err := debArchive.Decompress(tempDir)
+					Expect(err).To(MatchError(ContainSubstring("illegal file path \"../some-file\"")))
+				})
+			})
+		})
+	})
+}
@@ -10,8 +10,11 @@ import (
 // file name specified by the option `Executable.WithName()` (or defaults to
 // `artifact`) in the destination directory with executable permissions (0755).
 type Executable struct {
-	reader io.Reader
-	name   string
+	reader   io.Reader
+	name     string
+	limits   Limits
+	manifest *Manifest
+	progress ProgressReporter
 }
 
 // NewExecutable returns a new Executable that reads from inputReader.
@@ -25,15 +28,24 @@ func NewExecutable(inputReader io.Reader) Executable {
 // Decompress copies the reader contents into the destination specified and
 // sets executable permissions.
 func (e Executable) Decompress(destination string) error {
-	file, err := os.Create(filepath.Join(destination, e.name))
+	limits, reader := e.limits.count(e.reader)
+	progress, reader := trackProgress(e.progress, reader)
+	limiter := newLimiter(limits)
+
+	path := filepath.Join(destination, e.name)
+	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
+	limiter.track(path)
+	progress.entry(e.name)
 
-	_, err = io.Copy(file, e.reader)
+	recorder := newManifestRecorder(e.manifest, destination)
+	content := recorder.content(reader)
+	err = limiter.copy(file, content, e.name)
 	if err != nil {
-		return err
+		return limiter.cleanup(err)
 	}
 
 	err = os.Chmod(filepath.Join(destination, e.name), 0755)
@@ -41,6 +53,9 @@ func (e Executable) Decompress(destination string) error {
 		return err
 	}
 
+	recorder.file(path, 0755, content)
+	recorder.commit()
+
 	return nil
 }
 
@@ -52,3 +67,21 @@ func (e Executable) WithName(name string) Executable {
 	}
 	return e
 }
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (e Executable) WithLimits(limits Limits) Executable {
+	e.limits = limits
+	return e
+}
+
+// WithManifest records the file that is written in manifest.
+func (e Executable) WithManifest(manifest *Manifest) Executable {
+	e.manifest = manifest
+	return e
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (e Executable) WithProgress(reporter ProgressReporter) Executable {
+	e.progress = reporter
+	return e
+}
@@ -0,0 +1,25 @@
+package vacation
+
+// extractOptions are the options shared by the archive types that extract the
+// entries of an archive. Each of those types embeds them so that they can be
+// passed on, using forward, to the archive that extracts the decompressed
+// content.
+type extractOptions struct {
+	components int
+	limits     Limits
+	includes   []string
+	excludes   []string
+	preserve   PreserveOptions
+	manifest   *Manifest
+	linkPolicy LinkPolicy
+	progress   ProgressReporter
+}
+
+// forward returns the options to give to an inner archive, with the limits
+// and progress replaced by the ones that are already counting the input of
+// the outer archive.
+func (o extractOptions) forward(limits Limits, progress ProgressReporter) extractOptions {
+	o.limits = limits
+	o.progress = progress
+	return o
+}
@@ -8,9 +8,10 @@ import (
 
 // A GzipArchive decompresses gzipped files from an input stream.
 type GzipArchive struct {
-	reader     io.Reader
-	components int
-	name       string
+	extractOptions
+
+	reader io.Reader
+	name   string
 }
 
 // NewGzipArchive returns a new GzipArchive that reads from inputReader.
@@ -21,12 +22,15 @@ func NewGzipArchive(inputReader io.Reader) GzipArchive {
 // Decompress reads from GzipArchive and writes files into the destination
 // specified.
 func (gz GzipArchive) Decompress(destination string) error {
-	gzr, err := gzip.NewReader(gz.reader)
+	limits, reader := gz.limits.count(gz.reader)
+	progress, reader := trackProgress(gz.progress, reader)
+
+	gzr, err := gzip.NewReader(reader)
 	if err != nil {
 		return fmt.Errorf("failed to create gzip reader: %w", err)
 	}
 
-	return NewArchive(gzr).WithName(gz.name).StripComponents(gz.components).Decompress(destination)
+	return Archive{extractOptions: gz.forward(limits, progress), reader: gzr, name: gz.name}.Decompress(destination)
 }
 
 // StripComponents behaves like the --strip-components flag on tar command
@@ -42,3 +46,50 @@ func (gz GzipArchive) WithName(name string) GzipArchive {
 	gz.name = name
 	return gz
 }
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (gz GzipArchive) WithLimits(limits Limits) GzipArchive {
+	gz.limits = limits
+	return gz
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs.
+func (gz GzipArchive) WithIncludes(globs ...string) GzipArchive {
+	gz.includes = globs
+	return gz
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs.
+func (gz GzipArchive) WithExcludes(globs ...string) GzipArchive {
+	gz.excludes = globs
+	return gz
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files.
+func (gz GzipArchive) WithPreserve(options PreserveOptions) GzipArchive {
+	gz.preserve = options
+	return gz
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (gz GzipArchive) WithManifest(manifest *Manifest) GzipArchive {
+	gz.manifest = manifest
+	return gz
+}
+
+// WithLinkPolicy sets the policy that symlinks and hard links must satisfy
+// before they are created.
+func (gz GzipArchive) WithLinkPolicy(policy LinkPolicy) GzipArchive {
+	gz.linkPolicy = policy
+	return gz
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (gz GzipArchive) WithProgress(reporter ProgressReporter) GzipArchive {
+	gz.progress = reporter
+	return gz
+}
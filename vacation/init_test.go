@@ -10,13 +10,24 @@ import (
 func TestVacation(t *testing.T) {
 	suite := spec.New("vacation", spec.Report(report.Terminal{}))
 	suite("Archive", testArchive)
+	suite("Archiver", testArchiver)
 	suite("Bzip2Archive", testBzip2Archive)
+	suite("DebArchive", testDebArchive)
 	suite("Executable", testExecutable)
 	suite("GzipArchive", testGzipArchive)
+	suite("LZ4Archive", testLZ4Archive)
+	suite("Limits", testLimits)
+	suite("LinkPolicy", testLinkPolicy)
+	suite("Manifest", testManifest)
 	suite("LinkSorting", testLinkSorting)
+	suite("Metadata", testMetadata)
 	suite("NopArchive", testNopArchive)
+	suite("PathFilter", testPathFilter)
+	suite("Progress", testProgress)
+	suite("RPMArchive", testRPMArchive)
 	suite("TarArchive", testTarArchive)
 	suite("XZArchive", testXZArchive)
 	suite("ZipArchive", testZipArchive)
+	suite("ZstdArchive", testZstdArchive)
 	suite.Run(t)
 }
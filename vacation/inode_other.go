@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package vacation
+
+import (
+	"os"
+)
+
+// fileInode reports that inode numbers are not available on this platform, so
+// hard links are archived as separate files.
+func fileInode(info os.FileInfo) (dev, ino, nlink uint64, ok bool) {
+	return 0, 0, 0, false
+}
@@ -0,0 +1,20 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package vacation
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileInode returns the device and inode numbers of the file described by
+// info, along with its number of hard links.
+func fileInode(info os.FileInfo) (dev, ino, nlink uint64, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	return uint64(stat.Dev), uint64(stat.Ino), uint64(stat.Nlink), true
+}
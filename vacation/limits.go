@@ -0,0 +1,197 @@
+package vacation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	LimitTotalSize        = "total size"
+	LimitEntries          = "entries"
+	LimitFileSize         = "file size"
+	LimitCompressionRatio = "compression ratio"
+)
+
+// Limits bounds the resources that decompressing an archive may consume. A
+// zero value for any of the limits means that it is not enforced.
+//
+// For zip files that are spooled to a temporary file, the compressed input is
+// also limited to MaxTotalSize as it must be buffered before it can be read.
+type Limits struct {
+	// MaxTotalSize is the maximum number of bytes written across all files.
+	MaxTotalSize int64
+
+	// MaxEntries is the maximum number of entries in the archive.
+	MaxEntries int
+
+	// MaxFileSize is the maximum number of bytes written to a single file.
+	MaxFileSize int64
+
+	// MaxCompressionRatio is the maximum ratio of the number of bytes written
+	// to the number of bytes read from the input stream.
+	MaxCompressionRatio float64
+
+	// compressed counts the bytes read from the outermost input stream so that
+	// the compression ratio can be enforced by nested archives.
+	compressed *countingReader
+}
+
+// A LimitError is returned when decompressing an archive exceeds one of its
+// Limits. Any files written before the limit was exceeded are removed.
+type LimitError struct {
+	// Limit is the name of the limit that was exceeded, one of LimitTotalSize,
+	// LimitEntries, LimitFileSize or LimitCompressionRatio.
+	Limit string
+
+	// Max is the value of the limit that was exceeded.
+	Max float64
+
+	// Path is the path of the archive entry being extracted when the limit was
+	// exceeded.
+	Path string
+}
+
+func (e *LimitError) Error() string {
+	message := fmt.Sprintf("archive exceeds the maximum %s of %s", e.Limit, strconv.FormatFloat(e.Max, 'f', -1, 64))
+	if e.Path != "" {
+		message = fmt.Sprintf("%s while extracting %q", message, e.Path)
+	}
+
+	return message
+}
+
+func (l Limits) enabled() bool {
+	return l.MaxTotalSize > 0 || l.MaxEntries > 0 || l.MaxFileSize > 0 || l.MaxCompressionRatio > 0
+}
+
+// count wraps the input stream of the outermost archive so that the bytes read
+// from it are counted when a compression ratio is enforced.
+func (l Limits) count(reader io.Reader) (Limits, io.Reader) {
+	if l.MaxCompressionRatio <= 0 || l.compressed != nil {
+		return l, reader
+	}
+
+	l.compressed = &countingReader{reader: reader}
+	return l, l.compressed
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count += int64(n)
+	return n, err
+}
+
+// A limiter enforces Limits while an archive is extracted and keeps track of
+// the files that it creates so that they can be removed when a limit is
+// exceeded.
+type limiter struct {
+	limits  Limits
+	entries int
+	total   int64
+	created []string
+}
+
+func newLimiter(limits Limits) *limiter {
+	return &limiter{limits: limits}
+}
+
+// entry records that an entry is being extracted.
+func (l *limiter) entry(path string) error {
+	l.entries++
+	if l.limits.MaxEntries > 0 && l.entries > l.limits.MaxEntries {
+		return &LimitError{Limit: LimitEntries, Max: float64(l.limits.MaxEntries), Path: path}
+	}
+
+	return nil
+}
+
+// copy copies from src to the file at dst, which is the extracted path of the
+// entry at path, enforcing the size and compression ratio limits.
+func (l *limiter) copy(dst io.Writer, src io.Reader, path string) error {
+	_, err := io.Copy(&limitWriter{writer: dst, limiter: l, path: path}, src)
+	return err
+}
+
+// track records a path created during extraction.
+func (l *limiter) track(path string) {
+	if l.limits.enabled() {
+		l.created = append(l.created, path)
+	}
+}
+
+// mkdirAll creates a directory along with any parents and tracks the
+// outermost directory that did not already exist.
+func (l *limiter) mkdirAll(path string) error {
+	if l.limits.enabled() {
+		missing := ""
+		for dir := path; ; dir = filepath.Dir(dir) {
+			_, err := os.Lstat(dir)
+			if err == nil || !errors.Is(err, os.ErrNotExist) || dir == filepath.Dir(dir) {
+				break
+			}
+			missing = dir
+		}
+
+		if missing != "" {
+			l.created = append(l.created, missing)
+		}
+	}
+
+	return os.MkdirAll(path, os.ModePerm)
+}
+
+// cleanup removes the paths created during extraction when err is a
+// LimitError. It returns err.
+func (l *limiter) cleanup(err error) error {
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		return err
+	}
+
+	for i := len(l.created) - 1; i >= 0; i-- {
+		// Errors are ignored as the limit error is more useful to the caller.
+		_ = os.RemoveAll(l.created[i])
+	}
+	l.created = nil
+
+	return err
+}
+
+type limitWriter struct {
+	writer  io.Writer
+	limiter *limiter
+	path    string
+	written int64
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	limits := w.limiter.limits
+	size := int64(len(p))
+
+	if limits.MaxFileSize > 0 && w.written+size > limits.MaxFileSize {
+		return 0, &LimitError{Limit: LimitFileSize, Max: float64(limits.MaxFileSize), Path: w.path}
+	}
+
+	if limits.MaxTotalSize > 0 && w.limiter.total+size > limits.MaxTotalSize {
+		return 0, &LimitError{Limit: LimitTotalSize, Max: float64(limits.MaxTotalSize), Path: w.path}
+	}
+
+	if limits.compressed != nil && float64(w.limiter.total+size) > limits.MaxCompressionRatio*float64(limits.compressed.count) {
+		return 0, &LimitError{Limit: LimitCompressionRatio, Max: limits.MaxCompressionRatio, Path: w.path}
+	}
+
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	w.limiter.total += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,178 @@
+package vacation_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testLimits(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		tempDir string
+		tarball []byte
+		zipball []byte
+	)
+
+	it.Before(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "vacation")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(tempDir, "existing-file"), []byte("existing-file"), 0644)).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(tempDir, "existing-dir"), os.ModePerm)).To(Succeed())
+
+		files := []struct {
+			name    string
+			content []byte
+		}{
+			{name: "some-dir/some-file", content: bytes.Repeat([]byte("a"), 1024)},
+			{name: "existing-dir/some-file", content: bytes.Repeat([]byte("b"), 1024)},
+			{name: "large-file", content: bytes.Repeat([]byte("c"), 64*1024)},
+		}
+
+		buffer := bytes.NewBuffer(nil)
+		gw := gzip.NewWriter(buffer)
+		tw := tar.NewWriter(gw)
+		for _, file := range files {
+			Expect(tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.content))})).To(Succeed())
+			_, err = tw.Write(file.content)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(tw.Close()).To(Succeed())
+		Expect(gw.Close()).To(Succeed())
+		tarball = buffer.Bytes()
+
+		buffer = bytes.NewBuffer(nil)
+		zw := zip.NewWriter(buffer)
+		for _, file := range files {
+			w, err := zw.Create(file.name)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write(file.content)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(zw.Close()).To(Succeed())
+		zipball = buffer.Bytes()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	expectCleanedUp := func() {
+		files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(ConsistOf([]string{
+			filepath.Join(tempDir, "existing-dir"),
+			filepath.Join(tempDir, "existing-file"),
+		}))
+
+		Expect(filepath.Join(tempDir, "existing-dir", "some-file")).NotTo(BeAnExistingFile())
+	}
+
+	context("when the archive is within the limits", func() {
+		it("decompresses the archive", func() {
+			err := vacation.NewArchive(bytes.NewReader(tarball)).WithLimits(vacation.Limits{
+				MaxTotalSize:        1024 * 1024,
+				MaxEntries:          3,
+				MaxFileSize:         64 * 1024,
+				MaxCompressionRatio: 1000,
+			}).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(tempDir, "some-dir", "some-file")).To(BeARegularFile())
+			Expect(filepath.Join(tempDir, "existing-dir", "some-file")).To(BeARegularFile())
+			Expect(filepath.Join(tempDir, "large-file")).To(BeARegularFile())
+		})
+	})
+
+	for _, format := range []string{"tar", "zip"} {
+		format := format
+
+		context("when a "+format+" archive exceeds", func() {
+			var archive func() vacation.Archive
+
+			it.Before(func() {
+				archive = func() vacation.Archive {
+					if format == "zip" {
+						return vacation.NewArchive(bytes.NewReader(zipball))
+					}
+
+					return vacation.NewArchive(bytes.NewReader(tarball))
+				}
+			})
+
+			context("the maximum total size", func() {
+				it("returns a limit error and removes the extracted files", func() {
+					err := archive().WithLimits(vacation.Limits{MaxTotalSize: 32 * 1024}).Decompress(tempDir)
+
+					var limitErr *vacation.LimitError
+					Expect(errors.As(err, &limitErr)).To(BeTrue())
+					Expect(limitErr.Limit).To(Equal(vacation.LimitTotalSize))
+					Expect(limitErr.Max).To(Equal(float64(32 * 1024)))
+					Expect(err).To(MatchError(ContainSubstring("archive exceeds the maximum total size of 32768")))
+
+					expectCleanedUp()
+				})
+			})
+
+			context("the maximum number of entries", func() {
+				it("returns a limit error and removes the extracted files", func() {
+					err := archive().WithLimits(vacation.Limits{MaxEntries: 2}).Decompress(tempDir)
+					Expect(err).To(MatchError(`archive exceeds the maximum entries of 2 while extracting "large-file"`))
+
+					expectCleanedUp()
+				})
+			})
+
+			context("the maximum file size", func() {
+				it("returns a limit error and removes the extracted files", func() {
+					err := archive().WithLimits(vacation.Limits{MaxFileSize: 2048}).Decompress(tempDir)
+					Expect(err).To(MatchError(`archive exceeds the maximum file size of 2048 while extracting "large-file"`))
+
+					expectCleanedUp()
+				})
+			})
+
+			context("the maximum compression ratio", func() {
+				it("returns a limit error and removes the extracted files", func() {
+					err := archive().WithLimits(vacation.Limits{MaxCompressionRatio: 1.5}).Decompress(tempDir)
+
+					var limitErr *vacation.LimitError
+					Expect(errors.As(err, &limitErr)).To(BeTrue())
+					Expect(limitErr.Limit).To(Equal(vacation.LimitCompressionRatio))
+					Expect(err).To(MatchError(ContainSubstring("archive exceeds the maximum compression ratio of 1.5")))
+
+					expectCleanedUp()
+				})
+			})
+		})
+	}
+
+	context("when a single compressed file exceeds the maximum file size", func() {
+		it("returns a limit error and removes the file", func() {
+			buffer := bytes.NewBuffer(nil)
+			gw := gzip.NewWriter(buffer)
+			_, err := gw.Write(bytes.Repeat([]byte("some-text\n"), 10*1024))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gw.Close()).To(Succeed())
+
+			err = vacation.NewArchive(buffer).WithName("some-file").WithLimits(vacation.Limits{MaxFileSize: 1024}).Decompress(tempDir)
+			Expect(err).To(MatchError(`archive exceeds the maximum file size of 1024 while extracting "some-file"`))
+
+			Expect(filepath.Join(tempDir, "some-file")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempDir, "existing-file")).To(BeARegularFile())
+		})
+	})
+}
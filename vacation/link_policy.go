@@ -0,0 +1,128 @@
+package vacation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A LinkPolicy controls how the symlinks and hard links in an archive are
+// checked before they are created. Policies can be combined, for example
+// LinkPolicyStrict|LinkPolicyAllowDangling only creates links that point
+// inside the destination but does not require their targets to exist.
+//
+// The zero value keeps the default behavior, where a symlink is created when
+// its target exists and a hard link when its target was extracted.
+type LinkPolicy uint8
+
+const (
+	// LinkPolicyStrict requires that symlinks and hard links resolve to a path
+	// inside the destination directory.
+	LinkPolicyStrict LinkPolicy = 1 << iota
+
+	// LinkPolicyAllowDangling creates symlinks whose targets do not exist.
+	LinkPolicyAllowDangling
+
+	// LinkPolicyRewriteAbsolute rewrites absolute symlink targets to be
+	// relative to the destination directory, so that a link to /usr/lib/libfoo
+	// points at usr/lib/libfoo within the destination instead of at the host.
+	LinkPolicyRewriteAbsolute
+)
+
+// rewrite applies LinkPolicyRewriteAbsolute to the symlinks in an archive
+// extracted to destination.
+func (p LinkPolicy) rewrite(destination string, symlinks []link) []link {
+	if p&LinkPolicyRewriteAbsolute == 0 {
+		return symlinks
+	}
+
+	for i, l := range symlinks {
+		if !filepath.IsAbs(l.name) {
+			continue
+		}
+
+		name, err := filepath.Rel(filepath.Dir(l.path), filepath.Join(destination, l.name))
+		if err == nil {
+			symlinks[i].name = name
+		}
+	}
+
+	return symlinks
+}
+
+// evaluateSymlink checks that the target of a symlink can be resolved.
+func (p LinkPolicy) evaluateSymlink(l link) error {
+	// By default, absolute targets are evaluated relative to the directory of
+	// the symlink.
+	target := linknameFullPath(l.path, l.name)
+	if p != 0 && filepath.IsAbs(l.name) {
+		target = filepath.Clean(l.name)
+	}
+
+	_, err := filepath.EvalSymlinks(target)
+	if err != nil && p&LinkPolicyAllowDangling != 0 && errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// containsSymlink reports whether the target of a symlink is allowed by the
+// policy to resolve to where it does relative to destination.
+func (p LinkPolicy) containsSymlink(destination string, l link) bool {
+	if p&LinkPolicyStrict == 0 {
+		return true
+	}
+
+	target := linknameFullPath(l.path, l.name)
+	if filepath.IsAbs(l.name) {
+		target = filepath.Clean(l.name)
+	}
+
+	return resolvesWithin(destination, target)
+}
+
+// containsLink reports whether the target of a hard link is allowed by the
+// policy.
+func (p LinkPolicy) containsLink(destination, target string) bool {
+	if p&LinkPolicyStrict == 0 {
+		return true
+	}
+
+	return resolvesWithin(destination, target)
+}
+
+// resolvesWithin reports whether path, once any symlinks along it have been
+// followed, is within destination. Components of path that do not exist are
+// taken as they are.
+func resolvesWithin(destination, path string) bool {
+	root, err := filepath.EvalSymlinks(destination)
+	if err != nil {
+		root = filepath.Clean(destination)
+	}
+
+	resolved := filepath.Clean(path)
+	var rest []string
+	for {
+		evaluated, err := filepath.EvalSymlinks(resolved)
+		if err == nil {
+			resolved = filepath.Join(append([]string{evaluated}, rest...)...)
+			break
+		}
+
+		if resolved == filepath.Dir(resolved) {
+			break
+		}
+
+		rest = append([]string{filepath.Base(resolved)}, rest...)
+		resolved = filepath.Dir(resolved)
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
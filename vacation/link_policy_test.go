@@ -0,0 +1,210 @@
+package vacation_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testLinkPolicy(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		tempDir    string
+		outsideDir string
+	)
+
+	it.Before(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "vacation")
+		Expect(err).NotTo(HaveOccurred())
+
+		outsideDir, err = os.MkdirTemp("", "outside")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(outsideDir, "some-file"), nil, 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+		Expect(os.RemoveAll(outsideDir)).To(Succeed())
+	})
+
+	type entry struct {
+		name     string
+		linkname string
+		typeflag byte
+	}
+
+	newTarball := func(entries ...entry) []byte {
+		buffer := bytes.NewBuffer(nil)
+		tw := tar.NewWriter(buffer)
+
+		for _, e := range entries {
+			header := &tar.Header{Name: e.name, Linkname: e.linkname, Typeflag: e.typeflag, Mode: 0755}
+			if e.typeflag == tar.TypeReg {
+				header.Size = int64(len(e.name))
+			}
+
+			Expect(tw.WriteHeader(header)).To(Succeed())
+
+			if e.typeflag == tar.TypeReg {
+				_, err := tw.Write([]byte(e.name))
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
+
+		Expect(tw.Close()).To(Succeed())
+		return buffer.Bytes()
+	}
+
+	newZipball := func(entries ...entry) []byte {
+		buffer := bytes.NewBuffer(nil)
+		zw := zip.NewWriter(buffer)
+
+		for _, e := range entries {
+			header := &zip.FileHeader{Name: e.name}
+			content := e.name
+
+			switch e.typeflag {
+			case tar.TypeDir:
+				header.SetMode(os.ModeDir | 0755)
+				content = ""
+			case tar.TypeSymlink:
+				header.SetMode(os.ModeSymlink | 0777)
+				content = e.linkname
+			default:
+				header.SetMode(0755)
+			}
+
+			w, err := zw.CreateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = w.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(zw.Close()).To(Succeed())
+		return buffer.Bytes()
+	}
+
+	for _, format := range []string{"tar", "zip"} {
+		format := format
+
+		archive := func(entries ...entry) vacation.Archive {
+			if format == "zip" {
+				return vacation.NewArchive(bytes.NewReader(newZipball(entries...)))
+			}
+
+			return vacation.NewArchive(bytes.NewReader(newTarball(entries...)))
+		}
+
+		context("when a "+format+" archive has a dangling symlink", func() {
+			var entries []entry
+
+			it.Before(func() {
+				entries = []entry{
+					{name: "doc/", typeflag: tar.TypeDir},
+					{name: "doc/README", linkname: "../share/README", typeflag: tar.TypeSymlink},
+				}
+			})
+
+			it("fails by default", func() {
+				err := archive(entries...).Decompress(tempDir)
+				Expect(err).To(MatchError(ContainSubstring("failed to evaluate symlink")))
+			})
+
+			it("creates the symlink when dangling symlinks are allowed", func() {
+				err := archive(entries...).WithLinkPolicy(vacation.LinkPolicyAllowDangling).Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				link, err := os.Readlink(filepath.Join(tempDir, "doc", "README"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal("../share/README"))
+			})
+
+			context("when the dangling symlink points outside of the destination", func() {
+				it.Before(func() {
+					entries[1].linkname = "../../../some-file"
+				})
+
+				it("fails when the policy is also strict", func() {
+					err := archive(entries...).WithLinkPolicy(vacation.LinkPolicyStrict | vacation.LinkPolicyAllowDangling).Decompress(tempDir)
+					Expect(err).To(MatchError(ContainSubstring(`its target "../../../some-file" is outside of the destination directory`)))
+				})
+			})
+		})
+
+		context("when a "+format+" archive has a symlink to an absolute path", func() {
+			var entries []entry
+
+			it.Before(func() {
+				entries = []entry{
+					{name: "usr/", typeflag: tar.TypeDir},
+					{name: "usr/lib/", typeflag: tar.TypeDir},
+					{name: "usr/lib/some-file", typeflag: tar.TypeReg},
+					{name: "usr/bin/", typeflag: tar.TypeDir},
+					{name: "usr/bin/some-link", linkname: filepath.Join(outsideDir, "some-file"), typeflag: tar.TypeSymlink},
+				}
+			})
+
+			it("fails when the policy is strict", func() {
+				err := archive(entries...).WithLinkPolicy(vacation.LinkPolicyStrict).Decompress(tempDir)
+				Expect(err).To(MatchError(ContainSubstring("is outside of the destination directory")))
+
+				Expect(filepath.Join(tempDir, "usr", "bin", "some-link")).NotTo(BeAnExistingFile())
+			})
+
+			it("rewrites the target to be relative to the destination", func() {
+				entries[4].linkname = "/usr/lib/some-file"
+
+				err := archive(entries...).WithLinkPolicy(vacation.LinkPolicyRewriteAbsolute | vacation.LinkPolicyStrict).Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				link, err := os.Readlink(filepath.Join(tempDir, "usr", "bin", "some-link"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal("../lib/some-file"))
+
+				content, err := os.ReadFile(filepath.Join(tempDir, "usr", "bin", "some-link"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("usr/lib/some-file"))
+			})
+		})
+
+		context("when a "+format+" archive has a symlink that resolves inside the destination", func() {
+			it("creates it when the policy is strict", func() {
+				err := archive(
+					entry{name: "some-dir/", typeflag: tar.TypeDir},
+					entry{name: "some-dir/some-file", typeflag: tar.TypeReg},
+					entry{name: "some-link", linkname: "some-dir/some-file", typeflag: tar.TypeSymlink},
+				).WithLinkPolicy(vacation.LinkPolicyStrict).Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				link, err := os.Readlink(filepath.Join(tempDir, "some-link"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal("some-dir/some-file"))
+			})
+		})
+	}
+
+	context("when a tar archive has a hard link outside of the destination", func() {
+		it("fails when the policy is strict", func() {
+			rel, err := filepath.Rel(tempDir, filepath.Join(outsideDir, "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = vacation.NewArchive(bytes.NewReader(newTarball(
+				entry{name: "some-link", linkname: rel, typeflag: tar.TypeLink},
+			))).WithLinkPolicy(vacation.LinkPolicyStrict).Decompress(tempDir)
+			Expect(err).To(MatchError(ContainSubstring("failed to extract link")))
+			Expect(err).To(MatchError(ContainSubstring("is outside of the destination directory")))
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package vacation
+
+import (
+	"io"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// A LZ4Archive decompresses lz4 files from an input stream.
+type LZ4Archive struct {
+	extractOptions
+
+	reader io.Reader
+	name   string
+}
+
+// NewLZ4Archive returns a new LZ4Archive that reads from inputReader.
+func NewLZ4Archive(inputReader io.Reader) LZ4Archive {
+	return LZ4Archive{reader: inputReader}
+}
+
+// Decompress reads from LZ4Archive and writes files into the destination
+// specified.
+func (lz4Archive LZ4Archive) Decompress(destination string) error {
+	limits, reader := lz4Archive.limits.count(lz4Archive.reader)
+	progress, reader := trackProgress(lz4Archive.progress, reader)
+
+	lz4r := lz4.NewReader(reader)
+
+	return Archive{extractOptions: lz4Archive.forward(limits, progress), reader: lz4r, name: lz4Archive.name}.Decompress(destination)
+}
+
+// StripComponents behaves like the --strip-components flag on tar command
+// removing the first n levels from the final decompression destination.
+func (lz4Archive LZ4Archive) StripComponents(components int) LZ4Archive {
+	lz4Archive.components = components
+	return lz4Archive
+}
+
+// WithName provides a way of overriding the name of the file
+// that the decompressed file will be copied into.
+func (lz4Archive LZ4Archive) WithName(name string) LZ4Archive {
+	lz4Archive.name = name
+	return lz4Archive
+}
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (lz4Archive LZ4Archive) WithLimits(limits Limits) LZ4Archive {
+	lz4Archive.limits = limits
+	return lz4Archive
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs.
+func (lz4Archive LZ4Archive) WithIncludes(globs ...string) LZ4Archive {
+	lz4Archive.includes = globs
+	return lz4Archive
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs.
+func (lz4Archive LZ4Archive) WithExcludes(globs ...string) LZ4Archive {
+	lz4Archive.excludes = globs
+	return lz4Archive
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files.
+func (lz4Archive LZ4Archive) WithPreserve(options PreserveOptions) LZ4Archive {
+	lz4Archive.preserve = options
+	return lz4Archive
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (lz4Archive LZ4Archive) WithManifest(manifest *Manifest) LZ4Archive {
+	lz4Archive.manifest = manifest
+	return lz4Archive
+}
+
+// WithLinkPolicy sets the policy that symlinks and hard links must satisfy
+// before they are created.
+func (lz4Archive LZ4Archive) WithLinkPolicy(policy LinkPolicy) LZ4Archive {
+	lz4Archive.linkPolicy = policy
+	return lz4Archive
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (lz4Archive LZ4Archive) WithProgress(reporter ProgressReporter) LZ4Archive {
+	lz4Archive.progress = reporter
+	return lz4Archive
+}
@@ -0,0 +1,205 @@
+package vacation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// A Manifest records the files that were written when an archive was
+// decompressed. It is filled in by passing it to the WithManifest option of an
+// archive and can be kept alongside the extracted files using WriteFile.
+type Manifest struct {
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// A ManifestEntry describes a single extracted file, directory or link.
+type ManifestEntry struct {
+	// Path is the slash separated path of the entry relative to the
+	// decompression destination.
+	Path string `json:"path"`
+
+	// Mode is the mode of the entry, including its type bits.
+	Mode os.FileMode `json:"mode"`
+
+	// Size is the size in bytes of a regular file.
+	Size int64 `json:"size,omitempty"`
+
+	// Linkname is the target of a symlink, or for a hard link, the path of the
+	// file that it links to.
+	Linkname string `json:"linkname,omitempty"`
+
+	// SHA256 is the hex encoded sha256 digest of the content of a regular file,
+	// computed as the file was written.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// ReadManifest reads a manifest that was written using Manifest.WriteFile.
+func ReadManifest(path string) (Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// WriteFile writes the manifest to path as JSON.
+func (m Manifest) WriteFile(path string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path, append(content, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// A manifestRecorder collects the entries written during a single
+// decompression and adds them, sorted by path, to the manifest once
+// decompression has succeeded.
+type manifestRecorder struct {
+	manifest    *Manifest
+	destination string
+	entries     map[string]ManifestEntry
+}
+
+func newManifestRecorder(manifest *Manifest, destination string) *manifestRecorder {
+	return &manifestRecorder{
+		manifest:    manifest,
+		destination: destination,
+		entries:     map[string]ManifestEntry{},
+	}
+}
+
+// A digestReader computes the size and digest of the content of a file as it
+// is read.
+type digestReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	size   int64
+}
+
+func (d *digestReader) Read(p []byte) (int, error) {
+	n, err := d.reader.Read(p)
+	if d.hash != nil {
+		d.hash.Write(p[:n])
+		d.size += int64(n)
+	}
+
+	return n, err
+}
+
+// content wraps the content of a regular file so that its digest is computed
+// as it is written. The digest is only computed when a manifest was requested.
+func (r *manifestRecorder) content(reader io.Reader) *digestReader {
+	content := &digestReader{reader: reader}
+	if r.manifest != nil {
+		content.hash = sha256.New()
+	}
+
+	return content
+}
+
+// file records a regular file whose content was read through content.
+func (r *manifestRecorder) file(path string, mode os.FileMode, content *digestReader) {
+	if r.manifest == nil {
+		return
+	}
+
+	r.record(path, ManifestEntry{
+		Mode:   mode,
+		Size:   content.size,
+		SHA256: hex.EncodeToString(content.hash.Sum(nil)),
+	})
+}
+
+// dir records a directory.
+func (r *manifestRecorder) dir(path string, mode os.FileMode) {
+	if r.manifest == nil {
+		return
+	}
+
+	r.record(path, ManifestEntry{Mode: mode | os.ModeDir})
+}
+
+// symlink records a symlink to linkname.
+func (r *manifestRecorder) symlink(path, linkname string) {
+	if r.manifest == nil {
+		return
+	}
+
+	r.record(path, ManifestEntry{Mode: os.ModeSymlink | 0777, Linkname: linkname})
+}
+
+// link records a hard link to target. The link shares the mode, size and
+// digest of its target when the target was also extracted.
+func (r *manifestRecorder) link(path, target string) {
+	if r.manifest == nil {
+		return
+	}
+
+	entry := ManifestEntry{}
+	if rel, err := filepath.Rel(r.destination, target); err == nil {
+		entry = r.entries[filepath.ToSlash(rel)]
+	}
+
+	if entry.Mode == 0 {
+		info, err := os.Stat(target)
+		if err == nil {
+			entry.Mode = info.Mode()
+			entry.Size = info.Size()
+		}
+	}
+
+	entry.Linkname = r.rel(target)
+	r.record(path, entry)
+}
+
+func (r *manifestRecorder) record(path string, entry ManifestEntry) {
+	entry.Path = r.rel(path)
+	r.entries[entry.Path] = entry
+}
+
+func (r *manifestRecorder) rel(path string) string {
+	rel, err := filepath.Rel(r.destination, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// commit adds the recorded entries to the manifest.
+func (r *manifestRecorder) commit() {
+	if r.manifest == nil {
+		return
+	}
+
+	var entries []ManifestEntry
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	r.manifest.Entries = append(r.manifest.Entries, entries...)
+}
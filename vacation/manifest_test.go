@@ -0,0 +1,189 @@
+package vacation_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testManifest(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		tempDir string
+		tarball []byte
+		zipball []byte
+	)
+
+	digest := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	it.Before(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "vacation")
+		Expect(err).NotTo(HaveOccurred())
+
+		buffer := bytes.NewBuffer(nil)
+		tw := tar.NewWriter(buffer)
+
+		Expect(tw.WriteHeader(&tar.Header{Name: "some-dir/", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+		Expect(tw.WriteHeader(&tar.Header{Name: "some-dir/some-file", Mode: 0644, Size: int64(len("some-content"))})).To(Succeed())
+		_, err = tw.Write([]byte("some-content"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tw.WriteHeader(&tar.Header{Name: "some-dir/some-symlink", Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: "some-file"})).To(Succeed())
+		Expect(tw.WriteHeader(&tar.Header{Name: "some-link", Typeflag: tar.TypeLink, Linkname: "some-dir/some-file"})).To(Succeed())
+		Expect(tw.Close()).To(Succeed())
+		tarball = buffer.Bytes()
+
+		buffer = bytes.NewBuffer(nil)
+		zw := zip.NewWriter(buffer)
+
+		header := &zip.FileHeader{Name: "some-dir/"}
+		header.SetMode(os.ModeDir | 0755)
+		_, err = zw.CreateHeader(header)
+		Expect(err).NotTo(HaveOccurred())
+
+		header = &zip.FileHeader{Name: "some-dir/some-file", Method: zip.Deflate}
+		header.SetMode(0755)
+		w, err := zw.CreateHeader(header)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = w.Write([]byte("some-content"))
+		Expect(err).NotTo(HaveOccurred())
+
+		header = &zip.FileHeader{Name: "some-dir/some-symlink"}
+		header.SetMode(0777 | os.ModeSymlink)
+		w, err = zw.CreateHeader(header)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = w.Write([]byte("some-file"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(zw.Close()).To(Succeed())
+		zipball = buffer.Bytes()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	context("when a tar archive is decompressed", func() {
+		it("records the extracted files, directories and links", func() {
+			var manifest vacation.Manifest
+			err := vacation.NewArchive(bytes.NewReader(tarball)).WithManifest(&manifest).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifest.Entries).To(Equal([]vacation.ManifestEntry{
+				{Path: "some-dir", Mode: os.ModeDir | 0755},
+				{Path: "some-dir/some-file", Mode: 0644, Size: 12, SHA256: digest("some-content")},
+				{Path: "some-dir/some-symlink", Mode: os.ModeSymlink | 0777, Linkname: "some-file"},
+				{Path: "some-link", Mode: 0644, Size: 12, Linkname: "some-dir/some-file", SHA256: digest("some-content")},
+			}))
+		})
+
+		it("records the paths after stripping components", func() {
+			var manifest vacation.Manifest
+			err := vacation.NewTarArchive(bytes.NewReader(tarball)).StripComponents(1).WithManifest(&manifest).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifest.Entries).To(Equal([]vacation.ManifestEntry{
+				{Path: "some-file", Mode: 0644, Size: 12, SHA256: digest("some-content")},
+				{Path: "some-symlink", Mode: os.ModeSymlink | 0777, Linkname: "some-file"},
+			}))
+		})
+	})
+
+	for _, stream := range []bool{false, true} {
+		stream := stream
+
+		name := "when a zip archive is decompressed"
+		if stream {
+			name += " from a stream"
+		}
+
+		context(name, func() {
+			it("records the extracted files, directories and symlinks", func() {
+				archive := vacation.NewZipArchive(bytes.NewReader(zipball))
+				if stream {
+					archive = vacation.NewZipArchive(bytes.NewBuffer(zipball))
+				}
+
+				var manifest vacation.Manifest
+				err := archive.WithManifest(&manifest).Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(manifest.Entries).To(Equal([]vacation.ManifestEntry{
+					{Path: "some-dir", Mode: os.ModeDir | 0755},
+					{Path: "some-dir/some-file", Mode: 0755, Size: 12, SHA256: digest("some-content")},
+					{Path: "some-dir/some-symlink", Mode: os.ModeSymlink | 0777, Linkname: "some-file"},
+				}))
+			})
+		})
+	}
+
+	context("when a single file is decompressed", func() {
+		it("records the file", func() {
+			var manifest vacation.Manifest
+			err := vacation.NewExecutable(bytes.NewReader([]byte("some-content"))).WithName("some-executable").WithManifest(&manifest).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifest.Entries).To(Equal([]vacation.ManifestEntry{
+				{Path: "some-executable", Mode: 0755, Size: 12, SHA256: digest("some-content")},
+			}))
+		})
+	})
+
+	context("when decompression fails", func() {
+		it("does not record any entries", func() {
+			var manifest vacation.Manifest
+			err := vacation.NewArchive(bytes.NewReader(tarball)).WithLimits(vacation.Limits{MaxEntries: 2}).WithManifest(&manifest).Decompress(tempDir)
+			Expect(err).To(HaveOccurred())
+
+			Expect(manifest.Entries).To(BeEmpty())
+		})
+	})
+
+	context("WriteFile", func() {
+		it("writes a manifest that can be read back", func() {
+			var manifest vacation.Manifest
+			err := vacation.NewArchive(bytes.NewReader(tarball)).WithManifest(&manifest).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			path := filepath.Join(tempDir, "manifest.json")
+			Expect(manifest.WriteFile(path)).To(Succeed())
+
+			read, err := vacation.ReadManifest(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(read).To(Equal(manifest))
+		})
+
+		context("failure cases", func() {
+			context("when the manifest cannot be read", func() {
+				it("returns an error", func() {
+					_, err := vacation.ReadManifest(filepath.Join(tempDir, "missing.json"))
+					Expect(err).To(MatchError(ContainSubstring("failed to read manifest")))
+				})
+			})
+
+			context("when the manifest is malformed", func() {
+				it("returns an error", func() {
+					path := filepath.Join(tempDir, "manifest.json")
+					Expect(os.WriteFile(path, []byte("%%%"), 0644)).To(Succeed())
+
+					_, err := vacation.ReadManifest(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse manifest")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,178 @@
+package vacation
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const paxXattrPrefix = "SCHILY.xattr."
+
+// PreserveOptions selects the metadata of archive entries that is restored on
+// the extracted files. By default only the file mode is kept.
+type PreserveOptions struct {
+	// ModTimes restores the modification times of files.
+	ModTimes bool
+
+	// ClampModTimes restores the modification times of files, but sets any that
+	// are later than the time given in the SOURCE_DATE_EPOCH environment
+	// variable to that time so that the extracted files are reproducible. When
+	// SOURCE_DATE_EPOCH is unset, it behaves like ModTimes.
+	ClampModTimes bool
+
+	// Ownership restores the uid and gid of files. It is ignored unless the
+	// process is running as root.
+	Ownership bool
+
+	// Xattrs restores the extended attributes of files recorded in PAX headers.
+	// Attributes outside of the "user." namespace, such as the
+	// "security.capability" attribute holding file capabilities, are only
+	// restored when the process is running as root. Requesting them on a
+	// platform other than Linux or macOS is an error.
+	Xattrs bool
+}
+
+type entryMetadata struct {
+	path    string
+	mode    os.FileMode
+	symlink bool
+	modTime time.Time
+	uid     int
+	gid     int
+	xattrs  map[string]string
+}
+
+// A metadataRestorer records the metadata of extracted entries and restores
+// it once extraction is complete, so that creating later entries does not
+// change the modification times of directories.
+type metadataRestorer struct {
+	options PreserveOptions
+	root    bool
+	clamp   time.Time
+	entries []entryMetadata
+}
+
+func newMetadataRestorer(options PreserveOptions) (*metadataRestorer, error) {
+	if options.Xattrs && !xattrsSupported {
+		return nil, fmt.Errorf("failed to preserve xattrs: not supported on %s", runtime.GOOS)
+	}
+
+	restorer := &metadataRestorer{
+		options: options,
+		root:    os.Geteuid() == 0,
+	}
+
+	if options.ClampModTimes {
+		if value, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok && value != "" {
+			clamp, err := sourceDateEpoch()
+			if err != nil {
+				return nil, err
+			}
+
+			restorer.clamp = clamp
+		}
+	}
+
+	return restorer, nil
+}
+
+func (r *metadataRestorer) enabled() bool {
+	return r.options.ModTimes || r.options.ClampModTimes || r.options.Ownership || r.options.Xattrs
+}
+
+// recordTar records the metadata of the tar entry extracted to path.
+func (r *metadataRestorer) recordTar(path string, hdr *tar.Header) {
+	if !r.enabled() {
+		return
+	}
+
+	metadata := entryMetadata{
+		path:    path,
+		mode:    hdr.FileInfo().Mode(),
+		symlink: hdr.Typeflag == tar.TypeSymlink,
+		modTime: hdr.ModTime,
+		uid:     hdr.Uid,
+		gid:     hdr.Gid,
+	}
+
+	for key, value := range hdr.PAXRecords {
+		if strings.HasPrefix(key, paxXattrPrefix) {
+			if metadata.xattrs == nil {
+				metadata.xattrs = map[string]string{}
+			}
+
+			metadata.xattrs[strings.TrimPrefix(key, paxXattrPrefix)] = value
+		}
+	}
+
+	r.entries = append(r.entries, metadata)
+}
+
+// recordModTime records only the modification time of the entry extracted to
+// path, for archive formats that do not store any other metadata.
+func (r *metadataRestorer) recordModTime(path string, mode os.FileMode, modTime time.Time) {
+	if !r.enabled() || modTime.IsZero() {
+		return
+	}
+
+	r.entries = append(r.entries, entryMetadata{
+		path:    path,
+		mode:    mode,
+		symlink: mode&os.ModeSymlink != 0,
+		modTime: modTime,
+		uid:     -1,
+		gid:     -1,
+	})
+}
+
+// restore applies the recorded metadata. Ownership is restored first as
+// changing it clears the setuid and setgid bits and file capabilities.
+func (r *metadataRestorer) restore() error {
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		entry := r.entries[i]
+
+		if r.options.Ownership && r.root && entry.uid >= 0 && entry.gid >= 0 {
+			err := os.Lchown(entry.path, entry.uid, entry.gid)
+			if err != nil {
+				return fmt.Errorf("failed to restore ownership: %w", err)
+			}
+
+			if !entry.symlink && entry.mode&(os.ModeSetuid|os.ModeSetgid) != 0 {
+				err = os.Chmod(entry.path, entry.mode)
+				if err != nil {
+					return fmt.Errorf("failed to restore mode: %w", err)
+				}
+			}
+		}
+
+		if r.options.Xattrs {
+			for name, value := range entry.xattrs {
+				if !r.root && !strings.HasPrefix(name, "user.") {
+					continue
+				}
+
+				err := setXattr(entry.path, name, []byte(value))
+				if err != nil {
+					return fmt.Errorf("failed to restore xattr %q on %s: %w", name, entry.path, err)
+				}
+			}
+		}
+
+		if r.options.ModTimes || r.options.ClampModTimes {
+			modTime := entry.modTime
+			if !r.clamp.IsZero() && modTime.After(r.clamp) {
+				modTime = r.clamp
+			}
+
+			err := setModTime(entry.path, modTime)
+			if err != nil {
+				return fmt.Errorf("failed to restore modification time of %s: %w", entry.path, err)
+			}
+		}
+	}
+
+	return nil
+}
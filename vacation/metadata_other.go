@@ -0,0 +1,36 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package vacation
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+)
+
+// xattrsSupported reports whether extended attributes can be restored on this
+// platform.
+const xattrsSupported = false
+
+// setXattr always fails, as extended attributes are not supported on this
+// platform.
+func setXattr(path, name string, value []byte) error {
+	return fmt.Errorf("xattrs are not supported on %s", runtime.GOOS)
+}
+
+// setModTime sets the access and modification times of path to modTime. The
+// modification times of symlinks are not restored on this platform.
+func setModTime(path string, modTime time.Time) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+
+	return os.Chtimes(path, modTime, modTime)
+}
@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package vacation_test
+
+import (
+	"errors"
+	"os"
+)
+
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+func userXattrsSupported(dir string) (bool, error) {
+	return false, nil
+}
+
+func getXattr(path, name string) (string, error) {
+	return "", errors.New("xattrs are not supported on this platform")
+}
@@ -0,0 +1,182 @@
+package vacation_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testMetadata(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		tempDir string
+		tarball []byte
+		zipball []byte
+
+		dirTime     = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		fileTime    = time.Date(2021, time.February, 2, 0, 0, 0, 0, time.UTC)
+		symlinkTime = time.Date(2022, time.March, 3, 0, 0, 0, 0, time.UTC)
+	)
+
+	it.Before(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "vacation")
+		Expect(err).NotTo(HaveOccurred())
+
+		buffer := bytes.NewBuffer(nil)
+		tw := tar.NewWriter(buffer)
+
+		Expect(tw.WriteHeader(&tar.Header{Name: "some-dir/", Mode: 0755, Typeflag: tar.TypeDir, ModTime: dirTime, Uid: 1234, Gid: 5678})).To(Succeed())
+
+		Expect(tw.WriteHeader(&tar.Header{
+			Name:    "some-dir/some-file",
+			Mode:    0755,
+			Size:    int64(len("some-file")),
+			ModTime: fileTime,
+			Uid:     1234,
+			Gid:     5678,
+			PAXRecords: map[string]string{
+				"SCHILY.xattr.user.some-attribute": "some-value",
+			},
+		})).To(Succeed())
+		_, err = tw.Write([]byte("some-file"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tw.WriteHeader(&tar.Header{Name: "some-dir/some-symlink", Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: "some-file", ModTime: symlinkTime})).To(Succeed())
+
+		Expect(tw.Close()).To(Succeed())
+		tarball = buffer.Bytes()
+
+		buffer = bytes.NewBuffer(nil)
+		zw := zip.NewWriter(buffer)
+
+		_, err = zw.CreateHeader(&zip.FileHeader{Name: "some-dir/", Modified: dirTime})
+		Expect(err).NotTo(HaveOccurred())
+
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: "some-dir/some-file", Modified: fileTime})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = w.Write([]byte("some-file"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(zw.Close()).To(Succeed())
+		zipball = buffer.Bytes()
+
+		Expect(os.Unsetenv("SOURCE_DATE_EPOCH")).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("SOURCE_DATE_EPOCH")).To(Succeed())
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	modTime := func(path string) time.Time {
+		info, err := os.Lstat(path)
+		Expect(err).NotTo(HaveOccurred())
+		return info.ModTime()
+	}
+
+	context("when no metadata is preserved", func() {
+		it("only keeps the file mode", func() {
+			err := vacation.NewArchive(bytes.NewReader(tarball)).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(modTime(filepath.Join(tempDir, "some-dir", "some-file"))).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+	})
+
+	context("when modification times are preserved", func() {
+		it("restores the modification times of files, directories and symlinks", func() {
+			err := vacation.NewArchive(bytes.NewReader(tarball)).WithPreserve(vacation.PreserveOptions{ModTimes: true}).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(modTime(filepath.Join(tempDir, "some-dir"))).To(BeTemporally("==", dirTime))
+			Expect(modTime(filepath.Join(tempDir, "some-dir", "some-file"))).To(BeTemporally("==", fileTime))
+			Expect(modTime(filepath.Join(tempDir, "some-dir", "some-symlink"))).To(BeTemporally("==", symlinkTime))
+		})
+
+		it("restores the modification times of zip entries", func() {
+			err := vacation.NewArchive(bytes.NewReader(zipball)).WithPreserve(vacation.PreserveOptions{ModTimes: true}).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(modTime(filepath.Join(tempDir, "some-dir"))).To(BeTemporally("==", dirTime))
+			Expect(modTime(filepath.Join(tempDir, "some-dir", "some-file"))).To(BeTemporally("==", fileTime))
+		})
+	})
+
+	context("when modification times are clamped", func() {
+		it.Before(func() {
+			Expect(os.Setenv("SOURCE_DATE_EPOCH", "1600000000")).To(Succeed())
+		})
+
+		it("clamps modification times later than SOURCE_DATE_EPOCH", func() {
+			err := vacation.NewArchive(bytes.NewReader(tarball)).WithPreserve(vacation.PreserveOptions{ClampModTimes: true}).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(modTime(filepath.Join(tempDir, "some-dir"))).To(BeTemporally("==", dirTime))
+			Expect(modTime(filepath.Join(tempDir, "some-dir", "some-file"))).To(BeTemporally("==", time.Unix(1600000000, 0)))
+			Expect(modTime(filepath.Join(tempDir, "some-dir", "some-symlink"))).To(BeTemporally("==", time.Unix(1600000000, 0)))
+		})
+
+		context("when SOURCE_DATE_EPOCH is not an integer", func() {
+			it.Before(func() {
+				Expect(os.Setenv("SOURCE_DATE_EPOCH", "some-time")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				err := vacation.NewArchive(bytes.NewReader(tarball)).WithPreserve(vacation.PreserveOptions{ClampModTimes: true}).Decompress(tempDir)
+				Expect(err).To(MatchError(ContainSubstring("failed to parse SOURCE_DATE_EPOCH")))
+			})
+		})
+	})
+
+	context("when ownership is preserved", func() {
+		it("restores the owner when running as root and is ignored otherwise", func() {
+			err := vacation.NewArchive(bytes.NewReader(tarball)).WithPreserve(vacation.PreserveOptions{Ownership: true}).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			info, err := os.Stat(filepath.Join(tempDir, "some-dir", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+
+			uid, gid, ok := fileOwner(info)
+			if !ok {
+				t.Skip("file ownership is not available on this platform")
+			}
+
+			if os.Geteuid() == 0 {
+				Expect(uid).To(Equal(1234))
+				Expect(gid).To(Equal(5678))
+			} else {
+				Expect(uid).To(Equal(os.Geteuid()))
+			}
+		})
+	})
+
+	context("when xattrs are preserved", func() {
+		it.Before(func() {
+			supported, err := userXattrsSupported(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+			if !supported {
+				t.Skip("the temporary directory does not support user xattrs")
+			}
+		})
+
+		it("restores the xattrs recorded in PAX headers", func() {
+			err := vacation.NewArchive(bytes.NewReader(tarball)).WithPreserve(vacation.PreserveOptions{Xattrs: true}).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			value, err := getXattr(filepath.Join(tempDir, "some-dir", "some-file"), "user.some-attribute")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal("some-value"))
+		})
+	})
+}
@@ -0,0 +1,27 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package vacation
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// xattrsSupported reports whether extended attributes can be restored on this
+// platform.
+const xattrsSupported = true
+
+// setXattr sets the extended attribute name on path without following
+// symlinks.
+func setXattr(path, name string, value []byte) error {
+	return unix.Lsetxattr(path, name, value, 0)
+}
+
+// setModTime sets the access and modification times of path to modTime
+// without following symlinks.
+func setModTime(path string, modTime time.Time) error {
+	times := []unix.Timespec{unix.NsecToTimespec(modTime.UnixNano()), unix.NsecToTimespec(modTime.UnixNano())}
+	return unix.UtimesNanoAt(unix.AT_FDCWD, path, times, unix.AT_SYMLINK_NOFOLLOW)
+}
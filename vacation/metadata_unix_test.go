@@ -0,0 +1,51 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package vacation_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return int(stat.Uid), int(stat.Gid), true
+}
+
+func userXattrsSupported(dir string) (bool, error) {
+	probe := filepath.Join(dir, "probe")
+	err := os.WriteFile(probe, nil, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(probe)
+
+	err = unix.Setxattr(probe, "user.probe", []byte("probe"), 0)
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EPERM) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func getXattr(path, name string) (string, error) {
+	value := make([]byte, 64)
+	n, err := unix.Getxattr(path, name, value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value[:n]), nil
+}
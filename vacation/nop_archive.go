@@ -11,8 +11,11 @@ import (
 // the option `NopArchive.WithName()` (or defaults to `artifact`) in the
 // destination directory.
 type NopArchive struct {
-	reader io.Reader
-	name   string
+	reader   io.Reader
+	name     string
+	limits   Limits
+	manifest *Manifest
+	progress ProgressReporter
 }
 
 // NewNopArchive returns a new NopArchive
@@ -25,17 +28,34 @@ func NewNopArchive(r io.Reader) NopArchive {
 
 // Decompress copies the reader contents into the destination specified.
 func (na NopArchive) Decompress(destination string) error {
-	file, err := os.Create(filepath.Join(destination, na.name))
+	limits, reader := na.limits.count(na.reader)
+	progress, reader := trackProgress(na.progress, reader)
+	limiter := newLimiter(limits)
+
+	path := filepath.Join(destination, na.name)
+	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
+	limiter.track(path)
+	progress.entry(na.name)
+
+	recorder := newManifestRecorder(na.manifest, destination)
+	content := recorder.content(reader)
+	err = limiter.copy(file, content, na.name)
+	if err != nil {
+		return limiter.cleanup(err)
+	}
 
-	_, err = io.Copy(file, na.reader)
+	info, err := file.Stat()
 	if err != nil {
 		return err
 	}
 
+	recorder.file(path, info.Mode(), content)
+	recorder.commit()
+
 	return nil
 }
 
@@ -47,3 +67,21 @@ func (na NopArchive) WithName(name string) NopArchive {
 	}
 	return na
 }
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (na NopArchive) WithLimits(limits Limits) NopArchive {
+	na.limits = limits
+	return na
+}
+
+// WithManifest records the file that is written in manifest.
+func (na NopArchive) WithManifest(manifest *Manifest) NopArchive {
+	na.manifest = manifest
+	return na
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (na NopArchive) WithProgress(reporter ProgressReporter) NopArchive {
+	na.progress = reporter
+	return na
+}
@@ -0,0 +1,71 @@
+package vacation
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// A pathFilter selects the archive entries that are extracted using the
+// include and exclude globs given to an archive. The globs use the syntax of
+// path.Match and are matched against the slash separated path of an entry
+// after its leading components are stripped. A glob that matches a directory
+// also matches everything below it.
+type pathFilter struct {
+	includes []string
+	excludes []string
+}
+
+func newPathFilter(includes, excludes []string) (pathFilter, error) {
+	for _, pattern := range includes {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return pathFilter{}, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+	}
+
+	for _, pattern := range excludes {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return pathFilter{}, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	return pathFilter{includes: includes, excludes: excludes}, nil
+}
+
+// match reports whether the entry with the given name should be extracted.
+// An entry is extracted when it matches one of the includes, or there are no
+// includes, and does not match any of the excludes.
+func (f pathFilter) match(name string) bool {
+	if len(f.includes) > 0 && !matchAny(f.includes, name) {
+		return false
+	}
+
+	return !matchAny(f.excludes, name)
+}
+
+// excluded reports whether path, a path within destination, was skipped by
+// the filter.
+func (f pathFilter) excluded(destination, path string) bool {
+	rel, err := filepath.Rel(destination, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	return !f.match(filepath.ToSlash(rel))
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		for p := name; p != "." && p != "/"; p = path.Dir(p) {
+			if matched, _ := path.Match(pattern, p); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,171 @@
+package vacation_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testPathFilter(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		tempDir string
+		tarball []byte
+		zipball []byte
+	)
+
+	it.Before(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "vacation")
+		Expect(err).NotTo(HaveOccurred())
+
+		files := []string{
+			"jdk/bin/java",
+			"jdk/bin/javac",
+			"jdk/lib/modules",
+			"jdk/lib/security/cacerts",
+			"jdk/release",
+		}
+
+		buffer := bytes.NewBuffer(nil)
+		gw := gzip.NewWriter(buffer)
+		tw := tar.NewWriter(gw)
+
+		zipBuffer := bytes.NewBuffer(nil)
+		zw := zip.NewWriter(zipBuffer)
+
+		for _, dir := range []string{"jdk", "jdk/bin", "jdk/lib", "jdk/lib/security"} {
+			Expect(tw.WriteHeader(&tar.Header{Name: dir + "/", Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+
+			_, err = zw.Create(dir + "/")
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		for _, file := range files {
+			Expect(tw.WriteHeader(&tar.Header{Name: file, Mode: 0755, Size: int64(len(file))})).To(Succeed())
+			_, err = tw.Write([]byte(file))
+			Expect(err).NotTo(HaveOccurred())
+
+			w, err := zw.Create(file)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write([]byte(file))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(tw.WriteHeader(&tar.Header{Name: "jdk/bin/keytool", Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: "../lib/security/cacerts"})).To(Succeed())
+
+		header := &zip.FileHeader{Name: "jdk/bin/keytool"}
+		header.SetMode(0777 | os.ModeSymlink)
+		w, err := zw.CreateHeader(header)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = w.Write([]byte("../lib/security/cacerts"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tw.Close()).To(Succeed())
+		Expect(gw.Close()).To(Succeed())
+		Expect(zw.Close()).To(Succeed())
+
+		tarball = buffer.Bytes()
+		zipball = zipBuffer.Bytes()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	for _, format := range []string{"tar", "zip"} {
+		format := format
+
+		context("when a "+format+" archive is filtered", func() {
+			var archive func() vacation.Archive
+
+			it.Before(func() {
+				archive = func() vacation.Archive {
+					if format == "zip" {
+						return vacation.NewArchive(bytes.NewReader(zipball))
+					}
+
+					return vacation.NewArchive(bytes.NewReader(tarball))
+				}
+			})
+
+			it("only extracts the included directories after stripping components", func() {
+				err := archive().StripComponents(1).WithIncludes("bin", "lib/security").Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "bin"),
+					filepath.Join(tempDir, "lib"),
+				}))
+
+				Expect(filepath.Join(tempDir, "bin", "java")).To(BeARegularFile())
+				Expect(filepath.Join(tempDir, "bin", "javac")).To(BeARegularFile())
+				Expect(filepath.Join(tempDir, "bin", "keytool")).To(BeARegularFile())
+				Expect(filepath.Join(tempDir, "lib", "security", "cacerts")).To(BeARegularFile())
+				Expect(filepath.Join(tempDir, "lib", "modules")).NotTo(BeAnExistingFile())
+			})
+
+			it("only extracts the files matching the included globs", func() {
+				err := archive().WithIncludes("*/bin/java*").Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "jdk", "*", "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "jdk", "bin", "java"),
+					filepath.Join(tempDir, "jdk", "bin", "javac"),
+				}))
+			})
+
+			it("skips the excluded entries", func() {
+				err := archive().StripComponents(1).WithExcludes("lib/modules", "release").Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(tempDir, "bin", "java")).To(BeARegularFile())
+				Expect(filepath.Join(tempDir, "lib", "security", "cacerts")).To(BeARegularFile())
+				Expect(filepath.Join(tempDir, "lib", "modules")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(tempDir, "release")).NotTo(BeAnExistingFile())
+			})
+
+			it("gives excludes precedence over includes", func() {
+				err := archive().StripComponents(1).WithIncludes("bin").WithExcludes("bin/javac", "bin/keytool").Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := filepath.Glob(filepath.Join(tempDir, "*", "*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(ConsistOf([]string{
+					filepath.Join(tempDir, "bin", "java"),
+				}))
+			})
+
+			context("when the target of an included symlink is excluded", func() {
+				it("returns an error naming the excluded target", func() {
+					err := archive().StripComponents(1).WithIncludes("bin").Decompress(tempDir)
+					Expect(err).To(MatchError(ContainSubstring(`its target "../lib/security/cacerts" was excluded from extraction`)))
+				})
+			})
+
+			context("when a glob is malformed", func() {
+				it("returns an error", func() {
+					err := archive().WithIncludes("[").Decompress(tempDir)
+					Expect(err).To(MatchError(`invalid include pattern "[": syntax error in pattern`))
+
+					err = archive().WithExcludes("[").Decompress(tempDir)
+					Expect(err).To(MatchError(`invalid exclude pattern "[": syntax error in pattern`))
+				})
+			})
+		})
+	}
+}
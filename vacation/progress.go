@@ -0,0 +1,104 @@
+package vacation
+
+import (
+	"io"
+	"sync"
+)
+
+// A ProgressReporter receives updates on the progress of decompressing an
+// archive. Progress is called with the name of the entry being extracted, the
+// number of bytes of the input that have been read, and the total size of the
+// input, or -1 when it is not known. It is called often, so implementations
+// that print progress should throttle their output.
+type ProgressReporter interface {
+	Progress(entry string, read, total int64)
+}
+
+// A progressTracker counts the bytes read from the input stream of the
+// outermost archive and reports them along with the current entry. Archives
+// that are nested within another share its tracker.
+type progressTracker struct {
+	reporter ProgressReporter
+	reader   io.Reader
+	total    int64
+
+	mutex   sync.Mutex
+	read    int64
+	current string
+}
+
+// trackProgress wraps the input stream of an archive so that its progress is
+// reported to reporter as it is read. When reporter is the tracker of an
+// enclosing archive, it is returned as it is.
+func trackProgress(reporter ProgressReporter, reader io.Reader) (*progressTracker, io.Reader) {
+	if reporter == nil {
+		return nil, reader
+	}
+
+	if tracker, ok := reporter.(*progressTracker); ok {
+		return tracker, reader
+	}
+
+	tracker := &progressTracker{
+		reporter: reporter,
+		reader:   reader,
+		total:    -1,
+	}
+
+	if _, size, ok := readerAt(reader); ok {
+		tracker.total = size
+	}
+
+	return tracker, tracker
+}
+
+func (t *progressTracker) Read(p []byte) (int, error) {
+	n, err := t.reader.Read(p)
+	if n > 0 {
+		t.mutex.Lock()
+		t.read += int64(n)
+		t.report()
+		t.mutex.Unlock()
+	}
+
+	return n, err
+}
+
+// Progress forwards progress to the underlying reporter so that a tracker can
+// be handed to nested archives as their reporter.
+func (t *progressTracker) Progress(entry string, read, total int64) {
+	t.reporter.Progress(entry, read, total)
+}
+
+// entry records that extraction of the named entry has started.
+func (t *progressTracker) entry(name string) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.current = name
+	t.report()
+}
+
+// seek records that the input has been read up to offset, for archives that
+// are read from an io.ReaderAt rather than as a stream.
+func (t *progressTracker) seek(offset int64) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if offset > t.read {
+		t.read = offset
+		t.report()
+	}
+}
+
+func (t *progressTracker) report() {
+	t.reporter.Progress(t.current, t.read, t.total)
+}
@@ -0,0 +1,152 @@
+package vacation_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+type progressUpdate struct {
+	entry string
+	read  int64
+	total int64
+}
+
+type recordingReporter struct {
+	mutex   sync.Mutex
+	updates []progressUpdate
+}
+
+func (r *recordingReporter) Progress(entry string, read, total int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.updates = append(r.updates, progressUpdate{entry: entry, read: read, total: total})
+}
+
+func (r *recordingReporter) entries() []string {
+	var entries []string
+	for _, update := range r.updates {
+		if len(entries) == 0 || entries[len(entries)-1] != update.entry {
+			entries = append(entries, update.entry)
+		}
+	}
+
+	return entries
+}
+
+func (r *recordingReporter) last() progressUpdate {
+	return r.updates[len(r.updates)-1]
+}
+
+func testProgress(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		tempDir  string
+		reporter *recordingReporter
+	)
+
+	it.Before(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "vacation")
+		Expect(err).NotTo(HaveOccurred())
+
+		reporter = &recordingReporter{}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	context("when a compressed tar archive is decompressed", func() {
+		var tarball []byte
+
+		it.Before(func() {
+			buffer := bytes.NewBuffer(nil)
+			gw := gzip.NewWriter(buffer)
+			tw := tar.NewWriter(gw)
+
+			for _, name := range []string{"first", "second", "third"} {
+				Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(name))})).To(Succeed())
+				_, err := tw.Write([]byte(name))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(tw.Close()).To(Succeed())
+			Expect(gw.Close()).To(Succeed())
+			tarball = buffer.Bytes()
+		})
+
+		it("reports the entries and the bytes of the input that were read", func() {
+			err := vacation.NewArchive(bytes.NewReader(tarball)).WithProgress(reporter).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reporter.entries()).To(ContainElements("first", "second", "third"))
+			Expect(reporter.last()).To(Equal(progressUpdate{entry: "third", read: int64(len(tarball)), total: int64(len(tarball))}))
+		})
+
+		context("when the size of the input is not known", func() {
+			it("reports the total as -1", func() {
+				err := vacation.NewArchive(bytes.NewBuffer(tarball)).WithProgress(reporter).Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(reporter.last().read).To(BeEquivalentTo(len(tarball)))
+				Expect(reporter.last().total).To(BeEquivalentTo(-1))
+			})
+		})
+	})
+
+	context("when a zip archive is decompressed", func() {
+		var zipball []byte
+
+		it.Before(func() {
+			buffer := bytes.NewBuffer(nil)
+			zw := zip.NewWriter(buffer)
+
+			for _, name := range []string{"first", "second", "third"} {
+				w, err := zw.Create(name)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = w.Write([]byte(name))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(zw.Close()).To(Succeed())
+			zipball = buffer.Bytes()
+		})
+
+		it("reports the offset of each entry within the input", func() {
+			err := vacation.NewArchive(bytes.NewReader(zipball)).WithProgress(reporter).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reporter.entries()).To(ContainElements("first", "second", "third"))
+			Expect(reporter.last()).To(Equal(progressUpdate{entry: "third", read: int64(len(zipball)), total: int64(len(zipball))}))
+		})
+
+		it("reports the entries as they are streamed", func() {
+			err := vacation.NewZipArchive(bytes.NewBuffer(zipball)).WithProgress(reporter).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reporter.entries()).To(ContainElements("first", "second", "third"))
+			Expect(reporter.last().total).To(BeEquivalentTo(-1))
+		})
+	})
+
+	context("when a single file is decompressed", func() {
+		it("reports the name of the file", func() {
+			err := vacation.NewNopArchive(bytes.NewReader([]byte("some-content"))).WithName("some-file").WithProgress(reporter).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reporter.last()).To(Equal(progressUpdate{entry: "some-file", read: 12, total: 12}))
+		})
+	})
+}
@@ -0,0 +1,442 @@
+package vacation
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+const (
+	rpmLeadLength     = 96
+	rpmHeaderLength   = 16
+	rpmIndexLength    = 16
+	cpioHeaderLength  = 110
+	cpioTrailerName   = "TRAILER!!!"
+	cpioModeTypeMask  = 0170000
+	cpioModeDirectory = 0040000
+	cpioModeRegular   = 0100000
+	cpioModeSymlink   = 0120000
+
+	// cpioMaxPathLength bounds the names and symlink targets read from cpio
+	// headers, whose sizes are otherwise taken from the untrusted archive. It
+	// matches PATH_MAX on Linux.
+	cpioMaxPathLength = 4096
+)
+
+var (
+	rpmLeadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	rpmHeaderMagic = []byte{0x8e, 0xad, 0xe8, 0x01}
+)
+
+// A RPMArchive decompresses RPM packages from an input stream. Only the
+// files in the cpio payload of the package are extracted.
+type RPMArchive struct {
+	extractOptions
+
+	reader io.Reader
+}
+
+// NewRPMArchive returns a new RPMArchive that reads from inputReader.
+func NewRPMArchive(inputReader io.Reader) RPMArchive {
+	return RPMArchive{reader: inputReader}
+}
+
+// Decompress reads from RPMArchive and writes the files in its payload into
+// the destination specified.
+func (ra RPMArchive) Decompress(destination string) error {
+	limits, input := ra.limits.count(ra.reader)
+	progress, input := trackProgress(ra.progress, input)
+	reader := bufio.NewReader(input)
+
+	lead := make([]byte, rpmLeadLength)
+	_, err := io.ReadFull(reader, lead)
+	if err != nil || !bytes.HasPrefix(lead, rpmLeadMagic) {
+		return errors.New("failed to read rpm archive: invalid lead")
+	}
+
+	// The signature header is padded to a multiple of 8 bytes, the main header
+	// that follows it is not.
+	size, err := skipRPMHeader(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read rpm signature header: %w", err)
+	}
+
+	_, err = io.CopyN(io.Discard, reader, (8-size%8)%8)
+	if err != nil {
+		return fmt.Errorf("failed to read rpm signature header: %w", err)
+	}
+
+	_, err = skipRPMHeader(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read rpm header: %w", err)
+	}
+
+	payload, err := rpmPayloadReader(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read rpm payload: %w", err)
+	}
+	defer payload.Close()
+
+	// The cpio payload is converted into a tar stream so that the files are
+	// extracted with the same path, component stripping and link handling as
+	// tar archives.
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(cpioToTar(payload, pw))
+	}()
+
+	err = TarArchive{extractOptions: ra.forward(limits, progress), reader: pr}.Decompress(destination)
+
+	// Unblocks the conversion when extraction stops before the end of the
+	// payload.
+	pr.CloseWithError(io.ErrClosedPipe)
+	<-done
+
+	return err
+}
+
+// StripComponents behaves like the --strip-components flag on tar command
+// removing the first n levels from the final decompression destination.
+func (ra RPMArchive) StripComponents(components int) RPMArchive {
+	ra.components = components
+	return ra
+}
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (ra RPMArchive) WithLimits(limits Limits) RPMArchive {
+	ra.limits = limits
+	return ra
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs.
+func (ra RPMArchive) WithIncludes(globs ...string) RPMArchive {
+	ra.includes = globs
+	return ra
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs.
+func (ra RPMArchive) WithExcludes(globs ...string) RPMArchive {
+	ra.excludes = globs
+	return ra
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files.
+func (ra RPMArchive) WithPreserve(options PreserveOptions) RPMArchive {
+	ra.preserve = options
+	return ra
+}
+
+// skipRPMHeader reads past a header structure and returns its length.
+func skipRPMHeader(reader io.Reader) (int64, error) {
+	header := make([]byte, rpmHeaderLength)
+	_, err := io.ReadFull(reader, header)
+	if err != nil {
+		return 0, err
+	}
+
+	if !bytes.Equal(header[:4], rpmHeaderMagic) {
+		return 0, errors.New("invalid header magic")
+	}
+
+	entries := int64(binary.BigEndian.Uint32(header[8:12]))
+	store := int64(binary.BigEndian.Uint32(header[12:16]))
+
+	size := entries*rpmIndexLength + store
+	_, err = io.CopyN(io.Discard, reader, size)
+	if err != nil {
+		return 0, err
+	}
+
+	return rpmHeaderLength + size, nil
+}
+
+// rpmPayloadReader determines the compression of the payload from its magic
+// number and returns a reader of the uncompressed cpio stream. The reader must
+// be closed to release the resources held by its decompressor.
+func rpmPayloadReader(reader *bufio.Reader) (io.ReadCloser, error) {
+	magic, err := reader.Peek(6)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("070701")), bytes.HasPrefix(magic, []byte("070702")):
+		return io.NopCloser(reader), nil
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(reader)
+	case bytes.HasPrefix(magic, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		xzr, err := xz.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return io.NopCloser(xzr), nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return zr.IOReadCloser(), nil
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return io.NopCloser(bzip2.NewReader(reader)), nil
+	case bytes.HasPrefix(magic, []byte{0x5d, 0x00, 0x00}):
+		lr, err := lzma.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return io.NopCloser(lr), nil
+	default:
+		return nil, errors.New("unsupported payload compression")
+	}
+}
+
+type cpioHeader struct {
+	name     string
+	mode     int64
+	size     int64
+	nlink    int64
+	uid      int
+	gid      int
+	modTime  time.Time
+	inode    string
+	linkname string
+}
+
+// cpioToTar converts a "newc" cpio stream into a tar stream. Hard links are
+// stored in cpio as a series of empty entries followed by a single entry
+// holding the content, so the empty entries are held back and written as tar
+// hard links to the entry with the content.
+func cpioToTar(reader io.Reader, writer io.Writer) error {
+	tw := tar.NewWriter(writer)
+	pending := map[string][]cpioHeader{}
+	var inodes []string
+
+	for {
+		hdr, err := readCPIOHeader(reader)
+		if err != nil {
+			return err
+		}
+
+		if hdr.name == cpioTrailerName {
+			break
+		}
+
+		if hdr.mode&cpioModeTypeMask == cpioModeRegular && hdr.nlink > 1 {
+			if _, ok := pending[hdr.inode]; !ok {
+				inodes = append(inodes, hdr.inode)
+			}
+			pending[hdr.inode] = append(pending[hdr.inode], hdr)
+
+			if hdr.size == 0 {
+				continue
+			}
+
+			err = writeCPIOLinks(tw, reader, pending[hdr.inode])
+			if err != nil {
+				return err
+			}
+
+			delete(pending, hdr.inode)
+			continue
+		}
+
+		err = writeCPIOEntry(tw, reader, hdr)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Links to an empty file never have an entry with content.
+	for _, inode := range inodes {
+		if headers, ok := pending[inode]; ok {
+			err := writeCPIOLinks(tw, reader, headers)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tw.Close()
+}
+
+// writeCPIOLinks writes the last of the headers, which holds the content, as
+// a regular file and the others as hard links to it.
+func writeCPIOLinks(tw *tar.Writer, reader io.Reader, headers []cpioHeader) error {
+	target := headers[len(headers)-1]
+	err := writeCPIOEntry(tw, reader, target)
+	if err != nil {
+		return err
+	}
+
+	for _, hdr := range headers[:len(headers)-1] {
+		err = tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeLink,
+			Name:     hdr.name,
+			Linkname: target.name,
+			Mode:     hdr.mode &^ cpioModeTypeMask,
+			Uid:      hdr.uid,
+			Gid:      hdr.gid,
+			ModTime:  hdr.modTime,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeCPIOEntry(tw *tar.Writer, reader io.Reader, hdr cpioHeader) error {
+	header := &tar.Header{
+		Name:    hdr.name,
+		Mode:    hdr.mode &^ cpioModeTypeMask,
+		Uid:     hdr.uid,
+		Gid:     hdr.gid,
+		ModTime: hdr.modTime,
+	}
+
+	switch hdr.mode & cpioModeTypeMask {
+	case cpioModeDirectory:
+		header.Typeflag = tar.TypeDir
+	case cpioModeSymlink:
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = hdr.linkname
+	case cpioModeRegular:
+		header.Typeflag = tar.TypeReg
+		header.Size = hdr.size
+	default:
+		// Device files, fifos and sockets are not extracted.
+		return skipCPIOData(reader, hdr.size+(4-hdr.size%4)%4)
+	}
+
+	err := tw.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+
+	if header.Typeflag != tar.TypeReg {
+		return nil
+	}
+
+	_, err = io.CopyN(tw, reader, hdr.size)
+	if err != nil {
+		return fmt.Errorf("failed to read cpio entry %q: %w", hdr.name, err)
+	}
+
+	return skipCPIOData(reader, (4-hdr.size%4)%4)
+}
+
+func readCPIOHeader(reader io.Reader) (cpioHeader, error) {
+	raw := make([]byte, cpioHeaderLength)
+	_, err := io.ReadFull(reader, raw)
+	if err != nil {
+		return cpioHeader{}, fmt.Errorf("failed to read cpio header: %w", err)
+	}
+
+	if string(raw[:6]) != "070701" && string(raw[:6]) != "070702" {
+		return cpioHeader{}, errors.New("failed to read cpio header: unsupported format")
+	}
+
+	// The header is made of 13 eight character hexadecimal fields following
+	// the magic number.
+	var fields [13]int64
+	for i := range fields {
+		fields[i], err = strconv.ParseInt(string(raw[6+i*8:14+i*8]), 16, 64)
+		if err != nil {
+			return cpioHeader{}, fmt.Errorf("failed to read cpio header: %w", err)
+		}
+	}
+
+	hdr := cpioHeader{
+		mode:    fields[1],
+		uid:     int(fields[2]),
+		gid:     int(fields[3]),
+		nlink:   fields[4],
+		modTime: time.Unix(fields[5], 0),
+		size:    fields[6],
+		inode:   fmt.Sprintf("%x:%x:%x", fields[7], fields[8], fields[0]),
+	}
+
+	// The name is NUL terminated and, together with the header, padded to a
+	// multiple of 4 bytes.
+	nameSize := fields[11]
+	if nameSize > cpioMaxPathLength {
+		return cpioHeader{}, fmt.Errorf("failed to read cpio header: name is %d bytes long, exceeding the limit of %d bytes", nameSize, cpioMaxPathLength)
+	}
+
+	name := make([]byte, nameSize+(4-(cpioHeaderLength+nameSize)%4)%4)
+	_, err = io.ReadFull(reader, name)
+	if err != nil {
+		return cpioHeader{}, fmt.Errorf("failed to read cpio header: %w", err)
+	}
+	hdr.name = string(bytes.TrimRight(name[:nameSize], "\x00"))
+
+	if hdr.mode&cpioModeTypeMask == cpioModeSymlink {
+		if hdr.size > cpioMaxPathLength {
+			return cpioHeader{}, fmt.Errorf("failed to read cpio symlink %q: target is %d bytes long, exceeding the limit of %d bytes", hdr.name, hdr.size, cpioMaxPathLength)
+		}
+
+		target := make([]byte, hdr.size)
+		_, err = io.ReadFull(reader, target)
+		if err != nil {
+			return cpioHeader{}, fmt.Errorf("failed to read cpio symlink %q: %w", hdr.name, err)
+		}
+		hdr.linkname = string(target)
+
+		err = skipCPIOData(reader, (4-hdr.size%4)%4)
+		if err != nil {
+			return cpioHeader{}, err
+		}
+		hdr.size = 0
+	}
+
+	return hdr, nil
+}
+
+func skipCPIOData(reader io.Reader, size int64) error {
+	_, err := io.CopyN(io.Discard, reader, size)
+	if err != nil {
+		return fmt.Errorf("failed to read cpio data: %w", err)
+	}
+
+	return nil
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (ra RPMArchive) WithManifest(manifest *Manifest) RPMArchive {
+	ra.manifest = manifest
+	return ra
+}
+
+// WithLinkPolicy sets the policy that symlinks and hard links must satisfy
+// before they are created.
+func (ra RPMArchive) WithLinkPolicy(policy LinkPolicy) RPMArchive {
+	ra.linkPolicy = policy
+	return ra
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (ra RPMArchive) WithProgress(reporter ProgressReporter) RPMArchive {
+	ra.progress = reporter
+	return ra
+}
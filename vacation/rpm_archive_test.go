@@ -0,0 +1,287 @@
+package vacation_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/paketo-buildpacks/packit/v2/vacation"
+	"github.com/sclevine/spec"
+	"github.com/ulikunitz/xz"
+
+	. "github.com/onsi/gomega"
+)
+
+type cpioEntry struct {
+	name    string
+	mode    int
+	inode   int
+	nlink   int
+	content string
+}
+
+// newRPMPackage returns an RPM package with empty signature and main headers
+// and a payload holding the given entries.
+func newRPMPackage(t *testing.T, entries []cpioEntry, compress func(io.Writer) io.WriteCloser) []byte {
+	payload := bytes.NewBuffer(nil)
+	for i, entry := range append(entries, cpioEntry{name: "TRAILER!!!", nlink: 1}) {
+		inode := entry.inode
+		if inode == 0 {
+			inode = i + 1
+		}
+
+		nlink := entry.nlink
+		if nlink == 0 {
+			nlink = 1
+		}
+
+		fmt.Fprintf(payload, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+			inode, entry.mode, 0, 0, nlink, 0, len(entry.content), 0, 0, 0, 0, len(entry.name)+1, 0)
+		payload.WriteString(entry.name + "\x00")
+		for payload.Len()%4 != 0 {
+			payload.WriteByte(0)
+		}
+
+		payload.WriteString(entry.content)
+		for payload.Len()%4 != 0 {
+			payload.WriteByte(0)
+		}
+	}
+
+	buffer := bytes.NewBuffer(nil)
+
+	lead := make([]byte, 96)
+	copy(lead, []byte{0xed, 0xab, 0xee, 0xdb, 0x03, 0x00})
+	buffer.Write(lead)
+
+	// The signature header has a store of 5 bytes so that it must be padded to
+	// an 8 byte boundary.
+	header := []byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0}
+	buffer.Write(header)
+	buffer.Write([]byte{0, 0, 0, 0, 0, 0, 0, 5})
+	buffer.Write([]byte{1, 2, 3, 4, 5, 0, 0, 0})
+
+	buffer.Write(header)
+	buffer.Write([]byte{0, 0, 0, 1, 0, 0, 0, 3})
+	buffer.Write(make([]byte, 16+3))
+
+	if compress == nil {
+		buffer.Write(payload.Bytes())
+		return buffer.Bytes()
+	}
+
+	w := compress(buffer)
+	_, err := w.Write(payload.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
+
+func testRPMArchive(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		entries []cpioEntry
+	)
+
+	it.Before(func() {
+		entries = []cpioEntry{
+			{name: "./usr", mode: 0040755},
+			{name: "./usr/bin", mode: 0040755},
+			{name: "./usr/bin/some-binary", mode: 0100755, content: "some-binary"},
+			{name: "./usr/bin/some-symlink", mode: 0120777, content: "some-binary"},
+			{name: "./usr/bin/some-hardlink", mode: 0100755, inode: 100, nlink: 2},
+			{name: "./usr/bin/other-binary", mode: 0100755, inode: 100, nlink: 2, content: "other-binary"},
+			{name: "./some-file", mode: 0100644, content: "some-file"},
+			{name: "./some-fifo", mode: 0010644},
+		}
+	})
+
+	context("Decompress", func() {
+		var (
+			tempDir string
+		)
+
+		it.Before(func() {
+			var err error
+			tempDir, err = os.MkdirTemp("", "vacation")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(tempDir)).To(Succeed())
+		})
+
+		it("unpackages the payload of the package into the path", func() {
+			rpmArchive := vacation.NewRPMArchive(bytes.NewReader(newRPMPackage(t, entries, func(w io.Writer) io.WriteCloser {
+				return gzip.NewWriter(w)
+			})))
+
+			err := rpmArchive.Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf([]string{
+				filepath.Join(tempDir, "usr"),
+				filepath.Join(tempDir, "some-file"),
+			}))
+
+			info, err := os.Stat(filepath.Join(tempDir, "usr", "bin", "some-binary"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0755)))
+
+			info, err = os.Stat(filepath.Join(tempDir, "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0644)))
+
+			link, err := os.Readlink(filepath.Join(tempDir, "usr", "bin", "some-symlink"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).To(Equal("some-binary"))
+
+			content, err := os.ReadFile(filepath.Join(tempDir, "usr", "bin", "some-hardlink"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("other-binary"))
+
+			hardlink, err := os.Stat(filepath.Join(tempDir, "usr", "bin", "some-hardlink"))
+			Expect(err).NotTo(HaveOccurred())
+			target, err := os.Stat(filepath.Join(tempDir, "usr", "bin", "other-binary"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.SameFile(hardlink, target)).To(BeTrue())
+		})
+
+		it("unpackages the payload into the path but also strips the first component", func() {
+			rpmArchive := vacation.NewRPMArchive(bytes.NewReader(newRPMPackage(t, entries[:4], nil)))
+
+			err := rpmArchive.StripComponents(1).Decompress(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			files, err := filepath.Glob(filepath.Join(tempDir, "*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf([]string{
+				filepath.Join(tempDir, "bin"),
+			}))
+
+			Expect(filepath.Join(tempDir, "bin", "some-binary")).To(BeARegularFile())
+		})
+
+		context("when the payload is xz compressed", func() {
+			it("unpackages the payload into the path", func() {
+				rpmArchive := vacation.NewRPMArchive(bytes.NewReader(newRPMPackage(t, entries, func(w io.Writer) io.WriteCloser {
+					xzw, err := xz.NewWriter(w)
+					Expect(err).NotTo(HaveOccurred())
+					return xzw
+				})))
+
+				err := rpmArchive.Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(tempDir, "usr", "bin", "some-binary"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-binary"))
+			})
+		})
+
+		context("when the payload is zstd compressed", func() {
+			it("unpackages the payload into the path", func() {
+				rpmArchive := vacation.NewRPMArchive(bytes.NewReader(newRPMPackage(t, entries, func(w io.Writer) io.WriteCloser {
+					zw, err := zstd.NewWriter(w)
+					Expect(err).NotTo(HaveOccurred())
+					return zw
+				})))
+
+				err := rpmArchive.Decompress(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(tempDir, "usr", "bin", "some-binary"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("some-binary"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the input is not an rpm package", func() {
+				it("returns an error", func() {
+					err := vacation.NewRPMArchive(bytes.NewBuffer([]byte("something"))).Decompress(tempDir)
+					Expect(err).To(MatchError("failed to read rpm archive: invalid lead"))
+				})
+			})
+
+			context("when the signature header is malformed", func() {
+				it("returns an error", func() {
+					lead := make([]byte, 96)
+					copy(lead, []byte{0xed, 0xab, 0xee, 0xdb})
+
+					err := vacation.NewRPMArchive(bytes.NewReader(append(lead, []byte("some-bad-header!")...))).Decompress(tempDir)
+					Expect(err).To(MatchError("failed to read rpm signature header: invalid header magic"))
+				})
+			})
+
+			context("when the payload compression is not supported", func() {
+				it("returns an error", func() {
+					pkg := newRPMPackage(t, nil, nil)
+					pkg = append(pkg[:len(pkg)-124], []byte("some-unknown-payload")...)
+
+					err := vacation.NewRPMArchive(bytes.NewReader(pkg)).Decompress(tempDir)
+					Expect(err).To(MatchError("failed to read rpm payload: unsupported payload compression"))
+				})
+			})
+
+			context("when the payload is truncated", func() {
+				it("returns an error", func() {
+					pkg := newRPMPackage(t, entries, nil)
+
+					err := vacation.NewRPMArchive(bytes.NewReader(pkg[:len(pkg)-200])).Decompress(tempDir)
+					Expect(err).To(MatchError(ContainSubstring("failed to read cpio")))
+				})
+			})
+
+			context("when a symlink target in the payload is too long", func() {
+				it("returns an error without reading it", func() {
+					pkg := newRPMPackage(t, []cpioEntry{
+						{name: "some-symlink", mode: 0120777, content: "some-target"},
+					}, nil)
+
+					// Claim a 4 GiB symlink target in the header of the entry.
+					pkg = bytes.Replace(pkg, []byte("0000000b00000000"), []byte("ffffffff00000000"), 1)
+
+					err := vacation.NewRPMArchive(bytes.NewReader(pkg)).Decompress(tempDir)
+					Expect(err).To(MatchError(ContainSubstring(`failed to read cpio symlink "some-symlink": target is 4294967295 bytes long, exceeding the limit of 4096 bytes`)))
+				})
+			})
+
+			context("when a name in the payload is too long", func() {
+				it("returns an error", func() {
+					err := vacation.NewRPMArchive(bytes.NewReader(newRPMPackage(t, []cpioEntry{
+						{name: strings.Repeat("a", 4097), mode: 0100644, content: "some-file"},
+					}, nil))).Decompress(tempDir)
+					Expect(err).To(MatchError(ContainSubstring("name is 4098 bytes long, exceeding the limit of 4096 bytes")))
+				})
+			})
+
+			context("when the payload contains a zip-slip path", func() {
+				it("returns an error", func() {
+					rpmArchive := vacation.NewRPMArchive(bytes.NewReader(newRPMPackage(t, []cpioEntry{
+						{name: "../some-file", mode: 0100644, content: "some-file"},
+					}, nil)))
+
+					err := rpmArchive.Decompress(tempDir)
+					Expect(err).To(MatchError(ContainSubstring("illegal file path \"../some-file\"")))
+				})
+			})
+		})
+	})
+}
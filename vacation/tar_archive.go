@@ -11,8 +11,9 @@ import (
 
 // A TarArchive decompresses tar files from an input stream.
 type TarArchive struct {
-	reader     io.Reader
-	components int
+	extractOptions
+
+	reader io.Reader
 }
 
 // NewTarArchive returns a new TarArchive that reads from inputReader.
@@ -23,6 +24,14 @@ func NewTarArchive(inputReader io.Reader) TarArchive {
 // Decompress reads from TarArchive and writes files into the
 // destination specified.
 func (ta TarArchive) Decompress(destination string) error {
+	limits, reader := ta.limits.count(ta.reader)
+	progress, reader := trackProgress(ta.progress, reader)
+	limiter := newLimiter(limits)
+
+	return limiter.cleanup(ta.decompress(reader, destination, limiter, progress))
+}
+
+func (ta TarArchive) decompress(reader io.Reader, destination string, limiter *limiter, progress *progressTracker) error {
 	// This map keeps track of what directories have been made already so that we
 	// only attempt to make them once for a cleaner interaction.  This map is
 	// only necessary in cases where there are no directory headers in the
@@ -30,10 +39,22 @@ func (ta TarArchive) Decompress(destination string) error {
 	// metadata.
 	directories := map[string]interface{}{}
 
+	filter, err := newPathFilter(ta.includes, ta.excludes)
+	if err != nil {
+		return err
+	}
+
+	restorer, err := newMetadataRestorer(ta.preserve)
+	if err != nil {
+		return err
+	}
+
+	recorder := newManifestRecorder(ta.manifest, destination)
+
 	var symlinks []link
 	var links []link
 
-	tarReader := tar.NewReader(ta.reader)
+	tarReader := tar.NewReader(reader)
 	for {
 		hdr, err := tarReader.Next()
 		if err == io.EOF {
@@ -51,6 +72,13 @@ func (ta TarArchive) Decompress(destination string) error {
 			continue
 		}
 
+		err = limiter.entry(name)
+		if err != nil {
+			return err
+		}
+
+		progress.entry(name)
+
 		err = checkExtractPath(name, destination)
 		if err != nil {
 			return err
@@ -63,6 +91,12 @@ func (ta TarArchive) Decompress(destination string) error {
 			continue
 		}
 
+		// Skips entries that are not selected by the include and exclude globs
+		// without writing them.
+		if !filter.match(strings.Join(fileNames[ta.components:], "/")) {
+			continue
+		}
+
 		// Constructs the path that conforms to the stripped components.
 		path := filepath.Join(append([]string{destination}, fileNames[ta.components:]...)...)
 
@@ -70,18 +104,20 @@ func (ta TarArchive) Decompress(destination string) error {
 		// this logic is needed to handle tarballs with no directory headers.
 		switch hdr.Typeflag {
 		case tar.TypeDir:
-			err = os.MkdirAll(path, os.ModePerm)
+			err = limiter.mkdirAll(path)
 			if err != nil {
 				return fmt.Errorf("failed to create archived directory: %s", err)
 			}
 
 			directories[path] = nil
+			restorer.recordTar(path, hdr)
+			recorder.dir(path, hdr.FileInfo().Mode())
 
 		default:
 			dir := filepath.Dir(path)
 			_, ok := directories[dir]
 			if !ok {
-				err = os.MkdirAll(dir, os.ModePerm)
+				err = limiter.mkdirAll(dir)
 				if err != nil {
 					return fmt.Errorf("failed to create archived directory from file path: %s", err)
 				}
@@ -96,8 +132,10 @@ func (ta TarArchive) Decompress(destination string) error {
 			if err != nil {
 				return fmt.Errorf("failed to create archived file: %s", err)
 			}
+			limiter.track(path)
 
-			_, err = io.Copy(file, tarReader)
+			content := recorder.content(tarReader)
+			err = limiter.copy(file, content, name)
 			if err != nil {
 				return err
 			}
@@ -107,6 +145,9 @@ func (ta TarArchive) Decompress(destination string) error {
 				return err
 			}
 
+			restorer.recordTar(path, hdr)
+			recorder.file(path, hdr.FileInfo().Mode(), content)
+
 		case tar.TypeLink:
 			// Collect all of the headers for links so that they can be verified
 			// after all other files are written
@@ -122,25 +163,36 @@ func (ta TarArchive) Decompress(destination string) error {
 				name: hdr.Linkname,
 				path: path,
 			})
+			restorer.recordTar(path, hdr)
 		}
 	}
 
-	symlinks, err := sortLinks(symlinks)
+	symlinks, err = sortLinks(ta.linkPolicy.rewrite(destination, symlinks))
 	if err != nil {
 		return err
 	}
 
 	for _, link := range symlinks {
 		// Check to see if the file that will be linked to is valid for symlinking
-		_, err := filepath.EvalSymlinks(linknameFullPath(link.path, link.name))
+		err := ta.linkPolicy.evaluateSymlink(link)
 		if err != nil {
+			if filter.excluded(destination, linknameFullPath(link.path, link.name)) {
+				return fmt.Errorf("failed to extract symlink %s: its target %q was excluded from extraction", link.path, link.name)
+			}
+
 			return fmt.Errorf("failed to evaluate symlink %s: %w", link.path, err)
 		}
 
+		if !ta.linkPolicy.containsSymlink(destination, link) {
+			return fmt.Errorf("failed to extract symlink %s: its target %q is outside of the destination directory", link.path, link.name)
+		}
+
 		err = os.Symlink(link.name, link.path)
 		if err != nil {
 			return fmt.Errorf("failed to extract symlink: %s", err)
 		}
+		limiter.track(link.path)
+		recorder.symlink(link.path, link.name)
 	}
 
 	links, err = sortLinks(links)
@@ -149,12 +201,29 @@ func (ta TarArchive) Decompress(destination string) error {
 	}
 
 	for _, link := range links {
+		if !ta.linkPolicy.containsLink(destination, filepath.Join(destination, link.name)) {
+			return fmt.Errorf("failed to extract link %s: its target %q is outside of the destination directory", link.path, link.name)
+		}
+
 		err := os.Link(filepath.Join(destination, link.name), link.path)
 		if err != nil {
+			if filter.excluded(destination, filepath.Join(destination, link.name)) {
+				return fmt.Errorf("failed to extract link %s: its target %q was excluded from extraction", link.path, link.name)
+			}
+
 			return fmt.Errorf("failed to extract link: %s", err)
 		}
+		limiter.track(link.path)
+		recorder.link(link.path, filepath.Join(destination, link.name))
 	}
 
+	err = restorer.restore()
+	if err != nil {
+		return err
+	}
+
+	recorder.commit()
+
 	return nil
 }
 
@@ -164,3 +233,52 @@ func (ta TarArchive) StripComponents(components int) TarArchive {
 	ta.components = components
 	return ta
 }
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (ta TarArchive) WithLimits(limits Limits) TarArchive {
+	ta.limits = limits
+	return ta
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs. The globs are matched against the path of an entry after its leading
+// components are stripped and a glob that matches a directory includes
+// everything below it.
+func (ta TarArchive) WithIncludes(globs ...string) TarArchive {
+	ta.includes = globs
+	return ta
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs. Excludes take precedence over includes.
+func (ta TarArchive) WithExcludes(globs ...string) TarArchive {
+	ta.excludes = globs
+	return ta
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files.
+func (ta TarArchive) WithPreserve(options PreserveOptions) TarArchive {
+	ta.preserve = options
+	return ta
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (ta TarArchive) WithManifest(manifest *Manifest) TarArchive {
+	ta.manifest = manifest
+	return ta
+}
+
+// WithLinkPolicy sets the policy that symlinks and hard links must satisfy
+// before they are created.
+func (ta TarArchive) WithLinkPolicy(policy LinkPolicy) TarArchive {
+	ta.linkPolicy = policy
+	return ta
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (ta TarArchive) WithProgress(reporter ProgressReporter) TarArchive {
+	ta.progress = reporter
+	return ta
+}
@@ -1,5 +1,6 @@
 // Package vacation provides a set of functions that enable input stream
 // decompression logic from several popular decompression formats. This allows
 // from decompression from either a file or any other byte stream, which is
-// useful for decompressing files that are being downloaded.
+// useful for decompressing files that are being downloaded. It also provides
+// an Archiver that creates reproducible archives from a directory.
 package vacation
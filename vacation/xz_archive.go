@@ -9,9 +9,10 @@ import (
 
 // A XZArchive decompresses xz files from an input stream.
 type XZArchive struct {
-	reader     io.Reader
-	components int
-	name       string
+	extractOptions
+
+	reader io.Reader
+	name   string
 }
 
 // NewXZArchive returns a new XZArchive that reads from inputReader.
@@ -22,12 +23,15 @@ func NewXZArchive(inputReader io.Reader) XZArchive {
 // Decompress reads from XZArchive and writes files into the destination
 // specified.
 func (xzArchive XZArchive) Decompress(destination string) error {
-	xzr, err := xz.NewReader(xzArchive.reader)
+	limits, reader := xzArchive.limits.count(xzArchive.reader)
+	progress, reader := trackProgress(xzArchive.progress, reader)
+
+	xzr, err := xz.NewReader(reader)
 	if err != nil {
 		return fmt.Errorf("failed to create xz reader: %w", err)
 	}
 
-	return NewArchive(xzr).WithName(xzArchive.name).StripComponents(xzArchive.components).Decompress(destination)
+	return Archive{extractOptions: xzArchive.forward(limits, progress), reader: xzr, name: xzArchive.name}.Decompress(destination)
 }
 
 // StripComponents behaves like the --strip-components flag on tar command
@@ -43,3 +47,50 @@ func (xzArchive XZArchive) WithName(name string) XZArchive {
 	xzArchive.name = name
 	return xzArchive
 }
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (xzArchive XZArchive) WithLimits(limits Limits) XZArchive {
+	xzArchive.limits = limits
+	return xzArchive
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs.
+func (xzArchive XZArchive) WithIncludes(globs ...string) XZArchive {
+	xzArchive.includes = globs
+	return xzArchive
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs.
+func (xzArchive XZArchive) WithExcludes(globs ...string) XZArchive {
+	xzArchive.excludes = globs
+	return xzArchive
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files.
+func (xzArchive XZArchive) WithPreserve(options PreserveOptions) XZArchive {
+	xzArchive.preserve = options
+	return xzArchive
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (xzArchive XZArchive) WithManifest(manifest *Manifest) XZArchive {
+	xzArchive.manifest = manifest
+	return xzArchive
+}
+
+// WithLinkPolicy sets the policy that symlinks and hard links must satisfy
+// before they are created.
+func (xzArchive XZArchive) WithLinkPolicy(policy LinkPolicy) XZArchive {
+	xzArchive.linkPolicy = policy
+	return xzArchive
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (xzArchive XZArchive) WithProgress(reporter ProgressReporter) XZArchive {
+	xzArchive.progress = reporter
+	return xzArchive
+}
@@ -2,6 +2,9 @@ package vacation
 
 import (
 	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"os"
@@ -10,9 +13,22 @@ import (
 )
 
 // A ZipArchive decompresses zip files from an input stream.
+//
+// When the input implements io.ReaderAt and has a Size method, as
+// *bytes.Reader does, or is an *os.File, the zip file is read from it directly.
+// Otherwise the entries are extracted as they are streamed, unless a spool
+// directory is given with WithSpoolDir or the zip file does not begin with a
+// local file header, in which case the input is buffered in a temporary file.
 type ZipArchive struct {
-	reader     io.Reader
-	components int
+	extractOptions
+
+	reader   io.Reader
+	spoolDir string
+}
+
+type sizedReaderAt interface {
+	io.ReaderAt
+	Size() int64
 }
 
 // NewZipArchive returns a new ZipArchive that reads from inputReader.
@@ -23,22 +39,96 @@ func NewZipArchive(inputReader io.Reader) ZipArchive {
 // Decompress reads from ZipArchive and writes files into the destination
 // specified.
 func (z ZipArchive) Decompress(destination string) error {
+	if input, size, ok := readerAt(z.reader); ok {
+		limits := z.limits
+		if limits.MaxCompressionRatio > 0 {
+			// All of the input is counted as it is read from directly.
+			limits.compressed = &countingReader{count: size}
+		}
+
+		// The entries are read from the input directly, so progress is reported
+		// by the offset of each entry within it.
+		progress, _ := trackProgress(z.progress, z.reader)
+		limiter := newLimiter(limits)
+		return limiter.cleanup(z.decompress(input, size, destination, limiter, progress))
+	}
+
+	limits, reader := z.limits.count(z.reader)
+	progress, reader := trackProgress(z.progress, reader)
+	limiter := newLimiter(limits)
+
+	// Zip files that do not begin with a local file header, such as
+	// self-extracting archives with a stub prepended to them, can only be read
+	// through their central directory and so are always spooled.
+	stream := bufio.NewReader(reader)
+	signature, err := stream.Peek(4)
+	if z.spoolDir == "" && err == nil && binary.LittleEndian.Uint32(signature) == zipLocalHeaderSignature {
+		return limiter.cleanup(z.decompressStream(stream, destination, limiter, progress))
+	}
+
+	return limiter.cleanup(z.spool(stream, destination, limiter, progress))
+}
 
-	// Use an os.File to buffer the zip contents. This is needed because
-	// zip.NewReader requires an io.ReaderAt so that it can jump around within
-	// the file as it decompresses.
-	buffer, err := os.CreateTemp("", "")
+// readerAt returns the input as an io.ReaderAt along with its size when it
+// can be read from directly.
+func readerAt(reader io.Reader) (io.ReaderAt, int64, bool) {
+	switch input := reader.(type) {
+	case sizedReaderAt:
+		return input, input.Size(), true
+
+	case *os.File:
+		info, err := input.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil, 0, false
+		}
+
+		return input, info.Size(), true
+	}
+
+	return nil, 0, false
+}
+
+// spool buffers the input in a file in the spool directory, as zip.NewReader
+// requires an io.ReaderAt so that it can jump around within the file as it
+// decompresses.
+func (z ZipArchive) spool(reader io.Reader, destination string, limiter *limiter, progress *progressTracker) error {
+	buffer, err := os.CreateTemp(z.spoolDir, "")
 	if err != nil {
 		return err
 	}
 	defer os.Remove(buffer.Name())
+	defer buffer.Close()
+
+	if z.limits.MaxTotalSize > 0 {
+		reader = io.LimitReader(reader, z.limits.MaxTotalSize+1)
+	}
 
-	size, err := io.Copy(buffer, z.reader)
+	size, err := io.Copy(buffer, reader)
 	if err != nil {
 		return err
 	}
 
-	zr, err := zip.NewReader(buffer, size)
+	if z.limits.MaxTotalSize > 0 && size > z.limits.MaxTotalSize {
+		return &LimitError{Limit: LimitTotalSize, Max: float64(z.limits.MaxTotalSize)}
+	}
+
+	return z.decompress(buffer, size, destination, limiter, progress)
+}
+
+func (z ZipArchive) decompress(input io.ReaderAt, size int64, destination string, limiter *limiter, progress *progressTracker) error {
+	filter, err := newPathFilter(z.includes, z.excludes)
+	if err != nil {
+		return err
+	}
+
+	restorer, err := newMetadataRestorer(z.preserve)
+	if err != nil {
+		return err
+	}
+
+	recorder := newManifestRecorder(z.manifest, destination)
+
+	zr, err := zip.NewReader(input, size)
 	if err != nil {
 		return fmt.Errorf("failed to create zip reader: %w", err)
 	}
@@ -53,6 +143,16 @@ func (z ZipArchive) Decompress(destination string) error {
 			continue
 		}
 
+		err = limiter.entry(name)
+		if err != nil {
+			return err
+		}
+
+		progress.entry(name)
+		if offset, err := f.DataOffset(); err == nil {
+			progress.seek(offset)
+		}
+
 		err = checkExtractPath(name, destination)
 		if err != nil {
 			return err
@@ -65,22 +165,33 @@ func (z ZipArchive) Decompress(destination string) error {
 			continue
 		}
 
+		// Skips entries that are not selected by the include and exclude globs
+		// without writing them.
+		if !filter.match(strings.Join(fileNames[z.components:], "/")) {
+			continue
+		}
+
 		// Constructs the path that conforms to the stripped components.
 		path := filepath.Join(append([]string{destination}, fileNames[z.components:]...)...)
 
 		switch {
 		case f.FileInfo().IsDir():
-			err = os.MkdirAll(path, os.ModePerm)
+			err = limiter.mkdirAll(path)
 			if err != nil {
 				return fmt.Errorf("failed to unzip directory: %w", err)
 			}
+
+			restorer.recordModTime(path, f.Mode(), f.Modified)
+			recorder.dir(path, f.Mode())
+
 		case f.FileInfo().Mode()&os.ModeSymlink != 0:
 			fd, err := f.Open()
 			if err != nil {
 				return err
 			}
 
-			linkname, err := io.ReadAll(fd)
+			var linkname bytes.Buffer
+			err = limiter.copy(&linkname, fd, name)
 			if err != nil {
 				return err
 			}
@@ -88,12 +199,13 @@ func (z ZipArchive) Decompress(destination string) error {
 			// Collect all of the headers for symlinks so that they can be verified
 			// after all other files are written
 			symlinks = append(symlinks, link{
-				name: string(linkname),
+				name: linkname.String(),
 				path: path,
 			})
+			restorer.recordModTime(path, f.Mode(), f.Modified)
 
 		default:
-			err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+			err = limiter.mkdirAll(filepath.Dir(path))
 			if err != nil {
 				return fmt.Errorf("failed to unzip directory that was part of file path: %w", err)
 			}
@@ -102,13 +214,15 @@ func (z ZipArchive) Decompress(destination string) error {
 			if err != nil {
 				return fmt.Errorf("failed to unzip file: %w", err)
 			}
+			limiter.track(path)
 
 			src, err := f.Open()
 			if err != nil {
 				return err
 			}
 
-			_, err = io.Copy(dst, src)
+			content := recorder.content(src)
+			err = limiter.copy(dst, content, name)
 			if err != nil {
 				return err
 			}
@@ -120,25 +234,56 @@ func (z ZipArchive) Decompress(destination string) error {
 			if err := src.Close(); err != nil {
 				return err
 			}
+
+			restorer.recordModTime(path, f.Mode(), f.Modified)
+			recorder.file(path, f.Mode(), content)
 		}
 	}
 
-	symlinks, err = sortLinks(symlinks)
+	progress.seek(size)
+
+	err = z.createSymlinks(symlinks, destination, filter, limiter, recorder)
+	if err != nil {
+		return err
+	}
+
+	err = restorer.restore()
+	if err != nil {
+		return err
+	}
+
+	recorder.commit()
+
+	return nil
+}
+
+func (z ZipArchive) createSymlinks(symlinks []link, destination string, filter pathFilter, limiter *limiter, recorder *manifestRecorder) error {
+	symlinks, err := sortLinks(z.linkPolicy.rewrite(destination, symlinks))
 	if err != nil {
 		return err
 	}
 
 	for _, link := range symlinks {
 		// Check to see if the file that will be linked to is valid for symlinking
-		_, err := filepath.EvalSymlinks(linknameFullPath(link.path, link.name))
+		err := z.linkPolicy.evaluateSymlink(link)
 		if err != nil {
+			if filter.excluded(destination, linknameFullPath(link.path, link.name)) {
+				return fmt.Errorf("failed to unzip symlink %s: its target %q was excluded from extraction", link.path, link.name)
+			}
+
 			return fmt.Errorf("failed to evaluate symlink %s: %w", link.path, err)
 		}
 
+		if !z.linkPolicy.containsSymlink(destination, link) {
+			return fmt.Errorf("failed to unzip symlink %s: its target %q is outside of the destination directory", link.path, link.name)
+		}
+
 		err = os.Symlink(link.name, link.path)
 		if err != nil {
 			return fmt.Errorf("failed to unzip symlink: %s", err)
 		}
+		limiter.track(link.path)
+		recorder.symlink(link.path, link.name)
 	}
 
 	return nil
@@ -150,3 +295,63 @@ func (z ZipArchive) StripComponents(components int) ZipArchive {
 	z.components = components
 	return z
 }
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (z ZipArchive) WithLimits(limits Limits) ZipArchive {
+	z.limits = limits
+	return z
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs. The globs are matched against the path of an entry after its leading
+// components are stripped and a glob that matches a directory includes
+// everything below it.
+func (z ZipArchive) WithIncludes(globs ...string) ZipArchive {
+	z.includes = globs
+	return z
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs. Excludes take precedence over includes.
+func (z ZipArchive) WithExcludes(globs ...string) ZipArchive {
+	z.excludes = globs
+	return z
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files. Zip files only record modification
+// times.
+func (z ZipArchive) WithPreserve(options PreserveOptions) ZipArchive {
+	z.preserve = options
+	return z
+}
+
+// WithSpoolDir buffers input that cannot be read from directly in a
+// temporary file in the given directory, rather than extracting its entries as
+// they are streamed. Reading the zip file from a buffer uses its central
+// directory, so it supports zip files that cannot be streamed, such as those
+// with data prepended to them.
+func (z ZipArchive) WithSpoolDir(dir string) ZipArchive {
+	z.spoolDir = dir
+	return z
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (z ZipArchive) WithManifest(manifest *Manifest) ZipArchive {
+	z.manifest = manifest
+	return z
+}
+
+// WithLinkPolicy sets the policy that symlinks must satisfy before they are
+// created.
+func (z ZipArchive) WithLinkPolicy(policy LinkPolicy) ZipArchive {
+	z.linkPolicy = policy
+	return z
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (z ZipArchive) WithProgress(reporter ProgressReporter) ZipArchive {
+	z.progress = reporter
+	return z
+}
@@ -22,6 +22,7 @@ func testZipArchive(t *testing.T, context spec.G, it spec.S) {
 	context("Decompress", func() {
 		var (
 			tempDir    string
+			zipball    []byte
 			zipArchive vacation.ZipArchive
 		)
 
@@ -75,7 +76,8 @@ func testZipArchive(t *testing.T, context spec.G, it spec.S) {
 
 			Expect(zw.Close()).To(Succeed())
 
-			zipArchive = vacation.NewZipArchive(bytes.NewReader(buffer.Bytes()))
+			zipball = buffer.Bytes()
+			zipArchive = vacation.NewZipArchive(bytes.NewReader(zipball))
 		})
 
 		it.After(func() {
@@ -126,6 +128,172 @@ func testZipArchive(t *testing.T, context spec.G, it spec.S) {
 			Expect(filepath.Join(tempDir, "some-other-dir", "some-file")).To(BeARegularFile())
 		})
 
+		context("when the input is a stream", func() {
+			it("extracts the entries as they are read", func() {
+				err := vacation.NewZipArchive(bytes.NewBuffer(zipball)).Decompress(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+
+				info, err := os.Stat(filepath.Join(tempDir, "first"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode()).To(Equal(os.FileMode(0755)))
+
+				content, err := os.ReadFile(filepath.Join(tempDir, "some-dir", "some-other-dir", "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("nested file"))
+
+				link, err := os.Readlink(filepath.Join(tempDir, "symlink"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal("some-dir/some-other-dir/some-file"))
+			})
+
+			context("when a file in the archive already exists in the destination", func() {
+				it.Before(func() {
+					buffer := bytes.NewBuffer(nil)
+					zw := zip.NewWriter(buffer)
+
+					for _, entry := range []struct {
+						name string
+						mode os.FileMode
+					}{
+						{name: "existing", mode: 0644},
+						{name: "bin/exe", mode: 0755},
+					} {
+						header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+						header.SetMode(entry.mode)
+
+						f, err := zw.CreateHeader(header)
+						Expect(err).NotTo(HaveOccurred())
+
+						_, err = f.Write([]byte(entry.name + " content"))
+						Expect(err).NotTo(HaveOccurred())
+					}
+
+					Expect(zw.Close()).To(Succeed())
+					zipball = buffer.Bytes()
+
+					Expect(os.WriteFile(filepath.Join(tempDir, "existing"), []byte("previous content"), 0600)).To(Succeed())
+				})
+
+				it("applies the modes of the entries in the same way as when the input is not a stream", func() {
+					err := vacation.NewZipArchive(bytes.NewBuffer(zipball)).Decompress(tempDir)
+					Expect(err).ToNot(HaveOccurred())
+
+					info, err := os.Stat(filepath.Join(tempDir, "bin", "exe"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(info.Mode()).To(Equal(os.FileMode(0755)))
+
+					info, err = os.Stat(filepath.Join(tempDir, "existing"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(info.Mode()).To(Equal(os.FileMode(0600)))
+
+					content, err := os.ReadFile(filepath.Join(tempDir, "existing"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("existing content"))
+
+					otherDir, err := os.MkdirTemp("", "vacation")
+					Expect(err).NotTo(HaveOccurred())
+					defer os.RemoveAll(otherDir)
+
+					Expect(os.WriteFile(filepath.Join(otherDir, "existing"), []byte("previous content"), 0600)).To(Succeed())
+
+					err = vacation.NewZipArchive(bytes.NewReader(zipball)).Decompress(otherDir)
+					Expect(err).ToNot(HaveOccurred())
+
+					for _, name := range []string{"existing", filepath.Join("bin", "exe")} {
+						streamed, err := os.Stat(filepath.Join(tempDir, name))
+						Expect(err).NotTo(HaveOccurred())
+
+						direct, err := os.Stat(filepath.Join(otherDir, name))
+						Expect(err).NotTo(HaveOccurred())
+						Expect(streamed.Mode()).To(Equal(direct.Mode()), name)
+					}
+
+					files, err := filepath.Glob(filepath.Join(tempDir, ".vacation-umask-*"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(BeEmpty())
+				})
+			})
+
+			context("when the entries are stored with data descriptors", func() {
+				it.Before(func() {
+					buffer := bytes.NewBuffer(nil)
+					zw := zip.NewWriter(buffer)
+
+					for _, name := range []string{"first", "second"} {
+						header := &zip.FileHeader{Name: name, Method: zip.Store}
+						header.SetMode(0644)
+
+						f, err := zw.CreateHeader(header)
+						Expect(err).NotTo(HaveOccurred())
+
+						_, err = f.Write([]byte(name + " content"))
+						Expect(err).NotTo(HaveOccurred())
+					}
+
+					Expect(zw.Close()).To(Succeed())
+
+					zipball = buffer.Bytes()
+				})
+
+				it("finds the end of each entry", func() {
+					err := vacation.NewZipArchive(bytes.NewBuffer(zipball)).Decompress(tempDir)
+					Expect(err).ToNot(HaveOccurred())
+
+					content, err := os.ReadFile(filepath.Join(tempDir, "first"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("first content"))
+
+					content, err = os.ReadFile(filepath.Join(tempDir, "second"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("second content"))
+				})
+			})
+
+			context("when data is prepended to the zip file", func() {
+				it.Before(func() {
+					zipball = append([]byte("#!/bin/sh\nexit 0\n"), zipball...)
+				})
+
+				it("buffers the input and reads the zip file through its central directory", func() {
+					err := vacation.NewZipArchive(bytes.NewBuffer(zipball)).Decompress(tempDir)
+					Expect(err).ToNot(HaveOccurred())
+
+					content, err := os.ReadFile(filepath.Join(tempDir, "some-dir", "some-other-dir", "some-file"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(Equal("nested file"))
+
+					info, err := os.Stat(filepath.Join(tempDir, "first"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(info.Mode()).To(Equal(os.FileMode(0755)))
+				})
+			})
+
+			context("when a spool directory is given", func() {
+				var spoolDir string
+
+				it.Before(func() {
+					var err error
+					spoolDir, err = os.MkdirTemp("", "spool")
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it.After(func() {
+					Expect(os.RemoveAll(spoolDir)).To(Succeed())
+				})
+
+				it("buffers the input in the spool directory and removes it afterwards", func() {
+					err := vacation.NewZipArchive(bytes.NewBuffer(zipball)).WithSpoolDir(spoolDir).Decompress(tempDir)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(filepath.Join(tempDir, "some-dir", "some-other-dir", "some-file")).To(BeARegularFile())
+
+					files, err := os.ReadDir(spoolDir)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(files).To(BeEmpty())
+				})
+			})
+		})
+
 		context("when given a zip file with enough contents to exhaust file descriptors", func() {
 			it.Before(func() {
 				buffer := bytes.NewBuffer(nil)
@@ -168,6 +336,50 @@ func testZipArchive(t *testing.T, context spec.G, it spec.S) {
 				})
 			})
 
+			context("when the stream ends before the central directory", func() {
+				it("returns an error", func() {
+					err := vacation.NewZipArchive(bytes.NewBuffer(zipball[:bytes.Index(zipball, []byte("PK\x01\x02"))])).Decompress(tempDir)
+					Expect(err).To(MatchError("failed to read zip stream: missing central directory"))
+				})
+
+				it("leaves the files that were written readable only by their owner", func() {
+					err := vacation.NewZipArchive(bytes.NewBuffer(zipball[:bytes.Index(zipball, []byte("PK\x01\x02"))])).Decompress(tempDir)
+					Expect(err).To(HaveOccurred())
+
+					info, err := os.Stat(filepath.Join(tempDir, "first"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(info.Mode()).To(Equal(os.FileMode(0600)))
+				})
+			})
+
+			context("when the content of a streamed entry does not match its checksum", func() {
+				it.Before(func() {
+					buffer := bytes.NewBuffer(nil)
+					zw := zip.NewWriter(buffer)
+
+					f, err := zw.CreateRaw(&zip.FileHeader{
+						Name:               "some-file",
+						Method:             zip.Store,
+						CRC32:              1,
+						CompressedSize64:   uint64(len("some-content")),
+						UncompressedSize64: uint64(len("some-content")),
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = f.Write([]byte("some-content"))
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(zw.Close()).To(Succeed())
+
+					zipball = buffer.Bytes()
+				})
+
+				it("returns an error", func() {
+					err := vacation.NewZipArchive(bytes.NewBuffer(zipball)).Decompress(tempDir)
+					Expect(err).To(MatchError(`failed to read zip stream: "some-file": checksum does not match`))
+				})
+			})
+
 			context("when a file is not inside of the destination director (Zip Slip)", func() {
 				var buffer *bytes.Buffer
 
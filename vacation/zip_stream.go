@@ -0,0 +1,649 @@
+package vacation
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	zipLocalHeaderSignature    = 0x04034b50
+	zipCentralHeaderSignature  = 0x02014b50
+	zipEndSignature            = 0x06054b50
+	zipDataDescriptorSignature = 0x08074b50
+
+	zipLocalHeaderLength   = 26
+	zipCentralHeaderLength = 42
+
+	zip64ExtraID           = 0x0001
+	zipExtendedTimestampID = 0x5455
+
+	zipFlagEncrypted      = 0x1
+	zipFlagDataDescriptor = 0x8
+)
+
+// A zipStreamEntry is an entry that was extracted from a zip stream before
+// its mode was known.
+type zipStreamEntry struct {
+	path     string
+	written  string
+	modified time.Time
+	dir      bool
+	existed  bool
+	content  *digestReader
+}
+
+// decompressStream extracts the entries of a zip file as they are read from
+// its local file headers. The mode of an entry is only recorded in the
+// central directory at the end of the zip file, so files are written first
+// and have their modes applied, or are replaced by symlinks, once the central
+// directory has been read.
+func (z ZipArchive) decompressStream(reader io.Reader, destination string, limiter *limiter, progress *progressTracker) error {
+	filter, err := newPathFilter(z.includes, z.excludes)
+	if err != nil {
+		return err
+	}
+
+	restorer, err := newMetadataRestorer(z.preserve)
+	if err != nil {
+		return err
+	}
+
+	recorder := newManifestRecorder(z.manifest, destination)
+
+	stream := bufio.NewReader(reader)
+	extracted := map[string]zipStreamEntry{}
+
+	// The umask is measured before the first file is written so that the modes
+	// applied from the central directory are masked in the same way as when
+	// the files are created with them.
+	var umask os.FileMode = 0
+	umaskKnown := false
+
+	var signature uint32
+	started := false
+	for {
+		signature, err = readUint32(stream)
+		if err != nil {
+			if !started {
+				return fmt.Errorf("failed to create zip reader: %w", zip.ErrFormat)
+			}
+
+			if errors.Is(err, io.EOF) {
+				return errors.New("failed to read zip stream: missing central directory")
+			}
+
+			return fmt.Errorf("failed to read zip stream: %w", err)
+		}
+
+		if signature == zipCentralHeaderSignature || signature == zipEndSignature {
+			break
+		}
+
+		if signature != zipLocalHeaderSignature {
+			if !started {
+				return fmt.Errorf("failed to create zip reader: %w", zip.ErrFormat)
+			}
+
+			return fmt.Errorf("failed to read zip stream: unexpected signature %#08x", signature)
+		}
+
+		started = true
+
+		header, err := readZipLocalHeader(stream)
+		if err != nil {
+			return fmt.Errorf("failed to read zip stream: %w", err)
+		}
+
+		content, err := header.open(stream)
+		if err != nil {
+			return fmt.Errorf("failed to read zip stream: %q: %w", header.name, err)
+		}
+
+		// Clean the name in the header to prevent './filename' being stripped to
+		// 'filename' also to skip if the destination it the destination directory
+		// itself i.e. './'
+		name := filepath.Clean(header.name)
+		if name != "." {
+			err = limiter.entry(name)
+			if err != nil {
+				return err
+			}
+
+			progress.entry(name)
+
+			err = checkExtractPath(name, destination)
+			if err != nil {
+				return err
+			}
+
+			fileNames := strings.Split(name, "/")
+
+			// Only entries that survive stripping components and the include and
+			// exclude globs are written, others are read past.
+			if len(fileNames) > z.components && filter.match(strings.Join(fileNames[z.components:], "/")) {
+				path := filepath.Join(append([]string{destination}, fileNames[z.components:]...)...)
+
+				if strings.HasSuffix(header.name, "/") {
+					err = limiter.mkdirAll(path)
+					if err != nil {
+						return fmt.Errorf("failed to unzip directory: %w", err)
+					}
+
+					extracted[header.name] = zipStreamEntry{path: path, modified: header.modified, dir: true}
+				} else {
+					err = limiter.mkdirAll(filepath.Dir(path))
+					if err != nil {
+						return fmt.Errorf("failed to unzip directory that was part of file path: %w", err)
+					}
+
+					// An existing symlink at the path is not written through, as the entry
+					// may turn out to be a symlink that cannot replace it, so the content
+					// is written alongside it until the central directory is read.
+					if !umaskKnown {
+						umask, err = measureUmask(filepath.Dir(path))
+						if err != nil {
+							return fmt.Errorf("failed to unzip file: %w", err)
+						}
+						umaskKnown = true
+					}
+
+					// Files are only readable and writable by their owner until their
+					// modes are applied. A file that already exists keeps its mode, as it
+					// does when it is truncated by the zip extraction of an io.ReaderAt.
+					var file *os.File
+					info, err := os.Lstat(path)
+					existed := err == nil && info.Mode()&os.ModeSymlink == 0
+					if err == nil && info.Mode()&os.ModeSymlink != 0 {
+						file, err = os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path))
+						if err != nil {
+							return fmt.Errorf("failed to unzip file: %w", err)
+						}
+					} else {
+						file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+						if err != nil {
+							return fmt.Errorf("failed to unzip file: %w", err)
+						}
+					}
+					limiter.track(file.Name())
+
+					digest := recorder.content(content)
+					err = limiter.copy(file, digest, name)
+					if err != nil {
+						var limitErr *LimitError
+						if errors.As(err, &limitErr) {
+							return err
+						}
+
+						return fmt.Errorf("failed to read zip stream: %q: %w", header.name, err)
+					}
+
+					err = file.Close()
+					if err != nil {
+						return err
+					}
+
+					extracted[header.name] = zipStreamEntry{path: path, written: file.Name(), modified: header.modified, existed: existed, content: digest}
+				}
+			}
+		}
+
+		err = content.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read zip stream: %q: %w", header.name, err)
+		}
+	}
+
+	modes := map[string]os.FileMode{}
+	if signature == zipCentralHeaderSignature {
+		modes, err = readZipCentralDirectory(stream)
+		if err != nil {
+			return fmt.Errorf("failed to read zip stream: %w", err)
+		}
+	}
+
+	var symlinks []link
+	for name, entry := range extracted {
+		mode, ok := modes[name]
+		if !ok {
+			return fmt.Errorf("failed to read zip stream: %q is missing from the central directory", name)
+		}
+
+		switch {
+		case entry.dir:
+			recorder.dir(entry.path, mode)
+
+		case mode&os.ModeSymlink != 0:
+			linkname, err := os.ReadFile(entry.written)
+			if err != nil {
+				return err
+			}
+
+			err = os.Remove(entry.written)
+			if err != nil {
+				return err
+			}
+
+			// Collect all of the headers for symlinks so that they can be verified
+			// after all other files are written
+			symlinks = append(symlinks, link{
+				name: string(linkname),
+				path: entry.path,
+			})
+
+		default:
+			if !entry.existed {
+				err = os.Chmod(entry.written, mode&^umask)
+				if err != nil {
+					return fmt.Errorf("failed to unzip file: %w", err)
+				}
+			}
+
+			if entry.written != entry.path {
+				err = os.Rename(entry.written, entry.path)
+				if err != nil {
+					return fmt.Errorf("failed to unzip file: %w", err)
+				}
+			}
+
+			recorder.file(entry.path, mode, entry.content)
+		}
+
+		restorer.recordModTime(entry.path, mode, entry.modified)
+	}
+
+	err = z.createSymlinks(symlinks, destination, filter, limiter, recorder)
+	if err != nil {
+		return err
+	}
+
+	err = restorer.restore()
+	if err != nil {
+		return err
+	}
+
+	recorder.commit()
+
+	return nil
+}
+
+type zipLocalHeader struct {
+	name       string
+	flags      uint16
+	method     uint16
+	crc32      uint32
+	size       uint64
+	zip64      bool
+	modified   time.Time
+	descriptor bool
+}
+
+func readZipLocalHeader(stream *bufio.Reader) (zipLocalHeader, error) {
+	raw := make([]byte, zipLocalHeaderLength)
+	_, err := io.ReadFull(stream, raw)
+	if err != nil {
+		return zipLocalHeader{}, err
+	}
+
+	header := zipLocalHeader{
+		flags:  binary.LittleEndian.Uint16(raw[2:4]),
+		method: binary.LittleEndian.Uint16(raw[4:6]),
+		crc32:  binary.LittleEndian.Uint32(raw[10:14]),
+		size:   uint64(binary.LittleEndian.Uint32(raw[14:18])),
+	}
+	header.descriptor = header.flags&zipFlagDataDescriptor != 0
+	uncompressedSize := binary.LittleEndian.Uint32(raw[18:22])
+
+	variable := make([]byte, int(binary.LittleEndian.Uint16(raw[22:24]))+int(binary.LittleEndian.Uint16(raw[24:26])))
+	_, err = io.ReadFull(stream, variable)
+	if err != nil {
+		return zipLocalHeader{}, err
+	}
+
+	nameLength := binary.LittleEndian.Uint16(raw[22:24])
+	header.name = string(variable[:nameLength])
+	header.modified = msDosTime(binary.LittleEndian.Uint16(raw[8:10]), binary.LittleEndian.Uint16(raw[6:8]))
+
+	extra := variable[nameLength:]
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra[0:2])
+		size := int(binary.LittleEndian.Uint16(extra[2:4]))
+		if len(extra) < 4+size {
+			break
+		}
+		field := extra[4 : 4+size]
+		extra = extra[4+size:]
+
+		switch id {
+		case zip64ExtraID:
+			header.zip64 = true
+
+			// The zip64 field holds the sizes that do not fit in the header, with
+			// the uncompressed size first.
+			if uncompressedSize == 0xffffffff && len(field) >= 8 {
+				field = field[8:]
+			}
+
+			if header.size == 0xffffffff && len(field) >= 8 {
+				header.size = binary.LittleEndian.Uint64(field[:8])
+			}
+
+		case zipExtendedTimestampID:
+			if len(field) >= 5 && field[0]&1 != 0 {
+				header.modified = time.Unix(int64(int32(binary.LittleEndian.Uint32(field[1:5]))), 0)
+			}
+		}
+	}
+
+	return header, nil
+}
+
+// open returns a reader of the content of the entry that verifies its
+// checksum once it has been read and reads past the remainder of the entry
+// when it is closed.
+func (h zipLocalHeader) open(stream *bufio.Reader) (io.ReadCloser, error) {
+	if h.flags&zipFlagEncrypted != 0 {
+		return nil, errors.New("encrypted entries are not supported")
+	}
+
+	var compressed io.Reader = io.LimitReader(stream, int64(h.size))
+	if h.descriptor {
+		compressed = stream
+	}
+
+	entry := &zipStreamContent{
+		header: h,
+		stream: stream,
+		hash:   crc32.NewIEEE(),
+	}
+
+	switch h.method {
+	case zip.Store:
+		if h.descriptor {
+			// The size of a stored entry is only given after its content, so the
+			// end of the content is found by looking for a matching descriptor.
+			entry.scanner = &zipDescriptorScanner{stream: stream, zip64: h.zip64, hash: crc32.NewIEEE()}
+			entry.reader = entry.scanner
+		} else {
+			entry.reader = compressed
+		}
+
+	case zip.Deflate:
+		// The stream is an io.ByteReader, so flate reads no further than the end
+		// of the compressed content.
+		entry.decompressor = flate.NewReader(compressed)
+		entry.reader = entry.decompressor
+		entry.compressed = compressed
+
+	default:
+		return nil, fmt.Errorf("unsupported compression method %d", h.method)
+	}
+
+	return entry, nil
+}
+
+type zipStreamContent struct {
+	header       zipLocalHeader
+	stream       *bufio.Reader
+	reader       io.Reader
+	compressed   io.Reader
+	decompressor io.ReadCloser
+	scanner      *zipDescriptorScanner
+	hash         hash.Hash32
+}
+
+func (c *zipStreamContent) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.hash.Write(p[:n])
+	return n, err
+}
+
+func (c *zipStreamContent) Close() error {
+	_, err := io.Copy(io.Discard, c)
+	if err != nil {
+		return err
+	}
+
+	if c.decompressor != nil {
+		err = c.decompressor.Close()
+		if err != nil {
+			return err
+		}
+
+		if !c.header.descriptor {
+			_, err = io.Copy(io.Discard, c.compressed)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	expected := c.header.crc32
+	switch {
+	case c.scanner != nil:
+		expected = c.scanner.crc32
+	case c.header.descriptor:
+		expected, err = readZipDataDescriptor(c.stream, c.header.zip64)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.hash.Sum32() != expected {
+		return errors.New("checksum does not match")
+	}
+
+	return nil
+}
+
+func readZipDataDescriptor(stream *bufio.Reader, zip64 bool) (uint32, error) {
+	// The descriptor signature is optional.
+	signature, err := stream.Peek(4)
+	if err != nil {
+		return 0, err
+	}
+
+	if binary.LittleEndian.Uint32(signature) == zipDataDescriptorSignature {
+		_, err = stream.Discard(4)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	length := 12
+	if zip64 {
+		length = 20
+	}
+
+	descriptor := make([]byte, length)
+	_, err = io.ReadFull(stream, descriptor)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(descriptor[:4]), nil
+}
+
+// A zipDescriptorScanner reads the content of a stored entry followed by a
+// data descriptor, ending at the first descriptor whose checksum and size
+// match the content read so far.
+type zipDescriptorScanner struct {
+	stream *bufio.Reader
+	zip64  bool
+	hash   hash.Hash32
+	size   uint64
+	crc32  uint32
+	done   bool
+}
+
+func (s *zipDescriptorScanner) Read(p []byte) (int, error) {
+	for i := range p {
+		if s.done {
+			return i, io.EOF
+		}
+
+		length, ok := s.match()
+		if ok {
+			_, err := s.stream.Discard(length)
+			if err != nil {
+				return i, err
+			}
+
+			s.crc32 = s.hash.Sum32()
+			s.done = true
+			return i, io.EOF
+		}
+
+		b, err := s.stream.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return i, err
+		}
+
+		p[i] = b
+		s.hash.Write([]byte{b})
+		s.size++
+	}
+
+	return len(p), nil
+}
+
+// match reports whether a data descriptor for the content read so far is
+// next in the stream, and its length.
+func (s *zipDescriptorScanner) match() (int, bool) {
+	sizeLength := 4
+	if s.zip64 {
+		sizeLength = 8
+	}
+
+	candidate, _ := s.stream.Peek(4 + 4 + 2*sizeLength)
+	if len(candidate) >= 4 && binary.LittleEndian.Uint32(candidate) == zipDataDescriptorSignature {
+		if s.descriptorMatches(candidate[4:], sizeLength) {
+			return 4 + 4 + 2*sizeLength, true
+		}
+	}
+
+	if s.descriptorMatches(candidate, sizeLength) {
+		return 4 + 2*sizeLength, true
+	}
+
+	return 0, false
+}
+
+func (s *zipDescriptorScanner) descriptorMatches(descriptor []byte, sizeLength int) bool {
+	if len(descriptor) < 4+2*sizeLength || binary.LittleEndian.Uint32(descriptor) != s.hash.Sum32() {
+		return false
+	}
+
+	if sizeLength == 8 {
+		return binary.LittleEndian.Uint64(descriptor[4:]) == s.size && binary.LittleEndian.Uint64(descriptor[12:]) == s.size
+	}
+
+	return uint64(binary.LittleEndian.Uint32(descriptor[4:])) == s.size && uint64(binary.LittleEndian.Uint32(descriptor[8:])) == s.size
+}
+
+// readZipCentralDirectory reads the central directory records, the first of
+// whose signature has already been read, and returns the mode of each entry.
+func readZipCentralDirectory(stream *bufio.Reader) (map[string]os.FileMode, error) {
+	modes := map[string]os.FileMode{}
+
+	signature := uint32(zipCentralHeaderSignature)
+	for signature == zipCentralHeaderSignature {
+		raw := make([]byte, zipCentralHeaderLength)
+		_, err := io.ReadFull(stream, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		nameLength := int(binary.LittleEndian.Uint16(raw[24:26]))
+		variableLength := nameLength + int(binary.LittleEndian.Uint16(raw[26:28])) + int(binary.LittleEndian.Uint16(raw[28:30]))
+
+		variable := make([]byte, variableLength)
+		_, err = io.ReadFull(stream, variable)
+		if err != nil {
+			return nil, err
+		}
+
+		// The mode is derived in the same way as for zip files that are read
+		// from their central directory.
+		header := zip.FileHeader{
+			Name:           string(variable[:nameLength]),
+			CreatorVersion: binary.LittleEndian.Uint16(raw[0:2]),
+			ExternalAttrs:  binary.LittleEndian.Uint32(raw[34:38]),
+		}
+		modes[header.Name] = header.Mode()
+
+		signature, err = readUint32(stream)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+	}
+
+	return modes, nil
+}
+
+func readUint32(reader io.Reader) (uint32, error) {
+	var value [4]byte
+	_, err := io.ReadFull(reader, value[:])
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(value[:]), nil
+}
+
+// msDosTime converts an MS-DOS date and time to a time.Time in UTC, as
+// archive/zip does.
+func msDosTime(date, clock uint16) time.Time {
+	return time.Date(
+		int(date>>9+1980),
+		time.Month(date>>5&0xf),
+		int(date&0x1f),
+		int(clock>>11),
+		int(clock>>5&0x3f),
+		int(clock&0x1f*2),
+		0,
+		time.UTC,
+	)
+}
+
+// measureUmask returns the umask of the process, as the permission bits that
+// are cleared from a file created with mode 0777 in a private directory within
+// dir. The directory is removed once the file has been measured.
+func measureUmask(dir string) (os.FileMode, error) {
+	private, err := os.MkdirTemp(dir, ".vacation-umask-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(private)
+
+	file, err := os.OpenFile(filepath.Join(private, "umask"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0777)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := file.Stat()
+	file.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	return 0777 &^ info.Mode().Perm(), nil
+}
@@ -0,0 +1,97 @@
+package vacation
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// A ZstdArchive decompresses zstd files from an input stream.
+type ZstdArchive struct {
+	extractOptions
+
+	reader io.Reader
+	name   string
+}
+
+// NewZstdArchive returns a new ZstdArchive that reads from inputReader.
+func NewZstdArchive(inputReader io.Reader) ZstdArchive {
+	return ZstdArchive{reader: inputReader}
+}
+
+// Decompress reads from ZstdArchive and writes files into the destination
+// specified.
+func (zstdArchive ZstdArchive) Decompress(destination string) error {
+	limits, reader := zstdArchive.limits.count(zstdArchive.reader)
+	progress, reader := trackProgress(zstdArchive.progress, reader)
+
+	zr, err := zstd.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("failed to create zstd reader: %w", err)
+	}
+	defer zr.Close()
+
+	return Archive{extractOptions: zstdArchive.forward(limits, progress), reader: zr, name: zstdArchive.name}.Decompress(destination)
+}
+
+// StripComponents behaves like the --strip-components flag on tar command
+// removing the first n levels from the final decompression destination.
+func (zstdArchive ZstdArchive) StripComponents(components int) ZstdArchive {
+	zstdArchive.components = components
+	return zstdArchive
+}
+
+// WithName provides a way of overriding the name of the file
+// that the decompressed file will be copied into.
+func (zstdArchive ZstdArchive) WithName(name string) ZstdArchive {
+	zstdArchive.name = name
+	return zstdArchive
+}
+
+// WithLimits bounds the resources that decompressing the archive may consume.
+func (zstdArchive ZstdArchive) WithLimits(limits Limits) ZstdArchive {
+	zstdArchive.limits = limits
+	return zstdArchive
+}
+
+// WithIncludes limits extraction to the entries that match one of the given
+// globs.
+func (zstdArchive ZstdArchive) WithIncludes(globs ...string) ZstdArchive {
+	zstdArchive.includes = globs
+	return zstdArchive
+}
+
+// WithExcludes skips the extraction of entries that match one of the given
+// globs.
+func (zstdArchive ZstdArchive) WithExcludes(globs ...string) ZstdArchive {
+	zstdArchive.excludes = globs
+	return zstdArchive
+}
+
+// WithPreserve selects the metadata of the archived files, beyond their mode,
+// that is restored on the extracted files.
+func (zstdArchive ZstdArchive) WithPreserve(options PreserveOptions) ZstdArchive {
+	zstdArchive.preserve = options
+	return zstdArchive
+}
+
+// WithManifest records the files that are extracted in manifest. Entries are
+// only added once decompression succeeds.
+func (zstdArchive ZstdArchive) WithManifest(manifest *Manifest) ZstdArchive {
+	zstdArchive.manifest = manifest
+	return zstdArchive
+}
+
+// WithLinkPolicy sets the policy that symlinks and hard links must satisfy
+// before they are created.
+func (zstdArchive ZstdArchive) WithLinkPolicy(policy LinkPolicy) ZstdArchive {
+	zstdArchive.linkPolicy = policy
+	return zstdArchive
+}
+
+// WithProgress reports the progress of decompression to reporter.
+func (zstdArchive ZstdArchive) WithProgress(reporter ProgressReporter) ZstdArchive {
+	zstdArchive.progress = reporter
+	return zstdArchive
+}